@@ -55,6 +55,43 @@ const (
 	// - if False, it's a PLZ test run and it wasn't aborted.
 	// - if True, it is a PLZ test run and it was aborted.
 	CloudTestRunAborted = "CloudTestRunAborted"
+
+	// RunnerJobsCreated indicates if runner jobs and services have been created.
+	// - if False, runner jobs are yet to be created
+	// - if True, all runner jobs and services have been created
+	RunnerJobsCreated = "RunnerJobsCreated"
+
+	// RunnersReady indicates if all runner pods and services are ready to start the test.
+	// - if False, runners are yet to be ready
+	// - if True, all runner pods are running and their services respond
+	RunnersReady = "RunnersReady"
+
+	// TestStarted indicates if the starter has been launched for this test run.
+	// - if False, the test is yet to be started
+	// - if True, the starter job has been created
+	TestStarted = "TestStarted"
+
+	// ThresholdsMet indicates if the runners have passed their thresholds.
+	// It is set once all runners have finished.
+	// - if empty / Unknown, runners haven't finished yet or some of them failed for other reasons
+	// - if False, at least one runner exited because of failed thresholds
+	// - if True, all runners have passed their thresholds
+	ThresholdsMet = "ThresholdsMet"
+
+	// RunnerAPIUnavailable indicates if the runner pods refuse connections to
+	// the k6 REST API on port 6565, which usually means that the runner image
+	// is not k6 or doesn't expose its REST API.
+	// - if empty / Unknown, connections to the runners haven't been refused for long
+	// - if False, the REST API of the runners has responded after being unavailable
+	// - if True, connections have been refused for a while after the start of the pods
+	RunnerAPIUnavailable = "RunnerAPIUnavailable"
+
+	// RunnersListFailed indicates if the operator cannot list the runner pods
+	// or services of the test run, e.g. because of missing RBAC permissions.
+	// - if empty / Unknown, listing hasn't failed for long
+	// - if False, listing has succeeded after failing
+	// - if True, listing fails with a permanent error or has failed for a while
+	RunnersListFailed = "RunnersListFailed"
 )
 
 // Initialize defines only conditions common to all test runs.
@@ -85,6 +122,9 @@ func Initialize(k6 *TestRun) {
 	}
 
 	UpdateCondition(k6, CloudTestRunAborted, metav1.ConditionFalse)
+	UpdateCondition(k6, RunnerJobsCreated, metav1.ConditionFalse)
+	UpdateCondition(k6, RunnersReady, metav1.ConditionFalse)
+	UpdateCondition(k6, TestStarted, metav1.ConditionFalse)
 
 	// PLZ test run case
 	if len(k6.GetSpec().TestRunID) > 0 {
@@ -148,6 +188,135 @@ func (k6status *TestRunStatus) SetIfNewer(proposedStatus TestRunStatus) (isNewer
 			return
 		})
 
+	// Manifests are rendered only once, in case of dry run.
+	if len(proposedStatus.Manifests) > 0 && len(k6status.Manifests) == 0 {
+		k6status.Manifests = proposedStatus.Manifests
+		isNewer = true
+	}
+
+	// Waiting reflects the latest check, so it's updated both ways.
+	if proposedStatus.Waiting != k6status.Waiting {
+		k6status.Waiting = proposedStatus.Waiting
+		isNewer = true
+	}
+
+	// Only the first error is kept, as it's the cause of the error stage.
+	if len(proposedStatus.Error) > 0 && len(k6status.Error) == 0 {
+		k6status.Error = proposedStatus.Error
+		isNewer = true
+	}
+
+	// Logs of the first failed runner are the most likely to show the cause.
+	if len(proposedStatus.RunnerLogs) > 0 && len(k6status.RunnerLogs) == 0 {
+		k6status.RunnerLogs = proposedStatus.RunnerLogs
+		isNewer = true
+	}
+
+	// Setup is not executed again after it has failed.
+	if len(proposedStatus.SetupError) > 0 && len(k6status.SetupError) == 0 {
+		k6status.SetupError = proposedStatus.SetupError
+		isNewer = true
+	}
+
+	// Node ports are allocated on creation of the services, which are only
+	// added to on scale-out.
+	if len(proposedStatus.NodePorts) > len(k6status.NodePorts) {
+		k6status.NodePorts = proposedStatus.NodePorts
+		isNewer = true
+	}
+
+	// The outcome of the runner jobs is known once, after all of them have finished.
+	if len(proposedStatus.Result) > 0 && len(k6status.Result) == 0 {
+		k6status.Result = proposedStatus.Result
+		k6status.CompletionTime = proposedStatus.CompletionTime
+		k6status.Duration = proposedStatus.Duration
+		isNewer = true
+	}
+
+	// Warnings are found on creation of the runners, so they don't change afterwards.
+	if len(proposedStatus.Warning) > 0 && len(k6status.Warning) == 0 {
+		k6status.Warning = proposedStatus.Warning
+		isNewer = true
+	}
+
+	// The test is stopped only once.
+	if len(proposedStatus.StopReason) > 0 && len(k6status.StopReason) == 0 {
+		k6status.StopReason = proposedStatus.StopReason
+		isNewer = true
+	}
+
+	// A rerun resets the whole status, so it's only set on initialization.
+	if len(proposedStatus.Rerun) > 0 && len(k6status.Rerun) == 0 {
+		k6status.Rerun = proposedStatus.Rerun
+		isNewer = true
+	}
+
+	// Runners are left out of the test only once, before its start.
+	if len(proposedStatus.MissingRunners) > 0 && len(k6status.MissingRunners) == 0 {
+		k6status.MissingRunners = proposedStatus.MissingRunners
+		isNewer = true
+	}
+
+	// The trace ID is chosen once for the whole test run.
+	if len(proposedStatus.TraceID) > 0 && len(k6status.TraceID) == 0 {
+		k6status.TraceID = proposedStatus.TraceID
+		isNewer = true
+	}
+
+	// Disruptions are only appended to.
+	if len(proposedStatus.Disruptions) > len(k6status.Disruptions) {
+		k6status.Disruptions = proposedStatus.Disruptions
+		isNewer = true
+	}
+
+	// Sent events cannot be unsent.
+	if len(proposedStatus.SentCloudEvents) > len(k6status.SentCloudEvents) {
+		k6status.SentCloudEvents = proposedStatus.SentCloudEvents
+		isNewer = true
+	}
+
+	// The script is inspected only once, by the initializer.
+	if len(proposedStatus.Inspection) > 0 && len(k6status.Inspection) == 0 {
+		k6status.Inspection = proposedStatus.Inspection
+		isNewer = true
+	}
+	if proposedStatus.MaxVUs > 0 && k6status.MaxVUs == 0 {
+		k6status.MaxVUs = proposedStatus.MaxVUs
+		k6status.TotalDuration = proposedStatus.TotalDuration
+		isNewer = true
+	}
+
+	// Resources of runners are computed once, before their creation.
+	if proposedStatus.RunnerResources != nil && k6status.RunnerResources == nil {
+		k6status.RunnerResources = proposedStatus.RunnerResources
+		isNewer = true
+	}
+
+	// Runners are started only once.
+	if proposedStatus.StartTime != nil && k6status.StartTime == nil {
+		k6status.StartTime = proposedStatus.StartTime
+		isNewer = true
+	}
+
+	// Runners finish the test only once.
+	if proposedStatus.RunnersKeptUntil != nil && k6status.RunnersKeptUntil == nil {
+		k6status.RunnersKeptUntil = proposedStatus.RunnersKeptUntil
+		isNewer = true
+	}
+
+	// Runners are created once and recreated only to scale out before the start.
+	if proposedStatus.Parallelism > k6status.Parallelism {
+		k6status.Parallelism = proposedStatus.Parallelism
+		isNewer = true
+	}
+
+	// Results of the runners are set only once, after they have finished.
+	if proposedStatus.ThresholdsPassed != nil && k6status.ThresholdsPassed == nil {
+		k6status.ThresholdsPassed = proposedStatus.ThresholdsPassed
+		k6status.Summary = proposedStatus.Summary
+		isNewer = true
+	}
+
 	// If a change in stage is proposed, confirm that it is consistent with
 	// expected flow of any test run.
 	if k6status.Stage != proposedStatus.Stage && len(proposedStatus.Stage) > 0 {
@@ -161,6 +330,21 @@ func (k6status *TestRunStatus) SetIfNewer(proposedStatus TestRunStatus) (isNewer
 				k6status.Stage = proposedStatus.Stage
 				isNewer = true
 			}
+		case "pending":
+			if proposedStatus.Stage == "creating" ||
+				proposedStatus.Stage == "created" ||
+				proposedStatus.Stage == "stopped" ||
+				proposedStatus.Stage == "error" {
+				k6status.Stage = proposedStatus.Stage
+				isNewer = true
+			}
+		case "creating":
+			if proposedStatus.Stage == "created" ||
+				proposedStatus.Stage == "stopped" ||
+				proposedStatus.Stage == "error" {
+				k6status.Stage = proposedStatus.Stage
+				isNewer = true
+			}
 		case "created":
 			if proposedStatus.Stage == "started" ||
 				proposedStatus.Stage == "finished" ||
@@ -0,0 +1,111 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConcurrencyPolicy describes how a new TestRun is treated
+// while the previous one is still running.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent creates the new TestRun regardless of the previous ones.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+
+	// ForbidConcurrent skips the new TestRun if the previous one hasn't finished yet.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+
+	// ReplaceConcurrent deletes the previous TestRun if it hasn't finished yet
+	// and creates the new one.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// ScheduledTestRunSpec defines the desired state of ScheduledTestRun
+type ScheduledTestRunSpec struct {
+	// Schedule of the test runs in Cron format, e.g. "0 3 * * *".
+	Schedule string `json:"schedule"`
+
+	// ConcurrencyPolicy defines what to do when it's time to create a new TestRun
+	// while the previous one hasn't finished yet. Default is Forbid.
+	// +kubebuilder:default=Forbid
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend stops creation of new TestRuns. Already created TestRuns are not affected.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// HistoryLimit is the number of finished TestRuns to keep. Default is 3.
+	// +kubebuilder:validation:Minimum=0
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+
+	// TestRunTemplate describes the TestRun created at each tick of the schedule.
+	TestRunTemplate TestRunTemplate `json:"testRunTemplate"`
+}
+
+// TestRunTemplate describes a TestRun to create.
+type TestRunTemplate struct {
+	Metadata PodMetadata `json:"metadata,omitempty"`
+	Spec     TestRunSpec `json:"spec"`
+}
+
+// ScheduledTestRunStatus defines the observed state of ScheduledTestRun
+type ScheduledTestRunStatus struct {
+	// Active contains names of the TestRuns which haven't finished yet.
+	Active []string `json:"active,omitempty"`
+
+	// LastScheduleTime is the last time a TestRun was scheduled.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+//+kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+//+kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ScheduledTestRun is the Schema for the scheduledtestruns API.
+type ScheduledTestRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduledTestRunSpec   `json:"spec,omitempty"`
+	Status ScheduledTestRunStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ScheduledTestRunList contains a list of ScheduledTestRun
+type ScheduledTestRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScheduledTestRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScheduledTestRun{}, &ScheduledTestRunList{})
+}
+
+// GetHistoryLimit returns the number of finished TestRuns to keep.
+func (s *ScheduledTestRun) GetHistoryLimit() int {
+	if s.Spec.HistoryLimit == nil {
+		return 3
+	}
+	return int(*s.Spec.HistoryLimit)
+}
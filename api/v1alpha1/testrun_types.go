@@ -16,13 +16,24 @@ package v1alpha1
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/grafana/k6-operator/pkg/types"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -53,8 +64,51 @@ type Pod struct {
 	Volumes                      []corev1.Volume                   `json:"volumes,omitempty"`
 	VolumeMounts                 []corev1.VolumeMount              `json:"volumeMounts,omitempty"`
 	PriorityClassName            string                            `json:"priorityClassName,omitempty"`
+	ActiveDeadlineSeconds        *int64                            `json:"activeDeadlineSeconds,omitempty"`
+	BackoffLimit                 *int32                            `json:"backoffLimit,omitempty"`
+	HostAliases                  []corev1.HostAlias                `json:"hostAliases,omitempty"`
+	DNSPolicy                    corev1.DNSPolicy                  `json:"dnsPolicy,omitempty"`
+	DNSConfig                    *corev1.PodDNSConfig              `json:"dnsConfig,omitempty"`
 }
 
+// RunnerPod is the configuration of runner Pods: Pod with the fields
+// which apply only to runners.
+type RunnerPod struct {
+	Pod `json:",inline"`
+
+	// TerminationGracePeriodSeconds is the time given to k6 to shut down cleanly when
+	// a runner Pod is deleted, e.g. to flush metrics or to end --linger. Default is 0.
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// RestartPolicy of runner Pods: Never (default) or OnFailure, which executes the segment
+	// again from the beginning and is allowed only if runners aren't paused.
+	// +kubebuilder:validation:Enum=Never;OnFailure
+	RestartPolicy corev1.RestartPolicy `json:"restartPolicy,omitempty"`
+
+	// Command overrides the entrypoint of the k6 container of runners, e.g. with a wrapper
+	// script which passes its arguments to k6. It must start k6 paused unless runners aren't paused.
+	Command []string `json:"command,omitempty"`
+
+	// Args overrides the arguments of the k6 container of runners, see Command.
+	Args []string `json:"args,omitempty"`
+
+	// RuntimeClassName of runner Pods, e.g. to run k6 in gVisor or Kata containers.
+	// The RuntimeClass must exist: a TestRun referring to a missing one is
+	// switched to the error stage instead of waiting for unschedulable Pods.
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// BrowserMode prepares runner Pods for k6 browser tests with headless Chromium,
+	// with DefaultBrowserRunnerImage by default.
+	BrowserMode bool `json:"browserMode,omitempty"`
+
+	// BrowserShmSize is the size of the in-memory /dev/shm with BrowserMode, 1Gi by default.
+	BrowserShmSize *resource.Quantity `json:"browserShmSize,omitempty"`
+}
+
+// InitContainer is run before the main container of the Pod. With restartPolicy
+// Always, it's a sidecar which keeps running alongside the main container instead,
+// e.g. an auth proxy next to the starter.
 type InitContainer struct {
 	Name          string                         `json:"name,omitempty"`
 	Image         string                         `json:"image,omitempty"`
@@ -81,11 +135,42 @@ type K6Scuttle struct {
 	QuitWithoutEnvoyTimeout string `json:"quitWithoutEnvoyTimeout,omitempty"`
 }
 
+// K6TokenSource defines where the token for Grafana Cloud k6 is loaded from.
+type K6TokenSource struct {
+	// SecretKeyRef selects a key of a Secret in the namespace of the TestRun.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// DefaultRunnerImage is the image of runners when none is specified.
+const DefaultRunnerImage = "grafana/k6:latest"
+
+// DefaultBrowserRunnerImage is the image of runners with BrowserMode when none is specified.
+const DefaultBrowserRunnerImage = "grafana/k6:latest-with-browser"
+
+// DefaultBrowserShmSize is the size of /dev/shm of runners with BrowserMode.
+var DefaultBrowserShmSize = resource.MustParse("1Gi")
+
+// TestRunResult is the combined outcome of the runner Jobs.
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type TestRunResult string
+
+const (
+	// TestRunSucceeded means that all runner Jobs have succeeded.
+	TestRunSucceeded TestRunResult = "Succeeded"
+
+	// TestRunFailed means that at least one runner Job has failed.
+	TestRunFailed TestRunResult = "Failed"
+)
+
 // TestRunSpec defines the desired state of TestRun
 type TestRunSpec struct {
 	// Script describes where the k6 script is located.
 	Script K6Script `json:"script"`
 
+	// PerRunnerScripts overrides Script for some runners, by the index of the runner
+	// from 1 to Parallelism. Such runners execute the whole script, without execution segments.
+	PerRunnerScripts map[string]K6Script `json:"perRunnerScripts,omitempty"`
+
 	// Parallelism shows the number of k6 runners.
 	Parallelism int32 `json:"parallelism"`
 
@@ -100,35 +185,419 @@ type TestRunSpec struct {
 	// Port 6565 is always configured for k6 processes.
 	Ports []corev1.ContainerPort `json:"ports,omitempty"`
 
+	// ImagePullSecrets are used by all Pods of the test run: initializer, starter and runners.
+	// They are added to the imagePullSecrets of each Pod configuration.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// Configuration for the initializer Pod. If omitted, the initializer
 	// is configured with the same parameters as a runner Pod.
 	Initializer *Pod `json:"initializer,omitempty"`
 
-	// Configuration for the starter Pod.
+	// Configuration for the starter Pod of the legacy starter, also used to stop the test.
+	// Image defaults to a small curl image.
 	Starter Pod `json:"starter,omitempty"`
 
 	// Configuration for a runner Pod.
-	Runner Pod `json:"runner,omitempty"`
+	Runner RunnerPod `json:"runner,omitempty"`
 
 	// Quiet is a boolean variable that allows to swtich off passing the `--quiet` to k6.
 	// +kubebuilder:default="true"
 	Quiet string `json:"quiet,omitempty"`
 
 	// Paused is a boolean variable that allows to switch off passing the `--paused` to k6.
-	// Use with caution as it can skew the result of the test.
-	// +kubebuilder:default="true"
+	// Use with caution as it can skew the result of the test. By default, only standalone runs aren't paused.
 	Paused string `json:"paused,omitempty"`
 
+	// LogLevel of the runners: info (default) or debug, passed to k6 as `--verbose`.
+	// +kubebuilder:validation:Enum=info;debug
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// LogFormat of the runners, passed to k6 as `--log-format`.
+	// With json, each log line is a JSON object.
+	// +kubebuilder:validation:Enum=raw;json
+	LogFormat string `json:"logFormat,omitempty"`
+
 	// Configuration for Envoy proxy.
 	Scuttle K6Scuttle `json:"scuttle,omitempty"`
 
 	Cleanup Cleanup `json:"cleanup,omitempty"`
 
+	// Extensions required by the script, by import path, output name or Go module,
+	// e.g. `k6/x/faker` or `github.com/grafana/xk6-faker`. The operator checks that the image of runners
+	// is built with all of them before creating the runners.
+	Extensions []string `json:"extensions,omitempty"`
+
+	// PreconditionProbe is checked before the runners are created:
+	// the test run waits in the initialized stage until it passes.
+	PreconditionProbe *K6PreconditionProbe `json:"preconditionProbe,omitempty"`
+
+	// Setup makes the operator execute setup() of the script once, on the first runner,
+	// and pass its data to all runners before the start. The test is started only
+	// if setup() succeeds. It is always done for PLZ test runs.
+	Setup *K6Setup `json:"setup,omitempty"`
+
+	// AbortGracePeriodSeconds is the grace period of runner Pods when the operator
+	// aborts the test run before its start, e.g. because the runners didn't get ready in time.
+	// If omitted, the terminationGracePeriodSeconds of the Pods is used.
+	// +kubebuilder:validation:Minimum=0
+	AbortGracePeriodSeconds *int64 `json:"abortGracePeriodSeconds,omitempty"`
+
+	// OutputVolume is a PersistentVolumeClaim mounted to all runner Pods,
+	// so that output files of k6 (e.g. CSV results or HTML reports) survive the Pods.
+	OutputVolume *K6OutputVolume `json:"outputVolume,omitempty"`
+
+	// ArtifactUpload runs a Job uploading the content of OutputVolume when the
+	// TestRun is deleted, e.g. to S3 or GCS. The deletion waits for the upload
+	// until it's done, it fails or its timeout is over.
+	ArtifactUpload *K6ArtifactUpload `json:"artifactUpload,omitempty"`
+
+	// AutoResources sizes the runners from the VUs of the script reported by
+	// `k6 inspect` and from parallelism, unless Runner has resources set.
+	// The computed resources are recorded in the status.
+	AutoResources *K6AutoResources `json:"autoResources,omitempty"`
+
+	// HeadlessService makes the operator create a single headless Service for all runners
+	// instead of a Service with a ClusterIP per runner. Runners are then addressed
+	// with stable DNS names of their Pods: `<runner>.<service>.<namespace>.svc`.
+	HeadlessService bool `json:"headlessService,omitempty"`
+
+	// RunnerPodIPs makes the operator reach the runners at the IPs of their Pods
+	// instead of creating a Service per runner.
+	RunnerPodIPs bool `json:"runnerPodIPs,omitempty"`
+
+	// ServiceType is the type of the Services of runners: ClusterIP, NodePort or LoadBalancer.
+	// Default is ClusterIP. It is ignored in case of HeadlessService.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// RunnerService customizes the names and ports of the Services of runners.
+	// It is ignored in case of HeadlessService.
+	RunnerService *K6RunnerService `json:"runnerService,omitempty"`
+
+	// Outputs of k6 metrics, in addition to the ones configured with `--out` in arguments.
+	Outputs *K6Outputs `json:"outputs,omitempty"`
+
+	// DisruptionBudget makes the operator create a PodDisruptionBudget for runner Pods,
+	// so that they are not evicted by node drains while the test is running.
+	// The PodDisruptionBudget is deleted once the test run is finished.
+	DisruptionBudget bool `json:"disruptionBudget,omitempty"`
+
+	// TolerateRunnerEviction makes the operator replace a runner whose Pod was evicted
+	// while the test is running. The new runner starts its segment from the beginning.
+	TolerateRunnerEviction bool `json:"tolerateRunnerEviction,omitempty"`
+
+	// NodeLossPolicy is what the operator does when a runner Pod is lost with its node
+	// while the test is running: Ignore (default), Rejoin or Fail.
+	// +kubebuilder:default=Ignore
+	// +optional
+	NodeLossPolicy NodeLossPolicy `json:"nodeLossPolicy,omitempty"`
+
+	// MinReadyRunners lets the test start without the runners which are not ready in time,
+	// with less load, as long as at least MinReadyRunners of them are. Zero means all runners.
+	// +kubebuilder:validation:Minimum=0
+	MinReadyRunners int32 `json:"minReadyRunners,omitempty"`
+
+	// Stopped stops the test while it's running. Unlike deletion of the TestRun,
+	// runner Pods are kept so that their final summaries can be read.
+	Stopped bool `json:"stopped,omitempty"`
+
+	// MaxDuration limits how long the test can run, whatever the script declares:
+	// once the runners have been started for longer than that, the operator stops
+	// them as with Stopped. For cloud test runs, the cloud test run is aborted.
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+
+	// KeepRunnersAfterFinish keeps the k6 container of each runner running for the given time
+	// after k6 has exited, e.g. to debug. The runner image must have `sh`.
+	KeepRunnersAfterFinish *metav1.Duration `json:"keepRunnersAfterFinish,omitempty"`
+
+	// ExecutionSegmentEnv passes the execution segment of each runner in
+	// K6_EXECUTION_SEGMENT and K6_EXECUTION_SEGMENT_SEQUENCE env vars instead
+	// of command line flags, e.g. for images with a custom entrypoint.
+	ExecutionSegmentEnv bool `json:"executionSegmentEnv,omitempty"`
+
+	// InheritProxyEnv passes HTTP_PROXY, HTTPS_PROXY and NO_PROXY of the operator
+	// to the runners. Variables set in the env of the runner take precedence.
+	InheritProxyEnv bool `json:"inheritProxyEnv,omitempty"`
+
+	// ConfirmStart delays the started stage until all runners report
+	// that the test is actually running. By default, the test run is
+	// considered started as soon as the start requests are sent.
+	ConfirmStart bool `json:"confirmStart,omitempty"`
+
+	// Tracing passes a trace ID to the runners and to the requests of the operator
+	// to them, to correlate the test run with distributed tracing.
+	Tracing *K6Tracing `json:"tracing,omitempty"`
+
+	// Seed is passed to all runners in K6_SEED env var, for the script to seed
+	// its randomness with randomSeed(__ENV.K6_SEED).
+	Seed *int64 `json:"seed,omitempty"`
+
+	// ThresholdsOverride replaces the thresholds of the script for the metrics it lists.
+	// The script must be an ES module at an absolute path.
+	ThresholdsOverride *K6ThresholdsOverride `json:"thresholdsOverride,omitempty"`
+
+	// PodInfoEnv passes the name, namespace and IP of the runner Pod and the name of its node
+	// in K6_POD_NAME, K6_POD_NAMESPACE, K6_POD_IP and K6_NODE_NAME env vars.
+	PodInfoEnv bool `json:"podInfoEnv,omitempty"`
+
+	// CompletionMode of the runners: NonIndexed (default) creates a Job per runner, while
+	// Indexed runs the runners as the pods of a single Indexed Job.
+	// +kubebuilder:validation:Enum=NonIndexed;Indexed
+	// +optional
+	CompletionMode batchv1.CompletionMode `json:"completionMode,omitempty"`
+
+	// DryRun makes the operator render runner Jobs and Services into
+	// the status of TestRun instead of creating them. The test is not executed.
+	DryRun bool `json:"dryRun,omitempty"`
+
 	// TestRunID is reserved by Grafana Cloud k6. Do not set it manually.
 	TestRunID string `json:"testRunId,omitempty"` // PLZ reserved field
 
 	// Token is reserved by Grafana Cloud k6. Do not set it manually.
 	Token string `json:"token,omitempty"` // PLZ reserved field (for now)
+
+	// TokenFrom defines where the token for Grafana Cloud k6 is loaded from.
+	// By default, it's the Secret labeled with k6cloud=token in the namespace
+	// of the operator.
+	TokenFrom *K6TokenSource `json:"tokenFrom,omitempty"`
+
+	// CloudOutputToken selects a key of a Secret with the token of Grafana Cloud k6,
+	// passed to runners which stream their results with `--out cloud` on their own.
+	CloudOutputToken *corev1.SecretKeySelector `json:"cloudOutputToken,omitempty"`
+
+	// CloudHost is the URL of Grafana Cloud k6 API for cloud test runs, e.g. of
+	// a dedicated stack. If empty, K6_CLOUD_HOST from runner's env is used and
+	// then the default Grafana Cloud k6 endpoint.
+	CloudHost string `json:"cloudHost,omitempty"`
+
+	// InheritLabels lists the labels of the TestRun which are copied to all objects created for it.
+	// A key ending with `*` selects all labels with that prefix.
+	InheritLabels []string `json:"inheritLabels,omitempty"`
+
+	// InheritAnnotations lists the annotations of the TestRun which are copied
+	// to all objects created for it, as with InheritLabels. The annotation
+	// kubectl.kubernetes.io/last-applied-configuration is never copied.
+	InheritAnnotations []string `json:"inheritAnnotations,omitempty"`
+}
+
+// K6PreconditionProbe describes what must be available before the test starts.
+// If several checks are configured, all of them must pass.
+type K6PreconditionProbe struct {
+	// URL must respond to HTTP GET with a status code lower than 400.
+	URL string `json:"url,omitempty"`
+
+	// Deployment is the name of a Deployment in the namespace of the TestRun
+	// which must be available.
+	Deployment string `json:"deployment,omitempty"`
+
+	// PeriodSeconds is how often the probe is checked. Default is 10.
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+}
+
+// K6Setup configures execution of setup() by the operator.
+type K6Setup struct {
+	// FailurePolicy defines what happens if setup() fails: with Abort, the test run
+	// is moved to the error stage; with Retry, setup() is executed again.
+	// Network errors are always retried. Default is Abort.
+	// +kubebuilder:default=Abort
+	FailurePolicy SetupFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// SetupFailurePolicy describes what to do when setup() fails.
+// +kubebuilder:validation:Enum=Abort;Retry
+type SetupFailurePolicy string
+
+const (
+	// AbortOnSetupFailure moves the test run to the error stage.
+	AbortOnSetupFailure SetupFailurePolicy = "Abort"
+
+	// RetryOnSetupFailure executes setup() again.
+	RetryOnSetupFailure SetupFailurePolicy = "Retry"
+)
+
+// NodeLossPolicy describes what to do when the node of a runner is lost during the test.
+// +kubebuilder:validation:Enum=Ignore;Rejoin;Fail
+type NodeLossPolicy string
+
+const (
+	// IgnoreNodeLoss leaves the lost runner to the Job controller.
+	IgnoreNodeLoss NodeLossPolicy = "Ignore"
+
+	// RejoinOnNodeLoss replaces the lost runner, which rejoins the test.
+	RejoinOnNodeLoss NodeLossPolicy = "Rejoin"
+
+	// FailOnNodeLoss stops the other runners and moves the test run to the error
+	// stage. A runner Pod replaced by its Job during the test counts as lost.
+	FailOnNodeLoss NodeLossPolicy = "Fail"
+)
+
+const (
+	// DefaultTraceIDEnv is the env var of runners with the trace ID.
+	DefaultTraceIDEnv = "K6_TRACE_ID"
+	// DefaultTraceHeader is the HTTP header with the trace context.
+	DefaultTraceHeader = "traceparent"
+	// SeedEnv is the env var of runners with the seed.
+	SeedEnv = "K6_SEED"
+	// InstanceIDEnv is the env var of runners with their index, starting from 1
+	// as in the `instance_id` tag of their metrics: the runner `<name>-1` is
+	// instance 1 and executes the first execution segment.
+	InstanceIDEnv = "K6_INSTANCE_ID"
+	// TestRunUIDLabel is the label of runners with the UID of their TestRun.
+	TestRunUIDLabel = "k6_uid"
+)
+
+// K6Tracing configures the trace context of a test run.
+type K6Tracing struct {
+	// TraceID is the W3C trace ID of the test run: 32 lowercase hex digits.
+	// If it's empty, a random one is generated for the test run.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{32}$`
+	TraceID string `json:"traceId,omitempty"`
+
+	// Env is the env var of runners which contains the trace ID. Default is K6_TRACE_ID.
+	Env string `json:"env,omitempty"`
+
+	// Header is the HTTP header carrying the trace context, in the W3C traceparent format,
+	// in the requests of the operator to the REST API of runners. Default is traceparent.
+	// The legacy starter doesn't send it.
+	Header string `json:"header,omitempty"`
+}
+
+// EnvName returns the name of the env var with the trace ID.
+func (t *K6Tracing) EnvName() string {
+	if len(t.Env) > 0 {
+		return t.Env
+	}
+	return DefaultTraceIDEnv
+}
+
+// HeaderName returns the name of the HTTP header with the trace context.
+func (t *K6Tracing) HeaderName() string {
+	if len(t.Header) > 0 {
+		return t.Header
+	}
+	return DefaultTraceHeader
+}
+
+// K6RunnerService customizes the Services of runners.
+type K6RunnerService struct {
+	// NameTemplate is a Go template of the name of the Service of each runner,
+	// with `{{.Name}}` and `{{.Index}}`. Default is "{{.Name}}-service-{{.Index}}".
+	NameTemplate string `json:"nameTemplate,omitempty"`
+
+	// ExtraPorts are added to the Services after the port of k6 REST API,
+	// e.g. for a metrics sidecar. They must not use port 6565 nor its name, `http-api`.
+	ExtraPorts []corev1.ServicePort `json:"extraPorts,omitempty"`
+}
+
+const (
+	// CloudTestRunIDAnnotation keeps the ID of the cloud test run as soon as it's
+	// created, so that it's not lost if the status cannot be updated right away.
+	CloudTestRunIDAnnotation = "k6.io/cloud-test-run-id"
+
+	// CloudAggregationVarsAnnotation keeps the aggregation config of the cloud test run
+	// together with CloudTestRunIDAnnotation.
+	CloudAggregationVarsAnnotation = "k6.io/cloud-aggregation-vars"
+
+	// RerunAnnotation runs a finished TestRun again whenever its value is changed:
+	// the objects of the previous run are deleted and the status is reset.
+	RerunAnnotation = "k6.io/rerun"
+
+	// DebugAnnotation injects an ephemeral debug container into the pod of the
+	// runner it names, e.g. "my-test-2", while the test is running. The images
+	// of debug containers are allowed by the operator: there is none by default.
+	DebugAnnotation = "k6.io/debug"
+
+	// DebugImageAnnotation is the image of the debug container, one of those
+	// allowed by the operator. The first allowed image is used by default.
+	DebugImageAnnotation = "k6.io/debug-image"
+)
+
+// DefaultRunnerServiceNameTemplate is the name template of the Services of runners.
+const DefaultRunnerServiceNameTemplate = "{{.Name}}-service-{{.Index}}"
+
+// RunnerServiceName returns the name of the Service of the runner with the given index.
+func (k6 *TestRun) RunnerServiceName(index int) (string, error) {
+	nameTemplate := DefaultRunnerServiceNameTemplate
+	if rs := k6.GetSpec().RunnerService; rs != nil && len(rs.NameTemplate) > 0 {
+		nameTemplate = rs.NameTemplate
+	}
+
+	tmpl, err := template.New("service").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid name template of runner services: %w", err)
+	}
+
+	var name strings.Builder
+	data := struct {
+		Name  string
+		Index int
+	}{k6.NamespacedName().Name, index}
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", fmt.Errorf("invalid name template of runner services: %w", err)
+	}
+
+	if errs := validation.IsDNS1035Label(name.String()); len(errs) > 0 {
+		return "", fmt.Errorf("invalid name of runner service `%s`: %s", name.String(), strings.Join(errs, ", "))
+	}
+	return name.String(), nil
+}
+
+// ValidateRunnerService checks that the Services of runners get unique valid names
+// and that extra ports don't collide with the port of k6 REST API.
+func (k6 *TestRun) ValidateRunnerService() error {
+	rs := k6.GetSpec().RunnerService
+	if rs == nil {
+		return nil
+	}
+
+	first, err := k6.RunnerServiceName(1)
+	if err != nil {
+		return err
+	}
+	if second, err := k6.RunnerServiceName(2); err != nil {
+		return err
+	} else if first == second {
+		return fmt.Errorf("name template of runner services `%s` must use {{.Index}}", rs.NameTemplate)
+	}
+
+	for _, port := range rs.ExtraPorts {
+		if port.Port == 6565 || port.Name == "http-api" {
+			return fmt.Errorf("extra port `%s` of runner services collides with port 6565 of k6 REST API", port.Name)
+		}
+	}
+	return nil
+}
+
+// K6Outputs describes outputs of k6 metrics configured by the operator.
+type K6Outputs struct {
+	// PrometheusRemoteWrite configures the `experimental-prometheus-rw` output.
+	PrometheusRemoteWrite *K6PrometheusRemoteWrite `json:"prometheusRemoteWrite,omitempty"`
+
+	// InfluxDB configures the `influxdb` output.
+	InfluxDB *K6InfluxDB `json:"influxdb,omitempty"`
+}
+
+// K6PrometheusRemoteWrite describes the Prometheus remote write output.
+type K6PrometheusRemoteWrite struct {
+	// ServerURL of the remote write endpoint, e.g. "http://prometheus:9090/api/v1/write".
+	ServerURL string `json:"serverUrl"`
+
+	// TrendStats is a list of stats to send for trend metrics, e.g. "p(95),p(99),min,max".
+	TrendStats string `json:"trendStats,omitempty"`
+
+	// CredentialsSecret is the name of a Secret with `username` and `password` keys,
+	// used for basic authentication.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// K6InfluxDB describes the InfluxDB output.
+type K6InfluxDB struct {
+	// URL of the InfluxDB database, e.g. "http://influxdb:8086/k6".
+	URL string `json:"url"`
+
+	// CredentialsSecret is the name of a Secret with `username` and `password` keys.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
 }
 
 // K6Script describes where to find the k6 script.
@@ -137,6 +606,20 @@ type K6Script struct {
 	ConfigMap   K6Configmap   `json:"configMap,omitempty"`
 	// LocalFile describes the location of the script in the runner image.
 	LocalFile string `json:"localFile,omitempty"`
+	// URL describes the location of the script to download before the test.
+	URL *K6ScriptURL `json:"url,omitempty"`
+}
+
+// K6ScriptURL describes the script downloaded by an init container of each k6 Pod,
+// e.g. a raw file from a Git hosting service.
+type K6ScriptURL struct {
+	// URL of the file to execute (.js or .tar). Only http and https are supported.
+	URL string `json:"url"`
+	// AuthSecretRef selects a key of a Secret with the value of the Authorization header,
+	// e.g. "Bearer <token>".
+	AuthSecretRef *corev1.SecretKeySelector `json:"authSecretRef,omitempty"`
+	// Image with curl, used to download the script. Default is the image of the starter.
+	Image string `json:"image,omitempty"`
 }
 
 // K6VolumeClaim describes the location of the script on the Volume.
@@ -151,6 +634,26 @@ type K6VolumeClaim struct {
 	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
+// K6ThresholdsOverride describes where to find the thresholds overriding the ones of the script.
+type K6ThresholdsOverride struct {
+	// ConfigMap with a JSON object of thresholds by metric, in the format of
+	// `options.thresholds` of k6, e.g. `{"http_req_duration": ["p(95)<500"]}`.
+	// The default File is `thresholds.json`.
+	ConfigMap K6Configmap `json:"configMap"`
+}
+
+// File returns the key of the ConfigMap with the thresholds.
+func (t *K6ThresholdsOverride) File() string {
+	if len(t.ConfigMap.File) > 0 {
+		return t.ConfigMap.File
+	}
+	return "thresholds.json"
+}
+
+// ThresholdsWrapperAnnotation of runner Pods keeps the module which applies
+// ThresholdsOverride over the script. It's mounted into the Pod from the annotation.
+const ThresholdsWrapperAnnotation = "k6.io/thresholds-wrapper"
+
 // K6Configmap describes the location of the script in the ConfigMap.
 type K6Configmap struct {
 	// Name of the ConfigMap. It is expected to be in the sanme namespace as the `TestRun`.
@@ -159,6 +662,119 @@ type K6Configmap struct {
 	File string `json:"file,omitempty"`
 }
 
+// K6AutoResources describes how resources of runners are derived from the number of their VUs.
+// Omitted values have defaults suitable for simple HTTP scripts.
+type K6AutoResources struct {
+	// MemoryPerVU is the memory used by a VU. Default is 4Mi.
+	MemoryPerVU *resource.Quantity `json:"memoryPerVU,omitempty"`
+
+	// CPUPerVU is the CPU used by a VU. Default is 2m.
+	CPUPerVU *resource.Quantity `json:"cpuPerVU,omitempty"`
+
+	// BaseMemory is the memory used by k6 without any VUs. Default is 128Mi.
+	BaseMemory *resource.Quantity `json:"baseMemory,omitempty"`
+
+	// BaseCPU is the CPU used by k6 without any VUs. Default is 100m.
+	BaseCPU *resource.Quantity `json:"baseCPU,omitempty"`
+}
+
+// Resources returns the resources of a runner with the given number of VUs.
+func (a *K6AutoResources) Resources(vus int64) corev1.ResourceRequirements {
+	orDefault := func(q *resource.Quantity, value string) resource.Quantity {
+		if q == nil {
+			return resource.MustParse(value)
+		}
+		return q.DeepCopy()
+	}
+	scaled := func(base, perVU resource.Quantity) resource.Quantity {
+		total := *resource.NewMilliQuantity(perVU.MilliValue()*vus, perVU.Format)
+		total.Add(base)
+		return total
+	}
+
+	memory := scaled(orDefault(a.BaseMemory, "128Mi"), orDefault(a.MemoryPerVU, "4Mi"))
+	cpu := scaled(orDefault(a.BaseCPU, "100m"), orDefault(a.CPUPerVU, "2m"))
+
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    cpu,
+			corev1.ResourceMemory: memory,
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: memory.DeepCopy(),
+		},
+	}
+}
+
+// K6ArtifactUpload describes the Job uploading output files of k6 runners.
+// OutputVolume is mounted to it at the same path as to the runners or, with
+// PerRunner, each claim is mounted at `<mountPath>/<claimName>`.
+type K6ArtifactUpload struct {
+	// Image of the container uploading the files, e.g. with aws or gcloud CLI.
+	Image string `json:"image"`
+
+	Command []string               `json:"command,omitempty"`
+	Args    []string               `json:"args,omitempty"`
+	Env     []corev1.EnvVar        `json:"env,omitempty"`
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// ServiceAccountName is the service account of the upload Pod,
+	// e.g. with the permissions to the bucket.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Timeout is how long the deletion of the TestRun waits for the upload.
+	// Default is 10 minutes.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// DefaultArtifactUploadTimeout is how long the deletion of a TestRun
+// waits for the upload of its output files by default.
+const DefaultArtifactUploadTimeout = 10 * time.Minute
+
+// TimeoutOrDefault returns how long the deletion of the TestRun waits for the upload.
+func (u *K6ArtifactUpload) TimeoutOrDefault() time.Duration {
+	if u.Timeout == nil || u.Timeout.Duration <= 0 {
+		return DefaultArtifactUploadTimeout
+	}
+	return u.Timeout.Duration
+}
+
+// K6OutputVolume describes the PersistentVolumeClaim for output files of k6 runners.
+type K6OutputVolume struct {
+	// Name of the PersistentVolumeClaim. It is expected to be in the same namespace as the `TestRun`.
+	// A claim shared by several runners should support `ReadWriteMany` access mode.
+	ClaimName string `json:"claimName"`
+	// Path to mount the volume at in runner containers. Default is `/output`.
+	MountPath string `json:"mountPath,omitempty"`
+	// PerRunner shows whether each runner mounts its own claim, named `<claimName>-<index>`.
+	// The claims are not created by k6-operator.
+	PerRunner bool `json:"perRunner,omitempty"`
+	// DeleteOnCleanup shows whether the claim(s) should be deleted together with
+	// the `TestRun` on `cleanup: post`. By default, claims are never deleted.
+	DeleteOnCleanup bool `json:"deleteOnCleanup,omitempty"`
+}
+
+// ClaimNames returns names of all claims used by the test run with the given parallelism.
+func (v *K6OutputVolume) ClaimNames(parallelism int) []string {
+	if !v.PerRunner {
+		return []string{v.ClaimName}
+	}
+
+	names := make([]string, parallelism)
+	for i := range names {
+		names[i] = v.ClaimNameFor(i + 1)
+	}
+	return names
+}
+
+// ClaimNameFor returns name of the claim mounted by the runner with the given index.
+func (v *K6OutputVolume) ClaimNameFor(index int) string {
+	if v.PerRunner {
+		return fmt.Sprintf("%s-%d", v.ClaimName, index)
+	}
+	return v.ClaimName
+}
+
 //TODO: cleanup pre-execution?
 
 // Cleanup allows for automatic cleanup of resources post execution.
@@ -166,7 +782,7 @@ type K6Configmap struct {
 type Cleanup string
 
 // Stage describes which stage of the test execution lifecycle k6 runners are in.
-// +kubebuilder:validation:Enum=initialization;initialized;created;started;stopped;finished;error
+// +kubebuilder:validation:Enum=initialization;initialized;pending;creating;created;started;stopped;finished;error
 type Stage string
 
 // TestRunStatus defines the observed state of TestRun.
@@ -175,14 +791,118 @@ type TestRunStatus struct {
 	TestRunID       string `json:"testRunId,omitempty"`
 	AggregationVars string `json:"aggregationVars,omitempty"`
 
+	// Manifests contains the rendered runner resources in case of DryRun.
+	Manifests string `json:"manifests,omitempty"`
+
+	// ThresholdsPassed shows whether all runners have passed their thresholds.
+	// It is set once all runners have finished.
+	ThresholdsPassed *bool `json:"thresholdsPassed,omitempty"`
+
+	// Summary describes the results of the runners.
+	Summary string `json:"summary,omitempty"`
+
+	// Result is the combined outcome of the runner Jobs,
+	// set once all of them have finished.
+	Result TestRunResult `json:"result,omitempty"`
+
+	// StartTime is the time when the runners were started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the last runner Job has finished.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Duration is the time between StartTime and CompletionTime.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RunnersKeptUntil is the time until which the runner Pods are kept for
+	// inspection with KeepRunnersAfterFinish, once they've finished the test.
+	RunnersKeptUntil *metav1.Time `json:"runnersKeptUntil,omitempty"`
+
+	// Waiting describes what the test run is waiting for before it can proceed.
+	Waiting string `json:"waiting,omitempty"`
+
+	// Error describes why the test run was moved to the error stage,
+	// if the operator could tell.
+	Error string `json:"error,omitempty"`
+
+	// RunnerLogs contains the tail of the logs of the first failed runner Pod,
+	// so that the failure can be diagnosed even after the Pod is gone.
+	RunnerLogs string `json:"runnerLogs,omitempty"`
+
+	// SetupError is the error of setup() executed by the operator, if it has failed.
+	SetupError string `json:"setupError,omitempty"`
+
+	// NodePorts contains the node ports allocated to the Services of runners,
+	// by runner name. It is set only if the Services have node ports.
+	NodePorts map[string]int32 `json:"nodePorts,omitempty"`
+
+	// Parallelism is the number of runners that were created. The load
+	// is segmented between them, so it must not change afterwards.
+	Parallelism int32 `json:"parallelism,omitempty"`
+
+	// MaxVUs is the maximum number of VUs required by the script,
+	// as reported by `k6 inspect --execution-requirements`.
+	MaxVUs int64 `json:"maxVUs,omitempty"`
+
+	// Inspection is the output of `k6 inspect` read from the initializer,
+	// so that its logs are read only once.
+	Inspection string `json:"inspection,omitempty"`
+
+	// TotalDuration is the maximum duration of the test, including graceful stops,
+	// as reported by `k6 inspect --execution-requirements`.
+	TotalDuration *metav1.Duration `json:"totalDuration,omitempty"`
+
+	// RunnerResources are the resources of runners computed with AutoResources.
+	RunnerResources *corev1.ResourceRequirements `json:"runnerResources,omitempty"`
+
+	// Warning describes a problem of the configuration which doesn't prevent
+	// the test run from proceeding, e.g. runners without any VUs.
+	Warning string `json:"warning,omitempty"`
+
+	// StopReason describes why the test was stopped before its end,
+	// with Stopped or MaxDuration.
+	StopReason string `json:"stopReason,omitempty"`
+
+	// Rerun is the value of RerunAnnotation when the current run was started.
+	Rerun string `json:"rerun,omitempty"`
+
+	// MissingRunners lists the runner Jobs which were not ready within the
+	// startup timeout and were left out of the test, with MinReadyRunners.
+	MissingRunners []string `json:"missingRunners,omitempty"`
+
+	// TraceID is the trace ID of the test run, with Tracing.
+	TraceID string `json:"traceId,omitempty"`
+
+	// Disruptions lists the runners which were evicted and replaced
+	// during the test, with TolerateRunnerEviction or NodeLossPolicy.
+	Disruptions []RunnerDisruption `json:"disruptions,omitempty"`
+
+	// SentCloudEvents lists the terminal events already sent to k6 Cloud
+	// for the test run, by type and reason, so that they aren't sent again.
+	SentCloudEvents []string `json:"sentCloudEvents,omitempty"`
+
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// RunnerDisruption describes an eviction of a runner during the test.
+type RunnerDisruption struct {
+	// Runner is the name of the runner Job which was replaced.
+	Runner string `json:"runner"`
+
+	// Message is the reason of the eviction given by Kubernetes.
+	Message string `json:"message,omitempty"`
+
+	// Time is when the eviction was noticed by the operator.
+	Time metav1.Time `json:"time"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Stage",type="string",JSONPath=".status.stage",description="Stage"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:printcolumn:name="TestRunID",type="string",JSONPath=".status.testRunId"
+//+kubebuilder:printcolumn:name="Result",type="string",JSONPath=".status.result",description="Combined outcome of the runner jobs"
+//+kubebuilder:printcolumn:name="Passed",type="boolean",JSONPath=".status.thresholdsPassed",description="Whether all runners passed their thresholds"
 
 // TestRun is the Schema for the testruns API.
 type TestRun struct {
@@ -208,13 +928,226 @@ func init() {
 
 func (k6 *TestRunSpec) Validate() error {
 	// Currently, we validate "manually" only arguments field.
-	_, err := types.ParseCLI(k6.Arguments)
-	return err
+	cli, err := types.ParseCLI(k6.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if err := k6.validateCloudOutputToken(cli); err != nil {
+		return err
+	}
+
+	// execution segments are always computed by the operator
+	for _, arg := range append(append([]string{}, k6.Runner.Command...), k6.Runner.Args...) {
+		if strings.HasPrefix(arg, "--execution-segment") {
+			return fmt.Errorf("runner command and args must not contain `%s`: execution segments are set by the operator", arg)
+		}
+	}
+
+	if k6.ArtifactUpload != nil && k6.OutputVolume == nil {
+		return errors.New("artifactUpload requires outputVolume with the files to upload")
+	}
+
+	if k6.MinReadyRunners > k6.Parallelism {
+		return fmt.Errorf("minReadyRunners %d cannot be larger than parallelism %d", k6.MinReadyRunners, k6.Parallelism)
+	}
+
+	if k6.KeepRunnersAfterFinish != nil && k6.Runner.HasCommandOverride() {
+		return errors.New("keepRunnersAfterFinish cannot be combined with runner command and args: the wrapper of k6 is set by the operator")
+	}
+
+	if k6.RunnerPodIPs && (k6.HeadlessService || len(k6.ServiceType) > 0) {
+		return errors.New("runnerPodIPs cannot be combined with headlessService or serviceType: runners get no Services")
+	}
+
+	if err := k6.validateIndexedRunners(); err != nil {
+		return err
+	}
+
+	if err := k6.validatePerRunnerScripts(); err != nil {
+		return err
+	}
+
+	if err := k6.validateThresholdsOverride(); err != nil {
+		return err
+	}
+
+	return k6.validateRunnerVolumes()
+}
+
+// validatePerRunnerScripts checks that PerRunnerScripts override the script
+// of existing runners only and that each of them is a valid script.
+func (k6 *TestRunSpec) validatePerRunnerScripts() error {
+	if len(k6.PerRunnerScripts) == 0 {
+		return nil
+	}
+
+	for key, script := range k6.PerRunnerScripts {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 1 || index > int(k6.Parallelism) {
+			return fmt.Errorf("perRunnerScripts key `%s` must be the index of a runner, from 1 to %d", key, k6.Parallelism)
+		}
+		if _, err := script.Parse(); err != nil {
+			return fmt.Errorf("perRunnerScripts of runner %d: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// validateThresholdsOverride checks that the scripts can be imported by the
+// wrapper module of ThresholdsOverride. The thresholds themselves are in
+// a ConfigMap and are validated by the operator.
+func (k6 *TestRunSpec) validateThresholdsOverride() error {
+	if k6.ThresholdsOverride == nil {
+		return nil
+	}
+
+	if len(k6.ThresholdsOverride.ConfigMap.Name) == 0 {
+		return errors.New("thresholdsOverride requires the name of the ConfigMap with the thresholds")
+	}
+	if k6.Runner.HasCommandOverride() {
+		return errors.New("thresholdsOverride cannot be combined with runner command and args: the script is set by the operator")
+	}
+
+	scripts := []K6Script{k6.Script}
+	for _, script := range k6.PerRunnerScripts {
+		scripts = append(scripts, script)
+	}
+	for _, spec := range scripts {
+		script, err := spec.Parse()
+		if err != nil {
+			// reported by the initializer
+			continue
+		}
+		if strings.HasSuffix(script.Filename, ".tar") || !path.IsAbs(script.FullName()) {
+			return fmt.Errorf("thresholdsOverride requires a .js script at an absolute path, got `%s`", script.FullName())
+		}
+	}
+	return nil
+}
+
+// ParseRunnerScript returns the script executed by the runner with the given
+// index: its entry of PerRunnerScripts or, by default, Script.
+func (k6 TestRunSpec) ParseRunnerScript(index int) (*types.Script, error) {
+	if script, ok := k6.PerRunnerScripts[strconv.Itoa(index)]; ok {
+		return script.Parse()
+	}
+	return k6.ParseScript()
+}
+
+// IndexedRunners shows whether the runners are the pods of a single Indexed Job.
+func (k6 *TestRunSpec) IndexedRunners() bool {
+	return k6.CompletionMode == batchv1.IndexedCompletion
+}
+
+// validateCloudOutputToken checks that the token for cloud output is passed
+// only to runners which stream to Grafana Cloud k6 on their own.
+func (k6 *TestRunSpec) validateCloudOutputToken(cli *types.CLI) error {
+	ref := k6.CloudOutputToken
+	if ref == nil {
+		return nil
+	}
+
+	switch {
+	case len(ref.Name) == 0 || len(ref.Key) == 0:
+		return errors.New("cloudOutputToken requires the name and the key of a Secret")
+	case !cli.HasCloudOut:
+		return errors.New("cloudOutputToken requires `--out cloud` in arguments")
+	case len(k6.Token) > 0 || len(k6.TestRunID) > 0:
+		return errors.New("cloudOutputToken cannot be used with test runs of Private Load Zones")
+	}
+	return nil
+}
+
+// validateIndexedRunners checks that the Indexed Job of the runners isn't
+// combined with the features which need a Job per runner.
+func (k6 *TestRunSpec) validateIndexedRunners() error {
+	if !k6.IndexedRunners() {
+		return nil
+	}
+
+	var unsupported string
+	switch {
+	case k6.HeadlessService:
+		unsupported = "headlessService"
+	case k6.MinReadyRunners > 0:
+		unsupported = "minReadyRunners"
+	case k6.TolerateRunnerEviction:
+		unsupported = "tolerateRunnerEviction"
+	case k6.NodeLossPolicy == RejoinOnNodeLoss:
+		unsupported = "nodeLossPolicy Rejoin"
+	case k6.OutputVolume != nil && k6.OutputVolume.PerRunner:
+		unsupported = "outputVolume.perRunner"
+	case k6.Runner.HasCommandOverride():
+		unsupported = "runner command and args"
+	case len(k6.PerRunnerScripts) > 0:
+		unsupported = "perRunnerScripts"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s cannot be used with the Indexed completion mode", unsupported)
+}
+
+// reservedVolumeNames are the names of the volumes managed by the operator.
+var reservedVolumeNames = []string{"k6-test-volume", "k6-output-volume", "k6-browser-shm", "k6-thresholds-volume"}
+
+// validateRunnerVolumes checks that the volumes of the runner don't collide
+// with the ones managed by the operator: the script and the output volume.
+func (k6 *TestRunSpec) validateRunnerVolumes() error {
+	var managed []corev1.VolumeMount
+	if script, err := k6.ParseScript(); err == nil {
+		managed = append(managed, script.VolumeMount()...)
+	}
+	for _, s := range k6.PerRunnerScripts {
+		if script, err := s.Parse(); err == nil {
+			managed = append(managed, script.VolumeMount()...)
+		}
+	}
+	if k6.OutputVolume != nil {
+		mountPath := "/output"
+		if len(k6.OutputVolume.MountPath) > 0 {
+			mountPath = k6.OutputVolume.MountPath
+		}
+		managed = append(managed, corev1.VolumeMount{Name: "k6-output-volume", MountPath: mountPath})
+	}
+
+	if k6.Runner.BrowserMode {
+		managed = append(managed, corev1.VolumeMount{Name: "k6-browser-shm", MountPath: "/dev/shm"})
+	}
+	if k6.ThresholdsOverride != nil {
+		managed = append(managed, corev1.VolumeMount{Name: "k6-thresholds-volume", MountPath: "/thresholds"})
+	}
+
+	for _, volume := range k6.Runner.Volumes {
+		if slices.Contains(reservedVolumeNames, volume.Name) {
+			return fmt.Errorf("runner volume name `%s` is reserved by the operator", volume.Name)
+		}
+	}
+
+	for _, mount := range k6.Runner.VolumeMounts {
+		for _, m := range managed {
+			if nestedPaths(mount.MountPath, m.MountPath) {
+				return fmt.Errorf("runner volume mount `%s` at %s collides with %s mounted by the operator", mount.Name, mount.MountPath, m.MountPath)
+			}
+		}
+	}
+	return nil
+}
+
+// nestedPaths shows whether the paths are the same or one of them is inside the other.
+func nestedPaths(a, b string) bool {
+	a = strings.TrimSuffix(path.Clean(a), "/") + "/"
+	b = strings.TrimSuffix(path.Clean(b), "/") + "/"
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
 }
 
 // Parse extracts Script data bits from K6 spec and performs basic validation
 func (k6 TestRunSpec) ParseScript() (*types.Script, error) {
-	spec := k6.Script
+	return k6.Script.Parse()
+}
+
+// Parse extracts the location of the script and performs basic validation.
+func (spec K6Script) Parse() (*types.Script, error) {
 	s := &types.Script{}
 
 	// VolumeClaim: allow file to include a path component (e.g. "subdir/script.js").
@@ -263,7 +1196,28 @@ func (k6 TestRunSpec) ParseScript() (*types.Script, error) {
 		return s, nil
 	}
 
-	return nil, errors.New("script definition should contain one of: ConfigMap, VolumeClaim, LocalFile")
+	// URL: downloaded to "/test" and named after the last element of the URL path
+	if spec.URL != nil {
+		u, err := url.Parse(spec.URL.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return nil, fmt.Errorf("script URL `%s` is invalid: an absolute http or https URL is expected", spec.URL.URL)
+		}
+
+		s.Name = "URL"
+		s.Path = "/test/"
+		s.Filename = "test.js"
+		if base := path.Base(u.Path); len(path.Ext(base)) > 0 {
+			s.Filename = base
+		}
+		s.URL = spec.URL.URL
+		s.AuthSecretRef = spec.URL.AuthSecretRef
+		s.FetchImage = spec.URL.Image
+
+		s.Type = "URL"
+		return s, nil
+	}
+
+	return nil, errors.New("script definition should contain one of: ConfigMap, VolumeClaim, LocalFile, URL")
 }
 
 // TestRunI implementation for TestRun
@@ -287,9 +1241,31 @@ func (k6 *TestRun) TestRunID() string {
 	if len(specId) > 0 {
 		return specId
 	}
-	return k6.GetStatus().TestRunID
+	if len(k6.GetStatus().TestRunID) > 0 {
+		return k6.GetStatus().TestRunID
+	}
+	// the status might not have been updated yet
+	return k6.GetAnnotations()[CloudTestRunIDAnnotation]
+}
+
+// RunnerLabels returns the labels identifying the runners of the test run.
+// The UID of the TestRun tells them apart from other objects with the same
+// name labels, e.g. pods created by users or left by a deleted TestRun.
+func (k6 *TestRun) RunnerLabels() map[string]string {
+	runnerLabels := map[string]string{
+		"app":    "k6",
+		"k6_cr":  k6.NamespacedName().Name,
+		"runner": "true",
+	}
+	if len(k6.UID) > 0 {
+		runnerLabels[TestRunUIDLabel] = string(k6.UID)
+	}
+	return runnerLabels
 }
 
+// ListOptions selects the runners of the test run by their name labels only,
+// as runners created before TestRunUIDLabel was introduced don't have it.
+// The listed objects must be filtered with IsRunnerOf.
 func (k6 *TestRun) ListOptions() *client.ListOptions {
 	selector := labels.SelectorFromSet(map[string]string{
 		"app":    "k6",
@@ -299,3 +1275,158 @@ func (k6 *TestRun) ListOptions() *client.ListOptions {
 
 	return &client.ListOptions{LabelSelector: selector, Namespace: k6.NamespacedName().Namespace}
 }
+
+// IsRunnerOf shows whether the object listed with ListOptions belongs to the
+// test run. An object without TestRunUIDLabel belongs to it if it's controlled
+// by the TestRun or, for a runner pod, by a Job.
+func (k6 *TestRun) IsRunnerOf(obj metav1.Object) bool {
+	if uid, ok := obj.GetLabels()[TestRunUIDLabel]; ok {
+		return uid == string(k6.UID)
+	}
+	if metav1.IsControlledBy(obj, k6) {
+		return true
+	}
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "Job"
+}
+
+// RunsSetup shows whether setup() is executed by the operator
+// instead of each runner.
+func (k6 *TestRun) RunsSetup() bool {
+	return IsTrue(k6, CloudPLZTestRun) || k6.GetSpec().Setup != nil
+}
+
+// RetriesSetup shows whether a failed setup() should be executed again.
+func (k6 *TestRun) RetriesSetup() bool {
+	return k6.GetSpec().Setup != nil && k6.GetSpec().Setup.FailurePolicy == RetryOnSetupFailure
+}
+
+// HasCommandOverride shows whether the command or args of the k6 container are set by the user.
+func (p *RunnerPod) HasCommandOverride() bool {
+	return len(p.Command) > 0 || len(p.Args) > 0
+}
+
+// PassesPaused shows whether the custom command, args or env of the k6 container
+// make k6 start paused.
+func (p *RunnerPod) PassesPaused() bool {
+	for _, arg := range append(append([]string{}, p.Command...), p.Args...) {
+		if arg == "--paused" || arg == "-p" || arg == "--paused=true" {
+			return true
+		}
+	}
+	for _, env := range p.Env {
+		if env.Name == "K6_PAUSED" {
+			paused, _ := strconv.ParseBool(env.Value)
+			return paused
+		}
+	}
+	return false
+}
+
+// ValidateRunnerCommand checks that runners start paused whenever the operator
+// is supposed to start them. The operator adds `--paused` itself, unless
+// the command of runners is overridden.
+func (k6 *TestRun) ValidateRunnerCommand() error {
+	runner := &k6.GetSpec().Runner
+	if runner.HasCommandOverride() && k6.RunnersPaused() && !runner.PassesPaused() {
+		return errors.New("runner command and args must pass `--paused` to k6, or env must set K6_PAUSED=true: " +
+			"otherwise runners start the test on their own instead of all at once")
+	}
+	return nil
+}
+
+// ValidateRunnerRestartPolicy checks that runners are restarted on failure
+// only if they aren't paused: the operator starts the runners once, so
+// a restarted k6 would stay paused until the test run times out.
+func (k6 *TestRun) ValidateRunnerRestartPolicy() error {
+	if k6.GetSpec().Runner.RestartPolicy == corev1.RestartPolicyOnFailure && k6.RunnersPaused() {
+		return errors.New("runner restartPolicy OnFailure requires runners which aren't paused: " +
+			"set paused to false, otherwise restarted runners are never started")
+	}
+	return nil
+}
+
+// RunnersPaused shows whether runners are started with `--paused`
+// and wait for the operator to start the test.
+func (k6 *TestRun) RunnersPaused() bool {
+	if k6.Standalone() {
+		// there is nothing to wait for
+		return false
+	}
+	if k6.GetSpec().Paused != "" {
+		paused, _ := strconv.ParseBool(k6.GetSpec().Paused)
+		return paused
+	}
+	return true
+}
+
+// Runners returns the number of runners taking part in the test:
+// all of them except the ones left out with MinReadyRunners.
+func (k6 *TestRun) Runners() int32 {
+	return k6.GetSpec().Parallelism - int32(len(k6.GetStatus().MissingRunners))
+}
+
+// IsMissingRunner shows whether the runner Job with the given name was left out of the test.
+func (k6 *TestRun) IsMissingRunner(name string) bool {
+	return slices.Contains(k6.GetStatus().MissingRunners, name)
+}
+
+// InheritedMetadata returns the labels and annotations of the TestRun
+// selected with InheritLabels and InheritAnnotations.
+func (k6 *TestRun) InheritedMetadata() (map[string]string, map[string]string) {
+	annotations := selectKeys(k6.GetAnnotations(), k6.GetSpec().InheritAnnotations)
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	return selectKeys(k6.GetLabels(), k6.GetSpec().InheritLabels), annotations
+}
+
+// selectKeys returns the entries of m with the given keys or,
+// for keys ending with `*`, with the given prefixes.
+func selectKeys(m map[string]string, keys []string) map[string]string {
+	selected := make(map[string]string)
+	for _, key := range keys {
+		if prefix, ok := strings.CutSuffix(key, "*"); ok {
+			for k, v := range m {
+				if strings.HasPrefix(k, prefix) {
+					selected[k] = v
+				}
+			}
+		} else if v, ok := m[key]; ok {
+			selected[key] = v
+		}
+	}
+	return selected
+}
+
+// RunnerResources returns the resources of the k6 container of runners:
+// the ones of Runner or, if there are none, the ones computed with AutoResources.
+func (k6 *TestRun) RunnerResources() corev1.ResourceRequirements {
+	resources := k6.GetSpec().Runner.Resources
+	if len(resources.Requests) == 0 && len(resources.Limits) == 0 && k6.GetStatus().RunnerResources != nil {
+		return *k6.GetStatus().RunnerResources
+	}
+	return resources
+}
+
+// Standalone shows whether the test run has a single runner which doesn't
+// need to be coordinated by the operator: it isn't paused, so it starts
+// the test right away, and it doesn't get a Service. A test run with Paused
+// set explicitly is never standalone: it's started as configured.
+func (k6 *TestRun) Standalone() bool {
+	spec := k6.GetSpec()
+	return spec.Parallelism == 1 &&
+		len(spec.Paused) == 0 &&
+		!IsTrue(k6, CloudTestRun) &&
+		!IsTrue(k6, CloudPLZTestRun) &&
+		spec.Setup == nil &&
+		!spec.ConfirmStart &&
+		!spec.HeadlessService &&
+		len(spec.ServiceType) == 0
+}
+
+// Period returns how often the probe is checked.
+func (p *K6PreconditionProbe) Period() time.Duration {
+	if p.PeriodSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(p.PeriodSeconds) * time.Second
+}
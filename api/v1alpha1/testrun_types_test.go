@@ -3,8 +3,12 @@ package v1alpha1
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/grafana/k6-operator/pkg/types"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_ParseScript(t *testing.T) {
@@ -112,6 +116,50 @@ func Test_ParseScript(t *testing.T) {
 				},
 			},
 		},
+		{
+			"URL",
+			false,
+			&types.Script{
+				Name:     "URL",
+				Path:     "/test/",
+				Filename: "script.js",
+				Type:     "URL",
+				URL:      "https://raw.example.com/team/tests/main/script.js",
+			},
+
+			&TestRunSpec{
+				Script: K6Script{
+					URL: &K6ScriptURL{URL: "https://raw.example.com/team/tests/main/script.js"},
+				},
+			},
+		},
+		{
+			"URL without a file name",
+			false,
+			&types.Script{
+				Name:     "URL",
+				Path:     "/test/",
+				Filename: "test.js",
+				Type:     "URL",
+				URL:      "https://tests.example.com/latest",
+			},
+
+			&TestRunSpec{
+				Script: K6Script{
+					URL: &K6ScriptURL{URL: "https://tests.example.com/latest"},
+				},
+			},
+		},
+		{
+			"Invalid URL",
+			true,
+			nil,
+			&TestRunSpec{
+				Script: K6Script{
+					URL: &K6ScriptURL{URL: "git@example.com:team/tests.git"},
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -132,3 +180,264 @@ func Test_ParseScript(t *testing.T) {
 		})
 	}
 }
+
+func Test_OutputVolumeClaimNames(t *testing.T) {
+	testCases := []struct {
+		name     string
+		volume   K6OutputVolume
+		expected []string
+	}{
+		{
+			"Shared claim",
+			K6OutputVolume{ClaimName: "results"},
+			[]string{"results"},
+		},
+		{
+			"Claim per runner",
+			K6OutputVolume{ClaimName: "results", PerRunner: true},
+			[]string{"results-1", "results-2", "results-3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			names := testCase.volume.ClaimNames(3)
+			if !reflect.DeepEqual(names, testCase.expected) {
+				t.Errorf("ClaimNames returned unexpected data, got: %v, expected: %v", names, testCase.expected)
+			}
+		})
+	}
+}
+
+func Test_Standalone(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     TestRunSpec
+		cloud    bool
+		expected bool
+	}{
+		{"Single runner", TestRunSpec{Parallelism: 1}, false, true},
+		{"Distributed", TestRunSpec{Parallelism: 2}, false, false},
+		{"Cloud output", TestRunSpec{Parallelism: 1}, true, false},
+		{"Setup by the operator", TestRunSpec{Parallelism: 1, Setup: &K6Setup{}}, false, false},
+		{"NodePort service", TestRunSpec{Parallelism: 1, ServiceType: "NodePort"}, false, false},
+		{"Paused explicitly", TestRunSpec{Parallelism: 1, Paused: "true"}, false, false},
+		{"Not paused explicitly", TestRunSpec{Parallelism: 1, Paused: "false"}, false, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			k6 := &TestRun{Spec: testCase.spec}
+			Initialize(k6)
+			if testCase.cloud {
+				UpdateCondition(k6, CloudTestRun, metav1.ConditionTrue)
+			}
+
+			if standalone := k6.Standalone(); standalone != testCase.expected {
+				t.Errorf("Standalone returned %v, expected: %v", standalone, testCase.expected)
+			}
+		})
+	}
+}
+
+func Test_ValidateRunnerRestartPolicy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    TestRunSpec
+		isValid bool
+	}{
+		{"Default", TestRunSpec{Parallelism: 2}, true},
+		{"OnFailure with paused runners", TestRunSpec{Parallelism: 2, Runner: RunnerPod{RestartPolicy: corev1.RestartPolicyOnFailure}}, false},
+		{"OnFailure without pausing", TestRunSpec{Parallelism: 2, Paused: "false", Runner: RunnerPod{RestartPolicy: corev1.RestartPolicyOnFailure}}, true},
+		{"OnFailure standalone", TestRunSpec{Parallelism: 1, Runner: RunnerPod{RestartPolicy: corev1.RestartPolicyOnFailure}}, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			k6 := &TestRun{Spec: testCase.spec}
+			Initialize(k6)
+
+			if err := k6.ValidateRunnerRestartPolicy(); (err == nil) != testCase.isValid {
+				t.Errorf("ValidateRunnerRestartPolicy returned %v, expected valid: %v", err, testCase.isValid)
+			}
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    TestRunSpec
+		isValid bool
+	}{
+		{"default command", TestRunSpec{}, true},
+		{"command override", TestRunSpec{Runner: RunnerPod{Command: []string{"/wrapper.sh"}, Args: []string{"run", "test.js"}}}, true},
+		{"segment in args", TestRunSpec{Runner: RunnerPod{Args: []string{"run", "--execution-segment=0:1/2", "test.js"}}}, false},
+		{"segment sequence in command", TestRunSpec{Runner: RunnerPod{Command: []string{"k6", "run", "--execution-segment-sequence=0,1"}}}, false},
+		{"fixtures volume", TestRunSpec{
+			Script: K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.js"}},
+			Runner: RunnerPod{
+				Pod: Pod{
+					Volumes:      []corev1.Volume{{Name: "fixtures"}},
+					VolumeMounts: []corev1.VolumeMount{{Name: "fixtures", MountPath: "/fixtures"}},
+				},
+			},
+		}, true},
+		{"mount over the script", TestRunSpec{
+			Script: K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.js"}},
+			Runner: RunnerPod{Pod: Pod{VolumeMounts: []corev1.VolumeMount{{Name: "fixtures", MountPath: "/test/"}}}},
+		}, false},
+		{"mount inside the script", TestRunSpec{
+			Script: K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.js"}},
+			Runner: RunnerPod{Pod: Pod{VolumeMounts: []corev1.VolumeMount{{Name: "fixtures", MountPath: "/test/data"}}}},
+		}, false},
+		{"mount next to the script", TestRunSpec{
+			Script: K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.js"}},
+			Runner: RunnerPod{Pod: Pod{VolumeMounts: []corev1.VolumeMount{{Name: "fixtures", MountPath: "/testdata"}}}},
+		}, true},
+		{"mount over the output", TestRunSpec{
+			OutputVolume: &K6OutputVolume{ClaimName: "results"},
+			Runner:       RunnerPod{Pod: Pod{VolumeMounts: []corev1.VolumeMount{{Name: "fixtures", MountPath: "/"}}}},
+		}, false},
+		{"reserved volume name", TestRunSpec{Runner: RunnerPod{Pod: Pod{Volumes: []corev1.Volume{{Name: "k6-test-volume"}}}}}, false},
+		{"mount over the shm of browser", TestRunSpec{
+			Runner: RunnerPod{Pod: Pod{VolumeMounts: []corev1.VolumeMount{{Name: "shm", MountPath: "/dev/shm"}}}, BrowserMode: true},
+		}, false},
+		{"quorum of runners", TestRunSpec{Parallelism: 4, MinReadyRunners: 3}, true},
+		{"quorum larger than parallelism", TestRunSpec{Parallelism: 2, MinReadyRunners: 3}, false},
+		{"runners at pod IPs", TestRunSpec{Parallelism: 3, RunnerPodIPs: true}, true},
+		{"thresholds override", TestRunSpec{
+			Script:             K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.js"}},
+			ThresholdsOverride: &K6ThresholdsOverride{ConfigMap: K6Configmap{Name: "thresholds"}},
+		}, true},
+		{"thresholds override of an archive", TestRunSpec{
+			Script:             K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.tar"}},
+			ThresholdsOverride: &K6ThresholdsOverride{ConfigMap: K6Configmap{Name: "thresholds"}},
+		}, false},
+		{"thresholds override without ConfigMap", TestRunSpec{
+			Script:             K6Script{ConfigMap: K6Configmap{Name: "test", File: "test.js"}},
+			ThresholdsOverride: &K6ThresholdsOverride{},
+		}, false},
+		{"kept runners with command override", TestRunSpec{
+			KeepRunnersAfterFinish: &metav1.Duration{Duration: time.Minute},
+			Runner:                 RunnerPod{Command: []string{"/wrapper.sh"}},
+		}, false},
+		{"runners at pod IPs with headless service", TestRunSpec{Parallelism: 3, RunnerPodIPs: true, HeadlessService: true}, false},
+		{"indexed runners", TestRunSpec{Parallelism: 3, CompletionMode: batchv1.IndexedCompletion}, true},
+		{"indexed runners with quorum", TestRunSpec{Parallelism: 3, MinReadyRunners: 2, CompletionMode: batchv1.IndexedCompletion}, false},
+		{"cloud output token", TestRunSpec{
+			Parallelism:      1,
+			Arguments:        "--out cloud",
+			CloudOutputToken: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "k6-output"}, Key: "token"},
+		}, true},
+		{"cloud output token without cloud output", TestRunSpec{
+			Parallelism:      1,
+			CloudOutputToken: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "k6-output"}, Key: "token"},
+		}, false},
+		{"cloud output token without a key", TestRunSpec{
+			Parallelism:      1,
+			Arguments:        "--out cloud",
+			CloudOutputToken: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "k6-output"}},
+		}, false},
+		{"cloud output token in a Private Load Zone", TestRunSpec{
+			Parallelism:      1,
+			Arguments:        "--out cloud",
+			Token:            "plz-token",
+			CloudOutputToken: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "k6-output"}, Key: "token"},
+		}, false},
+		{"indexed runners failing on node loss", TestRunSpec{Parallelism: 3, NodeLossPolicy: FailOnNodeLoss, CompletionMode: batchv1.IndexedCompletion}, true},
+		{"indexed runners rejoining on node loss", TestRunSpec{Parallelism: 3, NodeLossPolicy: RejoinOnNodeLoss, CompletionMode: batchv1.IndexedCompletion}, false},
+		{"indexed runners with command override", TestRunSpec{
+			Parallelism:    3,
+			CompletionMode: batchv1.IndexedCompletion,
+			Runner:         RunnerPod{Command: []string{"/wrapper.sh"}},
+		}, false},
+		{"per-runner scripts", TestRunSpec{
+			Parallelism:      2,
+			Script:           K6Script{ConfigMap: K6Configmap{Name: "test", File: "a.js"}},
+			PerRunnerScripts: map[string]K6Script{"2": {ConfigMap: K6Configmap{Name: "test", File: "b.js"}}},
+		}, true},
+		{"per-runner script of a missing runner", TestRunSpec{
+			Parallelism:      2,
+			PerRunnerScripts: map[string]K6Script{"3": {ConfigMap: K6Configmap{Name: "test", File: "b.js"}}},
+		}, false},
+		{"per-runner script by name", TestRunSpec{
+			Parallelism:      2,
+			PerRunnerScripts: map[string]K6Script{"test-2": {ConfigMap: K6Configmap{Name: "test", File: "b.js"}}},
+		}, false},
+		{"empty per-runner script", TestRunSpec{Parallelism: 2, PerRunnerScripts: map[string]K6Script{"2": {}}}, false},
+		{"indexed runners with per-runner scripts", TestRunSpec{
+			Parallelism:      2,
+			CompletionMode:   batchv1.IndexedCompletion,
+			PerRunnerScripts: map[string]K6Script{"2": {ConfigMap: K6Configmap{Name: "test", File: "b.js"}}},
+		}, false},
+	}
+
+	for _, tc := range testCases {
+		err := tc.spec.Validate()
+		if tc.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.isValid && err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func Test_ValidateRunnerService(t *testing.T) {
+	testCases := []struct {
+		name         string
+		service      *K6RunnerService
+		expectedName string
+		isValid      bool
+	}{
+		{"default", nil, "test-service-1", true},
+		{"name template", &K6RunnerService{NameTemplate: "svc-{{.Name}}-{{.Index}}"}, "svc-test-1", true},
+		{"same name for all runners", &K6RunnerService{NameTemplate: "svc-{{.Name}}"}, "svc-test", false},
+		{"unknown field", &K6RunnerService{NameTemplate: "{{.Namespace}}-{{.Index}}"}, "", false},
+		{"invalid name", &K6RunnerService{NameTemplate: "{{.Index}}-{{.Name}}"}, "", false},
+		{"extra port", &K6RunnerService{ExtraPorts: []corev1.ServicePort{{Name: "metrics", Port: 9090}}}, "test-service-1", true},
+		{"extra port of k6", &K6RunnerService{ExtraPorts: []corev1.ServicePort{{Name: "api", Port: 6565}}}, "test-service-1", false},
+	}
+
+	for _, tc := range testCases {
+		k6 := &TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       TestRunSpec{RunnerService: tc.service},
+		}
+
+		err := k6.ValidateRunnerService()
+		if tc.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.isValid && err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+
+		if name, err := k6.RunnerServiceName(1); err == nil && name != tc.expectedName {
+			t.Errorf("%s: expected name %q, got %q", tc.name, tc.expectedName, name)
+		}
+	}
+}
+
+func Test_SetIfNewerCreating(t *testing.T) {
+	testCases := []struct {
+		current, proposed, expected Stage
+	}{
+		{"initialized", "creating", "creating"},
+		{"pending", "creating", "creating"},
+		{"creating", "created", "created"},
+		{"creating", "error", "error"},
+		{"creating", "initialization", "creating"},
+		{"creating", "pending", "creating"},
+		{"created", "creating", "created"},
+	}
+
+	for _, tc := range testCases {
+		status := TestRunStatus{Stage: tc.current}
+		status.SetIfNewer(TestRunStatus{Stage: tc.proposed})
+		if status.Stage != tc.expected {
+			t.Errorf("%s -> %s: expected stage %s, got %s", tc.current, tc.proposed, tc.expected, status.Stage)
+		}
+	}
+}
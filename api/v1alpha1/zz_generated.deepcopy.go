@@ -78,6 +78,85 @@ func (in *InitContainer) DeepCopy() *InitContainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6ArtifactUpload) DeepCopyInto(out *K6ArtifactUpload) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6ArtifactUpload.
+func (in *K6ArtifactUpload) DeepCopy() *K6ArtifactUpload {
+	if in == nil {
+		return nil
+	}
+	out := new(K6ArtifactUpload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6AutoResources) DeepCopyInto(out *K6AutoResources) {
+	*out = *in
+	if in.MemoryPerVU != nil {
+		in, out := &in.MemoryPerVU, &out.MemoryPerVU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPUPerVU != nil {
+		in, out := &in.CPUPerVU, &out.CPUPerVU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BaseMemory != nil {
+		in, out := &in.BaseMemory, &out.BaseMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BaseCPU != nil {
+		in, out := &in.BaseCPU, &out.BaseCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6AutoResources.
+func (in *K6AutoResources) DeepCopy() *K6AutoResources {
+	if in == nil {
+		return nil
+	}
+	out := new(K6AutoResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *K6Configmap) DeepCopyInto(out *K6Configmap) {
 	*out = *in
@@ -93,11 +172,123 @@ func (in *K6Configmap) DeepCopy() *K6Configmap {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6InfluxDB) DeepCopyInto(out *K6InfluxDB) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6InfluxDB.
+func (in *K6InfluxDB) DeepCopy() *K6InfluxDB {
+	if in == nil {
+		return nil
+	}
+	out := new(K6InfluxDB)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6OutputVolume) DeepCopyInto(out *K6OutputVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6OutputVolume.
+func (in *K6OutputVolume) DeepCopy() *K6OutputVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(K6OutputVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6Outputs) DeepCopyInto(out *K6Outputs) {
+	*out = *in
+	if in.PrometheusRemoteWrite != nil {
+		in, out := &in.PrometheusRemoteWrite, &out.PrometheusRemoteWrite
+		*out = new(K6PrometheusRemoteWrite)
+		**out = **in
+	}
+	if in.InfluxDB != nil {
+		in, out := &in.InfluxDB, &out.InfluxDB
+		*out = new(K6InfluxDB)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6Outputs.
+func (in *K6Outputs) DeepCopy() *K6Outputs {
+	if in == nil {
+		return nil
+	}
+	out := new(K6Outputs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6PreconditionProbe) DeepCopyInto(out *K6PreconditionProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6PreconditionProbe.
+func (in *K6PreconditionProbe) DeepCopy() *K6PreconditionProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(K6PreconditionProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6PrometheusRemoteWrite) DeepCopyInto(out *K6PrometheusRemoteWrite) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6PrometheusRemoteWrite.
+func (in *K6PrometheusRemoteWrite) DeepCopy() *K6PrometheusRemoteWrite {
+	if in == nil {
+		return nil
+	}
+	out := new(K6PrometheusRemoteWrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6RunnerService) DeepCopyInto(out *K6RunnerService) {
+	*out = *in
+	if in.ExtraPorts != nil {
+		in, out := &in.ExtraPorts, &out.ExtraPorts
+		*out = make([]v1.ServicePort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6RunnerService.
+func (in *K6RunnerService) DeepCopy() *K6RunnerService {
+	if in == nil {
+		return nil
+	}
+	out := new(K6RunnerService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *K6Script) DeepCopyInto(out *K6Script) {
 	*out = *in
 	out.VolumeClaim = in.VolumeClaim
 	out.ConfigMap = in.ConfigMap
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(K6ScriptURL)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6Script.
@@ -110,6 +301,26 @@ func (in *K6Script) DeepCopy() *K6Script {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6ScriptURL) DeepCopyInto(out *K6ScriptURL) {
+	*out = *in
+	if in.AuthSecretRef != nil {
+		in, out := &in.AuthSecretRef, &out.AuthSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6ScriptURL.
+func (in *K6ScriptURL) DeepCopy() *K6ScriptURL {
+	if in == nil {
+		return nil
+	}
+	out := new(K6ScriptURL)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *K6Scuttle) DeepCopyInto(out *K6Scuttle) {
 	*out = *in
@@ -125,6 +336,72 @@ func (in *K6Scuttle) DeepCopy() *K6Scuttle {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6Setup) DeepCopyInto(out *K6Setup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6Setup.
+func (in *K6Setup) DeepCopy() *K6Setup {
+	if in == nil {
+		return nil
+	}
+	out := new(K6Setup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6ThresholdsOverride) DeepCopyInto(out *K6ThresholdsOverride) {
+	*out = *in
+	out.ConfigMap = in.ConfigMap
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6ThresholdsOverride.
+func (in *K6ThresholdsOverride) DeepCopy() *K6ThresholdsOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(K6ThresholdsOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6TokenSource) DeepCopyInto(out *K6TokenSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6TokenSource.
+func (in *K6TokenSource) DeepCopy() *K6TokenSource {
+	if in == nil {
+		return nil
+	}
+	out := new(K6TokenSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K6Tracing) DeepCopyInto(out *K6Tracing) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K6Tracing.
+func (in *K6Tracing) DeepCopy() *K6Tracing {
+	if in == nil {
+		return nil
+	}
+	out := new(K6Tracing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *K6VolumeClaim) DeepCopyInto(out *K6VolumeClaim) {
 	*out = *in
@@ -248,6 +525,28 @@ func (in *Pod) DeepCopyInto(out *Pod) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]v1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(v1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pod.
@@ -421,6 +720,167 @@ func (in *PrivateLoadZoneStatus) DeepCopy() *PrivateLoadZoneStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunnerDisruption) DeepCopyInto(out *RunnerDisruption) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunnerDisruption.
+func (in *RunnerDisruption) DeepCopy() *RunnerDisruption {
+	if in == nil {
+		return nil
+	}
+	out := new(RunnerDisruption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunnerPod) DeepCopyInto(out *RunnerPod) {
+	*out = *in
+	in.Pod.DeepCopyInto(&out.Pod)
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.BrowserShmSize != nil {
+		in, out := &in.BrowserShmSize, &out.BrowserShmSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunnerPod.
+func (in *RunnerPod) DeepCopy() *RunnerPod {
+	if in == nil {
+		return nil
+	}
+	out := new(RunnerPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledTestRun) DeepCopyInto(out *ScheduledTestRun) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledTestRun.
+func (in *ScheduledTestRun) DeepCopy() *ScheduledTestRun {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledTestRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledTestRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledTestRunList) DeepCopyInto(out *ScheduledTestRunList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScheduledTestRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledTestRunList.
+func (in *ScheduledTestRunList) DeepCopy() *ScheduledTestRunList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledTestRunList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledTestRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledTestRunSpec) DeepCopyInto(out *ScheduledTestRunSpec) {
+	*out = *in
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	in.TestRunTemplate.DeepCopyInto(&out.TestRunTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledTestRunSpec.
+func (in *ScheduledTestRunSpec) DeepCopy() *ScheduledTestRunSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledTestRunSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledTestRunStatus) DeepCopyInto(out *ScheduledTestRunStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledTestRunStatus.
+func (in *ScheduledTestRunStatus) DeepCopy() *ScheduledTestRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledTestRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestRun) DeepCopyInto(out *TestRun) {
 	*out = *in
@@ -483,12 +943,24 @@ func (in *TestRunList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestRunSpec) DeepCopyInto(out *TestRunSpec) {
 	*out = *in
-	out.Script = in.Script
+	in.Script.DeepCopyInto(&out.Script)
+	if in.PerRunnerScripts != nil {
+		in, out := &in.PerRunnerScripts, &out.PerRunnerScripts
+		*out = make(map[string]K6Script, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.Ports != nil {
 		in, out := &in.Ports, &out.Ports
 		*out = make([]v1.ContainerPort, len(*in))
 		copy(*out, *in)
 	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.Initializer != nil {
 		in, out := &in.Initializer, &out.Initializer
 		*out = new(Pod)
@@ -497,6 +969,96 @@ func (in *TestRunSpec) DeepCopyInto(out *TestRunSpec) {
 	in.Starter.DeepCopyInto(&out.Starter)
 	in.Runner.DeepCopyInto(&out.Runner)
 	out.Scuttle = in.Scuttle
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreconditionProbe != nil {
+		in, out := &in.PreconditionProbe, &out.PreconditionProbe
+		*out = new(K6PreconditionProbe)
+		**out = **in
+	}
+	if in.Setup != nil {
+		in, out := &in.Setup, &out.Setup
+		*out = new(K6Setup)
+		**out = **in
+	}
+	if in.AbortGracePeriodSeconds != nil {
+		in, out := &in.AbortGracePeriodSeconds, &out.AbortGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.OutputVolume != nil {
+		in, out := &in.OutputVolume, &out.OutputVolume
+		*out = new(K6OutputVolume)
+		**out = **in
+	}
+	if in.ArtifactUpload != nil {
+		in, out := &in.ArtifactUpload, &out.ArtifactUpload
+		*out = new(K6ArtifactUpload)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoResources != nil {
+		in, out := &in.AutoResources, &out.AutoResources
+		*out = new(K6AutoResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RunnerService != nil {
+		in, out := &in.RunnerService, &out.RunnerService
+		*out = new(K6RunnerService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = new(K6Outputs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.KeepRunnersAfterFinish != nil {
+		in, out := &in.KeepRunnersAfterFinish, &out.KeepRunnersAfterFinish
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(K6Tracing)
+		**out = **in
+	}
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ThresholdsOverride != nil {
+		in, out := &in.ThresholdsOverride, &out.ThresholdsOverride
+		*out = new(K6ThresholdsOverride)
+		**out = **in
+	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(K6TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudOutputToken != nil {
+		in, out := &in.CloudOutputToken, &out.CloudOutputToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InheritLabels != nil {
+		in, out := &in.InheritLabels, &out.InheritLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InheritAnnotations != nil {
+		in, out := &in.InheritAnnotations, &out.InheritAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunSpec.
@@ -512,6 +1074,62 @@ func (in *TestRunSpec) DeepCopy() *TestRunSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TestRunStatus) DeepCopyInto(out *TestRunStatus) {
 	*out = *in
+	if in.ThresholdsPassed != nil {
+		in, out := &in.ThresholdsPassed, &out.ThresholdsPassed
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RunnersKeptUntil != nil {
+		in, out := &in.RunnersKeptUntil, &out.RunnersKeptUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.NodePorts != nil {
+		in, out := &in.NodePorts, &out.NodePorts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TotalDuration != nil {
+		in, out := &in.TotalDuration, &out.TotalDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RunnerResources != nil {
+		in, out := &in.RunnerResources, &out.RunnerResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MissingRunners != nil {
+		in, out := &in.MissingRunners, &out.MissingRunners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Disruptions != nil {
+		in, out := &in.Disruptions, &out.Disruptions
+		*out = make([]RunnerDisruption, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SentCloudEvents != nil {
+		in, out := &in.SentCloudEvents, &out.SentCloudEvents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -530,3 +1148,20 @@ func (in *TestRunStatus) DeepCopy() *TestRunStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestRunTemplate) DeepCopyInto(out *TestRunTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestRunTemplate.
+func (in *TestRunTemplate) DeepCopy() *TestRunTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(TestRunTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,9 +20,13 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 
 	controllers "github.com/grafana/k6-operator/internal/controller"
+	webhookk6v1alpha1 "github.com/grafana/k6-operator/internal/webhook/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/plz"
+	"github.com/grafana/k6-operator/pkg/testrun"
+	"github.com/grafana/k6-operator/pkg/types"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -55,11 +59,69 @@ func main() {
 	var metricsAddr string
 	var healthAddr string
 	var enableLeaderElection bool
+	var useLegacyStarter bool
+	var httpWorkers, httpQueueSize int
+	var maxActiveTestRuns int
+	var statusID, statusType string
+	var requeueIntervals controllers.RequeueIntervals
+	var previousRunGracePeriod time.Duration
+	var enableWebhooks bool
+	var networkCheckAddr string
+	var watchNamespacesFlag string
+	var statusAddr string
+	var runnerTimeout time.Duration
+	var debugImages string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&healthAddr, "health-probe-bind-address", ":8081", "The address the health endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&useLegacyStarter, "use-legacy-starter", true,
+		"Start tests with a starter job running curl. "+
+			"If disabled, the operator sends the start requests to the runners itself.")
+	flag.IntVar(&httpWorkers, "http-workers", controllers.DefaultHTTPWorkers,
+		"The number of workers sending start requests to the runners, if the legacy starter is disabled.")
+	flag.IntVar(&httpQueueSize, "http-queue-size", 0,
+		"The number of requests to the runners which can wait for a free worker, if the legacy starter is disabled. "+
+			"Defaults to the number of workers.")
+	flag.StringVar(&statusID, "status-id", types.DefaultStatusID,
+		"The id of the status resource in the requests to k6 REST API, if the legacy starter is disabled.")
+	flag.StringVar(&statusType, "status-type", types.DefaultStatusType,
+		"The type of the status resource in the requests to k6 REST API, if the legacy starter is disabled.")
+
+	flag.DurationVar(&requeueIntervals.Short, "requeue-short", controllers.DefaultRequeueIntervals.Short,
+		"The delay between checks of a test run waiting for something expected soon, e.g. runners to get ready.")
+	flag.DurationVar(&requeueIntervals.Medium, "requeue-medium", controllers.DefaultRequeueIntervals.Medium,
+		"The delay between checks of a test run waiting for slower steps, e.g. initialization.")
+	flag.DurationVar(&requeueIntervals.Long, "requeue-long", controllers.DefaultRequeueIntervals.Long,
+		"The delay between checks of a running test.")
+	flag.DurationVar(&requeueIntervals.PreviousRun, "requeue-previous-run", controllers.DefaultRequeueIntervals.PreviousRun,
+		"The delay between checks of a test run waiting for the runners of a deleted previous run with the same name to go away.")
+	flag.DurationVar(&previousRunGracePeriod, "previous-run-grace-period", controllers.DefaultPreviousRunGracePeriod,
+		"How long a test run waits for the runners of a deleted previous run with the same name to go away before it fails.")
+
+	flag.DurationVar(&runnerTimeout, "runner-timeout", testrun.DefaultRunnerTimeout,
+		"The timeout of requests to k6 REST API of the runners, e.g. to check their status, start or stop them. "+
+			"setup() and teardown() executed by the operator are limited by setupTimeout and teardownTimeout of the script instead. "+
+			"Zero means no timeout.")
+
+	flag.IntVar(&maxActiveTestRuns, "max-active-testruns", 0,
+		"The maximum number of TestRuns with runners at once. Other TestRuns wait in the pending stage. Zero means no limit.")
+
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the defaulting webhook of TestRun. It requires the webhook configuration and certificates to be deployed, see the [WEBHOOK] sections of config/default.")
+	flag.StringVar(&networkCheckAddr, "network-check-address", "",
+		"The address which the operator must be able to connect to in order to be ready, e.g. host:port of a Service "+
+			"in the namespace of the runners, behind the same NetworkPolicies. Leave empty to disable the check.")
+	flag.StringVar(&statusAddr, "status-bind-address", "",
+		"The address the endpoint serving the status of test runs binds to, e.g. :8082. "+
+			"Requests need a bearer token of a user allowed to get the TestRun. Leave empty to disable it.")
+	flag.StringVar(&debugImages, "debug-images", "",
+		"Comma-separated list of images of debug containers which can be injected into runners with the k6.io/debug annotation. "+
+			"The first one is used by default. Leave empty to disable debug containers.")
+	flag.StringVar(&watchNamespacesFlag, "watch-namespaces", "",
+		"Comma-separated list of namespaces where TestRuns are reconciled, or `all` for the whole cluster. "+
+			"Takes precedence over WATCH_NAMESPACES and WATCH_NAMESPACE env vars.")
 
 	opts := zap.Options{
 		Development: true,
@@ -68,6 +130,7 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	testrun.SetRunnerTimeout(runnerTimeout)
 
 	mgrOpts := ctrl.Options{
 		Scheme: scheme,
@@ -83,7 +146,7 @@ func main() {
 		HealthProbeBindAddress:     healthAddr,
 	}
 
-	if watchNamespaces, multiNamespaced := getWatchNamespaces(); multiNamespaced {
+	if watchNamespaces, multiNamespaced := getWatchNamespaces(watchNamespacesFlag); multiNamespaced {
 		defaultNamespaces := make(map[string]cache.Config, len(watchNamespaces))
 		for _, ns := range watchNamespaces {
 			defaultNamespaces[ns] = cache.Config{}
@@ -91,7 +154,9 @@ func main() {
 		mgrOpts.Cache = cache.Options{
 			DefaultNamespaces: defaultNamespaces,
 		}
-		setupLog.Info("WATCH_NAMESPACES is configured, WATCH_NAMESPACE will be ignored", "ns", watchNamespaces)
+		setupLog.Info("Watched namespaces are configured, WATCH_NAMESPACE will be ignored", "ns", watchNamespaces)
+	} else if len(watchNamespacesFlag) > 0 {
+		setupLog.Info("All namespaces are watched")
 	} else if watchNamespace, namespaced := getWatchNamespace(); namespaced {
 		mgrOpts.Cache = cache.Options{
 			DefaultNamespaces: map[string]cache.Config{
@@ -110,10 +175,33 @@ func main() {
 	_ = mgr.AddHealthzCheck("health", healthz.Ping)
 	_ = mgr.AddReadyzCheck("ready", healthz.Ping)
 
+	// Runners are reached over the cluster network: without it, tests would hang at the start.
+	networkCheck := controllers.NetworkCheck(networkCheckAddr)
+	if err := networkCheck(nil); err != nil {
+		setupLog.Error(err, "operator cannot reach the network of the runners: check NetworkPolicies")
+	}
+	_ = mgr.AddReadyzCheck("network", networkCheck)
+
+	var allowedDebugImages []string
+	if len(debugImages) > 0 {
+		allowedDebugImages = strings.Split(debugImages, ",")
+	}
+
 	if err = (&controllers.TestRunReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("TestRun"),
-		Scheme: mgr.GetScheme(),
+		Client:                 mgr.GetClient(),
+		APIReader:              mgr.GetAPIReader(),
+		Log:                    ctrl.Log.WithName("controllers").WithName("TestRun"),
+		Scheme:                 mgr.GetScheme(),
+		UseLegacyStarter:       useLegacyStarter,
+		HTTPWorkers:            httpWorkers,
+		HTTPQueueSize:          httpQueueSize,
+		StatusID:               statusID,
+		StatusType:             statusType,
+		RequeueIntervals:       requeueIntervals,
+		MaxActiveTestRuns:      maxActiveTestRuns,
+		Recorder:               mgr.GetEventRecorderFor("k6-operator"),
+		PreviousRunGracePeriod: previousRunGracePeriod,
+		DebugImages:            allowedDebugImages,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TestRun")
 		os.Exit(1)
@@ -127,6 +215,34 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.ScheduledTestRunReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ScheduledTestRun"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ScheduledTestRun")
+		os.Exit(1)
+	}
+
+	if len(statusAddr) > 0 {
+		if err = mgr.Add(&controllers.StatusServer{
+			Client:   mgr.GetClient(),
+			Reviewer: mgr.GetClient(),
+			Log:      ctrl.Log.WithName("status"),
+			Address:  statusAddr,
+		}); err != nil {
+			setupLog.Error(err, "unable to add the status server")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		if err = webhookk6v1alpha1.SetupTestRunWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TestRun")
+			os.Exit(1)
+		}
+	}
+
 	plz.SetScheme(scheme)
 
 	// +kubebuilder:scaffold:builder
@@ -144,16 +260,23 @@ func getWatchNamespace() (string, bool) {
 	return os.LookupEnv(watchNamespaceEnvVar)
 }
 
-func getWatchNamespaces() ([]string, bool) {
+// getWatchNamespaces returns the namespaces from the --watch-namespaces flag
+// or, if it's not set, from WATCH_NAMESPACES env var. The flag set to `all`
+// disables both of them.
+func getWatchNamespaces(flagValue string) ([]string, bool) {
 	const watchNamespacesEnvVar = "WATCH_NAMESPACES"
 
-	if nsList, isSet := os.LookupEnv(watchNamespacesEnvVar); isSet {
-		// The Kubernetes docs state that namespace names can only contain contain lowercase
-		// alphanumeric characters or '-', making a comma (',') a valid separator for multiple namespaces.
-		// See: https://kubernetes.io/docs/tasks/administer-cluster/namespaces/#creating-a-new-namespace
-		// See: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-label-names
-		return strings.Split(nsList, ","), true
+	nsList, isSet := flagValue, len(flagValue) > 0
+	if !isSet {
+		nsList, isSet = os.LookupEnv(watchNamespacesEnvVar)
+	}
+	if !isSet || flagValue == "all" {
+		return nil, false
 	}
 
-	return nil, false
+	// The Kubernetes docs state that namespace names can only contain contain lowercase
+	// alphanumeric characters or '-', making a comma (',') a valid separator for multiple namespaces.
+	// See: https://kubernetes.io/docs/tasks/administer-cluster/namespaces/#creating-a-new-namespace
+	// See: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-label-names
+	return strings.Split(nsList, ","), true
 }
@@ -7,6 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,24 +19,63 @@ import (
 
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/cloud"
+	"github.com/grafana/k6-operator/pkg/resources/jobs"
 	"github.com/grafana/k6-operator/pkg/testrun"
+	"github.com/grafana/k6-operator/pkg/types"
+	"go.k6.io/k6/metrics"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	errMessageTooLong = "Creation of %s takes too long: your configuration might be off. Check if %v were created successfully."
+	errImagePullHint  = " Pods %s cannot pull their images (ImagePullBackOff): check the image names and imagePullSecrets."
 )
 
+// imagePullFailures returns names of the pods which are stuck on pulling images.
+func imagePullFailures(pods []corev1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if w := status.State.Waiting; w != nil &&
+				(w.Reason == "ImagePullBackOff" || w.Reason == "ErrImagePull" || w.Reason == "InvalidImageName") {
+				names = append(names, pod.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
 // It may take some time to retrieve inspect output so indicate with boolean if it's ready
 // and use returnErr only for errors that require a change of behaviour. All other errors
 // should just be logged.
+// The logs of the initializer are read only once: the output is kept in the status
+// by the caller and reused afterwards.
 func inspectTestRun(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, c client.Client) (
 	inspectOutput cloud.InspectOutput, ready bool, returnErr error) {
+	if inspection := k6.GetStatus().Inspection; len(inspection) > 0 {
+		if returnErr = json.Unmarshal([]byte(inspection), &inspectOutput); returnErr != nil {
+			log.Error(returnErr, fmt.Sprintf("unable to marshal: `%s`", inspection))
+			return
+		}
+		ready = true
+		return
+	}
+
 	var (
 		listOpts = &client.ListOptions{
 			Namespace: k6.NamespacedName().Namespace,
@@ -58,6 +102,9 @@ func inspectTestRun(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 	// there should be only 1 initializer pod
 	if podList.Items[0].Status.Phase == corev1.PodFailed {
 		returnErr = errors.New("initalizer job has failed")
+		if msg, failed := scriptFetchError(&podList.Items[0]); failed {
+			returnErr = fmt.Errorf("failed to fetch the script: %s", msg)
+		}
 		log.Error(returnErr, "error:")
 		return
 	}
@@ -66,7 +113,58 @@ func inspectTestRun(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 		return
 	}
 
-	// Here we need to get the output of the pod
+	buf, err := readPodLogs(ctx, k6.NamespacedName().Namespace, podList.Items[0].Name, &corev1.PodLogOptions{
+		Container: "k6",
+	})
+	if err != nil {
+		log.Error(err, "unable to read logs from the pod")
+		returnErr = err
+		return
+	}
+
+	if len(k6.GetSpec().Extensions) > 0 {
+		versionLogs, err := readPodLogs(ctx, k6.NamespacedName().Namespace, podList.Items[0].Name, &corev1.PodLogOptions{
+			Container: jobs.VersionContainerName,
+		})
+		if err != nil {
+			log.Error(err, "unable to read extensions of the runner image")
+			returnErr = err
+			return
+		}
+
+		if returnErr = checkExtensions(versionLogs.Bytes(), k6.GetSpec().Extensions); returnErr != nil {
+			log.Error(returnErr, "error:")
+			return
+		}
+	}
+
+	if returnErr = json.Unmarshal(buf.Bytes(), &inspectOutput); returnErr != nil {
+		// this shouldn't normally happen but if it does, let's log output by default
+		log.Error(returnErr, fmt.Sprintf("unable to marshal: `%s`", buf.String()))
+		return
+	}
+
+	k6.GetStatus().Inspection = buf.String()
+	ready = true
+	return
+}
+
+// scriptFetchError returns the termination message of the init container
+// downloading the script, if it has failed.
+func scriptFetchError(pod *corev1.Pod) (string, bool) {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != types.ScriptFetchContainerName {
+			continue
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return strings.TrimSpace(terminated.Message), true
+		}
+	}
+	return "", false
+}
+
+// readPodLogs returns the logs of the container of the pod.
+func readPodLogs(ctx context.Context, namespace, podName string, opts *corev1.PodLogOptions) (*bytes.Buffer, error) {
 	// pods/log is not currently supported by controller-runtime client and it is officially
 	// recommended to use REST client instead:
 	// https://github.com/kubernetes-sigs/controller-runtime/issues/1229
@@ -75,46 +173,142 @@ func inspectTestRun(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 	// How likely is it? Should we track frequency of these errors here?
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		log.Error(err, "unable to fetch in-cluster REST config")
-		returnErr = err
-		return
+		return nil, fmt.Errorf("unable to fetch in-cluster REST config: %w", err)
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Error(err, "unable to get access to clientset")
-		returnErr = err
-		return
+		return nil, fmt.Errorf("unable to get access to clientset: %w", err)
 	}
-	req := clientset.CoreV1().Pods(k6.NamespacedName().Namespace).GetLogs(podList.Items[0].Name, &corev1.PodLogOptions{
-		Container: "k6",
-	})
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
+	req := clientset.CoreV1().Pods(namespace).GetLogs(podName, opts)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*60)
 	defer cancel()
 
 	podLogs, err := req.Stream(ctx)
 	if err != nil {
-		log.Error(err, "unable to stream logs from the pod")
-		returnErr = err
-		return
+		return nil, fmt.Errorf("unable to stream logs from the pod: %w", err)
 	}
 	defer podLogs.Close() //nolint:errcheck
 
 	buf := new(bytes.Buffer)
-	_, err = io.Copy(buf, podLogs)
+	if _, err = io.Copy(buf, podLogs); err != nil {
+		return nil, fmt.Errorf("unable to copy logs from the pod: %w", err)
+	}
+
+	return buf, nil
+}
+
+const (
+	// runnerLogsTailLines and runnerLogsLimitBytes limit the logs of a failed runner
+	// kept in the status: the end of the logs is usually enough to see the error.
+	runnerLogsTailLines  int64 = 20
+	runnerLogsLimitBytes int64 = 2048
+)
+
+// failedRunnerLogs returns the tail of the logs of the latest failed pod of the runner job.
+// The logs are read only once, so if they cannot be read, the reason is returned instead:
+// the logs are a hint for the user and the pod might be gone already.
+func failedRunnerLogs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, jobName string) string {
+	pl := &corev1.PodList{}
+	if err := r.List(ctx, pl, client.InNamespace(k6.NamespacedName().Namespace), client.MatchingLabels{"job-name": jobName}); err != nil {
+		log.Error(err, "Could not list pods")
+		return fmt.Sprintf("Could not list pods of runner job %s: %v", jobName, err)
+	}
+
+	var pod *corev1.Pod
+	for i := range pl.Items {
+		if pl.Items[i].Status.Phase != corev1.PodFailed {
+			continue
+		}
+		if pod == nil || pod.CreationTimestamp.Before(&pl.Items[i].CreationTimestamp) {
+			pod = &pl.Items[i]
+		}
+	}
+	if pod == nil {
+		msg := fmt.Sprintf("No failed pod of runner job %s found", jobName)
+		log.Info(msg)
+		return msg
+	}
+
+	tailLines, limitBytes := runnerLogsTailLines, runnerLogsLimitBytes
+	buf, err := readPodLogs(ctx, pod.Namespace, pod.Name, &corev1.PodLogOptions{
+		Container:  "k6",
+		TailLines:  &tailLines,
+		LimitBytes: &limitBytes,
+	})
 	if err != nil {
-		log.Error(err, "unable to copy logs from the pod")
-		returnErr = err
-		return
+		msg := fmt.Sprintf("Could not read logs of the failed runner pod %s", pod.Name)
+		log.Error(err, msg)
+		return fmt.Sprintf("%s: %v", msg, err)
 	}
 
-	if returnErr = json.Unmarshal(buf.Bytes(), &inspectOutput); returnErr != nil {
-		// this shouldn't normally happen but if it does, let's log output by default
-		log.Error(returnErr, fmt.Sprintf("unable to marshal: `%s`", buf.String()))
+	return fmt.Sprintf("%s:\n%s", pod.Name, buf.String())
+}
+
+// checkExtensions verifies that all required extensions are listed
+// in the output of `k6 version --json`. An extension is listed by its
+// Go module, with the JavaScript modules it can be imported as and the
+// outputs it provides, any of which can be required.
+func checkExtensions(versionOutput []byte, required []string) error {
+	var version struct {
+		Extensions []struct {
+			Module  string   `json:"module"`
+			Imports []string `json:"imports"`
+			Outputs []string `json:"outputs"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(versionOutput, &version); err != nil {
+		return fmt.Errorf("unable to parse extensions of the runner image `%s`: %w", versionOutput, err)
 	}
 
-	ready = true
-	return
+	var missing []string
+	for _, extension := range required {
+		found := false
+		for _, e := range version.Extensions {
+			if e.Module == extension || slices.Contains(e.Imports, extension) || slices.Contains(e.Outputs, extension) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, extension)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the runner image is missing required extensions: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// imageReferenceRegexp is a simplified grammar of image references:
+// [registry[:port]/]repository[:tag][@digest]
+var imageReferenceRegexp = regexp.MustCompile(
+	`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?` +
+		`(?:@[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-fA-F0-9]{32,})?$`)
+
+// validateImages checks the format of the images configured for the pods
+// of the test run, so that a typo is reported before any pod is created.
+// Whether the image can actually be pulled is known only once the pods are scheduled.
+func (r *TestRunReconciler) validateImages(k6 *v1alpha1.TestRun) error {
+	pods := []*v1alpha1.Pod{&k6.GetSpec().Runner.Pod, k6.GetSpec().Initializer}
+	if r.UseLegacyStarter {
+		pods = append(pods, &k6.GetSpec().Starter)
+	}
+
+	for _, pod := range pods {
+		if pod == nil || len(pod.Image) == 0 {
+			continue
+		}
+
+		if !imageReferenceRegexp.MatchString(pod.Image) {
+			return fmt.Errorf("invalid image reference `%s`", pod.Image)
+		}
+	}
+
+	return nil
 }
 
 func getEnvVar(vars []corev1.EnvVar, name string) string {
@@ -126,44 +320,268 @@ func getEnvVar(vars []corev1.EnvVar, name string) string {
 	return ""
 }
 
-func (r *TestRunReconciler) hostnames(ctx context.Context, log logr.Logger, abortOnUnready bool, opts *client.ListOptions) ([]string, error) {
+// newTokenInfo returns the source of the token for Grafana Cloud k6,
+// as configured in the test run.
+func newTokenInfo(k6 *v1alpha1.TestRun) *cloud.TokenInfo {
+	tokenInfo := cloud.NewTokenInfo(k6.GetSpec().Token, k6.NamespacedName().Namespace)
+
+	if from := k6.GetSpec().TokenFrom; from != nil {
+		if from.SecretKeyRef != nil {
+			return tokenInfo.WithSecretKey(from.SecretKeyRef.Name, from.SecretKeyRef.Key)
+		}
+	}
+
+	return tokenInfo
+}
+
+// failOnToken is called when the token for Grafana Cloud k6 cannot be loaded
+// because of the configuration: it moves the test run to the error stage.
+func failOnToken(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, err error) (ctrl.Result, error) {
+	log.Error(err, "A problem while getting token.")
+
+	if stage := k6.GetStatus().Stage; stage == "finished" || stage == "error" {
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("Changing stage of TestRun status to error")
+	k6.GetStatus().Stage = "error"
+	k6.GetStatus().Error = err.Error()
+
+	_, err = r.UpdateStatus(ctx, k6, log)
+	return ctrl.Result{}, err
+}
+
+// cloudHost returns the host of Grafana Cloud k6 API for the test run,
+// or an empty string for the default one.
+func cloudHost(k6 *v1alpha1.TestRun) string {
+	if len(k6.GetSpec().CloudHost) > 0 {
+		return strings.TrimSuffix(k6.GetSpec().CloudHost, "/")
+	}
+	return getEnvVar(k6.GetSpec().Runner.Env, "K6_CLOUD_HOST")
+}
+
+// validateCloudHost checks that the host, if set, is an absolute HTTP(S) URL.
+func validateCloudHost(host string) error {
+	if len(host) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("cloud host `%s` is invalid: %w", host, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("cloud host `%s` is invalid: an absolute http or https URL is expected", host)
+	}
+	return nil
+}
+
+// recordEvent records an event of the test run, if the recorder is configured.
+func (r *TestRunReconciler) recordEvent(k6 *v1alpha1.TestRun, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	if len(k6.TestRunID()) > 0 {
+		message = fmt.Sprintf("%s (test run ID %s)", message, k6.TestRunID())
+	}
+	r.Recorder.Event(k6, eventType, reason, message)
+}
+
+// sendCloudEvents sends the events of the test run to k6 Cloud, except for
+// the terminal ones already sent: failure paths are reached again on requeue
+// and the test run must not be aborted twice. Terminal events are recorded
+// in the status once they're delivered, so failed ones are sent again on
+// requeue; errors are only logged.
+func (r *TestRunReconciler) sendCloudEvents(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, events *cloud.Events) {
 	var (
-		hostnames []string
-		err       error
+		pending cloud.Events
+		sent    = k6.GetStatus().SentCloudEvents
 	)
+	for _, e := range *events {
+		if e.IsTerminal() && slices.Contains(sent, e.Key()) {
+			log.Info(fmt.Sprintf("Event %s was already sent to k6 Cloud, skipping it", e.Key()))
+			continue
+		}
+		pending = append(pending, e)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := cloud.SendTestRunEvents(r.cloudClient(k6), k6.TestRunID(), log, &pending); err != nil {
+		return
+	}
+
+	for _, e := range pending {
+		if e.IsTerminal() && !slices.Contains(sent, e.Key()) {
+			sent = append(sent, e.Key())
+		}
+	}
+	if len(sent) == len(k6.GetStatus().SentCloudEvents) {
+		return
+	}
+	k6.GetStatus().SentCloudEvents = sent
+	if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		log.Error(err, "Could not record the events sent to k6 Cloud")
+	}
+}
+
+// runnerTarget is a runner Job and the address the operator reaches it at.
+type runnerTarget struct {
+	runner   string
+	hostname string
+}
+
+// runnerTargets returns the runners behind the service with their addresses:
+// its ClusterIP or, for the headless service, the DNS names of all runner pods.
+func runnerTargets(k6 *v1alpha1.TestRun, service *corev1.Service) []runnerTarget {
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		runner := service.Spec.Selector["job-name"]
+		if index, err := strconv.Atoi(service.Spec.Selector[batchv1.JobCompletionIndexAnnotation]); err == nil {
+			// the service of a pod of the Indexed Job
+			runner = fmt.Sprintf("%s-%d", k6.NamespacedName().Name, index+1)
+		}
+		return []runnerTarget{{runner: runner, hostname: service.Spec.ClusterIP}}
+	}
+
+	targets := make([]runnerTarget, 0, k6.GetSpec().Parallelism)
+	for i := 1; i <= int(k6.GetSpec().Parallelism); i++ {
+		name := fmt.Sprintf("%s-%d", k6.NamespacedName().Name, i)
+		if k6.IsMissingRunner(name) {
+			continue
+		}
+		targets = append(targets, runnerTarget{runner: name, hostname: fmt.Sprintf("%s.%s.%s.svc", name, service.Name, service.Namespace)})
+	}
+	return targets
+}
+
+// listRunners lists the runner objects of the test run into list, leaving
+// out the objects with the same name labels which belong to another TestRun.
+func (r *TestRunReconciler) listRunners(ctx context.Context, k6 *v1alpha1.TestRun, list client.ObjectList) error {
+	if err := r.List(ctx, list, k6.ListOptions()); err != nil {
+		return err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	runners := items[:0]
+	for _, item := range items {
+		if obj, ok := item.(client.Object); ok && k6.IsRunnerOf(obj) {
+			runners = append(runners, item)
+		}
+	}
+	return meta.SetList(list, runners)
+}
+
+// listRunnerTargets returns all runners with their addresses: behind their
+// services or, with RunnerPodIPs, at the IPs of their running pods.
+func (r *TestRunReconciler) listRunnerTargets(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun) ([]runnerTarget, error) {
+	if !k6.GetSpec().RunnerPodIPs {
+		sl := &corev1.ServiceList{}
+		if err := r.listRunners(ctx, k6, sl); err != nil {
+			return nil, err
+		}
 
-	sl := &corev1.ServiceList{}
+		var targets []runnerTarget
+		for _, service := range sl.Items {
+			log.Info(fmt.Sprintf("Checking service %s", service.Name))
+			targets = append(targets, runnerTargets(k6, &service)...)
+		}
+		return targets, nil
+	}
 
-	if err = r.List(ctx, sl, opts); err != nil {
-		log.Error(err, "Could not list services")
+	pl := &corev1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
 		return nil, err
 	}
 
-	for _, service := range sl.Items {
-		log.Info(fmt.Sprintf("Checking service %s", service.Name))
-		if isServiceReady(log, &service) {
-			log.Info(fmt.Sprintf("%v service is ready", service.Name))
-			hostnames = append(hostnames, service.Spec.ClusterIP)
-		} else {
-			err = fmt.Errorf("%v service is not ready", service.Name)
-			log.Info(err.Error())
-			if abortOnUnready {
-				return nil, err
-			}
+	targets := make([]runnerTarget, 0, len(pl.Items))
+	for _, pod := range pl.Items {
+		runner := podRunner(&pod)
+		if pod.Status.Phase != corev1.PodRunning || len(pod.Status.PodIP) == 0 ||
+			!pod.DeletionTimestamp.IsZero() || k6.IsMissingRunner(runner) {
+			continue
 		}
+		targets = append(targets, runnerTarget{runner: runner, hostname: pod.Status.PodIP})
+	}
+	return targets, nil
+}
+
+// runnerTargetsKind returns the kind of objects listed by listRunnerTargets.
+func runnerTargetsKind(k6 *v1alpha1.TestRun) string {
+	if k6.GetSpec().RunnerPodIPs {
+		return "pods"
+	}
+	return "services"
+}
+
+// hostnames returns the addresses of the runners which respond to status requests.
+func (r *TestRunReconciler) hostnames(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun) ([]string, error) {
+	targets, err := r.listRunnerTargets(ctx, log, k6)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Could not list %s", runnerTargetsKind(k6)))
+		return nil, err
 	}
 
+	hostnames, _, _ := respondingRunners(ctx, log, testrun.RunnerClient(), targets)
 	return hostnames, nil
 }
 
+// runnerPodIPs returns the addresses of the running runner pods,
+// for runners which don't have a Service.
+func (r *TestRunReconciler) runnerPodIPs(ctx context.Context, k6 *v1alpha1.TestRun) ([]string, error) {
+	pl := &corev1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	for _, pod := range pl.Items {
+		if pod.Status.Phase == corev1.PodRunning && len(pod.Status.PodIP) > 0 {
+			ips = append(ips, pod.Status.PodIP)
+		}
+	}
+	return ips, nil
+}
+
+// defaultScriptTimeout is the default setupTimeout and teardownTimeout of k6.
+const defaultScriptTimeout = time.Minute
+
+// scriptTimeouts returns how long setup() and teardown() of the test run
+// may take when they're executed by the operator: setupTimeout and
+// teardownTimeout of the script, with the timeout of runner requests
+// on top for the request itself.
+func scriptTimeouts(k6 *v1alpha1.TestRun) (setup, teardown time.Duration) {
+	setup, teardown = defaultScriptTimeout, defaultScriptTimeout
+
+	var inspectOutput cloud.InspectOutput
+	if err := json.Unmarshal([]byte(k6.GetStatus().Inspection), &inspectOutput); err == nil {
+		if inspectOutput.SetupTimeout.Valid {
+			setup = inspectOutput.SetupTimeout.TimeDuration()
+		}
+		if inspectOutput.TeardownTimeout.Valid {
+			teardown = inspectOutput.TeardownTimeout.TimeDuration()
+		}
+	}
+
+	margin := testrun.RunnerClient().Timeout
+	return setup + margin, teardown + margin
+}
+
 // runSetup returns an outcome of HTTP calls, as well as
 // a retry bool showing whether operation should be retried
 // despite the error.
 // (for example, if there was a networking glitch).
-func runSetup(ctx context.Context, hostnames []string, log logr.Logger) (error, bool) {
+func runSetup(ctx context.Context, k6 *v1alpha1.TestRun, hostnames []string, log logr.Logger) (error, bool) {
 	log.Info("Invoking setup() on the first runner")
 
-	setupData, err := testrun.RunSetup(ctx, hostnames[0])
+	timeout, _ := scriptTimeouts(k6)
+	setupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	setupData, err := testrun.RunSetup(setupCtx, hostnames[0])
 	if err != nil {
 		// Is there a better way to get this error? Where is NDE...
 		if strings.Contains(err.Error(), "Error executing") {
@@ -185,10 +603,220 @@ func runSetup(ctx context.Context, hostnames []string, log logr.Logger) (error,
 	return nil, false
 }
 
-func runTeardown(ctx context.Context, hostnames []string, log logr.Logger) {
+// deleteOutputVolumes removes the claims of output volume of the test run.
+// Errors are only logged as they shouldn't block the cleanup of the test run itself.
+func (r *TestRunReconciler) deleteOutputVolumes(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun) {
+	for _, name := range k6.GetSpec().OutputVolume.ClaimNames(int(k6.GetSpec().Parallelism)) {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: k6.NamespacedName().Namespace,
+			},
+		}
+
+		if err := r.Delete(ctx, pvc); err != nil && !k8sErrors.IsNotFound(err) {
+			log.Error(err, fmt.Sprintf("Failed to delete output volume claim %s", name))
+			continue
+		}
+		log.Info(fmt.Sprintf("Deleted output volume claim %s", name))
+	}
+}
+
+func (r *TestRunReconciler) deleteDisruptionBudget(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun) {
+	pdb := jobs.NewRunnerPodDisruptionBudget(k6)
+
+	if err := r.Delete(ctx, pdb); err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			log.Error(err, fmt.Sprintf("Failed to delete pod disruption budget %s", pdb.Name))
+		}
+		return
+	}
+	log.Info(fmt.Sprintf("Deleted pod disruption budget %s", pdb.Name))
+}
+
+func runTeardown(ctx context.Context, k6 *v1alpha1.TestRun, hostnames []string, log logr.Logger) {
 	log.Info("Invoking teardown() on the first responsive runner")
 
+	_, timeout := scriptTimeouts(k6)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	if err := testrun.RunTeardown(ctx, hostnames); err != nil {
 		log.Error(err, "Failed to invoke teardown()")
 	}
 }
+
+// checkPrecondition returns the reason to wait if the precondition probe
+// of the test run doesn't pass yet, or an empty string otherwise.
+func (r *TestRunReconciler) checkPrecondition(ctx context.Context, k6 *v1alpha1.TestRun) string {
+	probe := k6.GetSpec().PreconditionProbe
+
+	if len(probe.Deployment) > 0 {
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: k6.NamespacedName().Namespace, Name: probe.Deployment}, deployment); err != nil {
+			return fmt.Sprintf("cannot get deployment %s: %v", probe.Deployment, err)
+		}
+
+		available := false
+		for _, cond := range deployment.Status.Conditions {
+			if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
+				available = true
+			}
+		}
+		if !available {
+			return fmt.Sprintf("deployment %s is not available", probe.Deployment)
+		}
+	}
+
+	if len(probe.URL) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.URL, nil)
+		if err != nil {
+			return fmt.Sprintf("invalid URL %s: %v", probe.URL, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Sprintf("%s is not reachable: %v", probe.URL, err)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return fmt.Sprintf("%s responded with %d", probe.URL, resp.StatusCode)
+		}
+	}
+
+	return ""
+}
+
+// validateServiceAccounts checks that the service accounts configured for the pods
+// of the test run exist, so that the pods don't get stuck in creation.
+// It returns an error of NotFound type if one of them is missing.
+func (r *TestRunReconciler) validateServiceAccounts(ctx context.Context, k6 *v1alpha1.TestRun) error {
+	pods := []*v1alpha1.Pod{&k6.GetSpec().Runner.Pod, k6.GetSpec().Initializer}
+	if r.UseLegacyStarter {
+		pods = append(pods, &k6.GetSpec().Starter)
+	}
+
+	for _, pod := range pods {
+		if pod == nil || len(pod.ServiceAccountName) == 0 {
+			continue
+		}
+
+		sa := &corev1.ServiceAccount{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: k6.Namespace, Name: pod.ServiceAccountName}, sa); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRuntimeClass checks that the RuntimeClass of runners exists, since
+// runner pods referring to a missing one are never scheduled.
+// It returns an error of NotFound type if it's missing.
+func (r *TestRunReconciler) validateRuntimeClass(ctx context.Context, k6 *v1alpha1.TestRun) error {
+	name := k6.GetSpec().Runner.RuntimeClassName
+	if name == nil || len(*name) == 0 {
+		return nil
+	}
+
+	return r.Get(ctx, client.ObjectKey{Name: *name}, &nodev1.RuntimeClass{})
+}
+
+// apiReader returns the reader of objects which aren't watched by the operator.
+func (r *TestRunReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// errInvalidThresholds means that the thresholds of ThresholdsOverride cannot be parsed.
+var errInvalidThresholds = errors.New("invalid thresholds override")
+
+// validateThresholdsOverride checks that the ConfigMap of ThresholdsOverride
+// has thresholds which k6 can parse, so that a typo doesn't fail every runner.
+func (r *TestRunReconciler) validateThresholdsOverride(ctx context.Context, k6 *v1alpha1.TestRun) error {
+	override := k6.GetSpec().ThresholdsOverride
+	if override == nil {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.apiReader().Get(ctx, client.ObjectKey{Namespace: k6.Namespace, Name: override.ConfigMap.Name}, cm); err != nil {
+		return err
+	}
+	data, ok := cm.Data[override.File()]
+	if !ok {
+		return fmt.Errorf("%w: ConfigMap %s has no key %s", errInvalidThresholds, cm.Name, override.File())
+	}
+
+	var thresholds map[string]metrics.Thresholds
+	if err := json.Unmarshal([]byte(data), &thresholds); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidThresholds, err)
+	}
+	for name, ts := range thresholds {
+		if _, _, err := metrics.ParseMetricName(name); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidThresholds, err)
+		}
+		if err := ts.Parse(); err != nil {
+			return fmt.Errorf("%w: thresholds of %s: %w", errInvalidThresholds, name, err)
+		}
+	}
+	return nil
+}
+
+// listFailureTimeout is how long listing of the runner objects can keep
+// failing before it's reported in the status of the test run.
+const listFailureTimeout = time.Minute
+
+const errListFailedHint = "Cannot list %s of the test run: %v. " +
+	"Check that the operator is allowed to list them in the namespace (RBAC) and that its cache is synced."
+
+// isPermanentListError shows whether err won't go away by retrying the List.
+func isPermanentListError(err error) bool {
+	var notCached *cache.ErrResourceNotCached
+	return k8sErrors.IsForbidden(err) ||
+		k8sErrors.IsUnauthorized(err) ||
+		meta.IsNoMatchError(err) ||
+		errors.As(err, &notCached)
+}
+
+// listFailed records a failed List of the runner objects of k6, which is
+// retried by the caller. Errors which won't go away by retrying and errors
+// lasting longer than listFailureTimeout are reported with RunnersListFailed
+// condition, so that the test run isn't stuck without a visible cause.
+func (r *TestRunReconciler) listFailed(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, kind string, err error) {
+	first, _ := r.listFailures.LoadOrStore(k6.NamespacedName(), time.Now())
+	if !isPermanentListError(err) && time.Since(first.(time.Time)) < listFailureTimeout {
+		return
+	}
+
+	msg := fmt.Sprintf(errListFailedHint, kind, err)
+	if v1alpha1.IsTrue(k6, v1alpha1.RunnersListFailed) && k6.GetStatus().Waiting == msg {
+		return
+	}
+
+	log.Info(msg)
+	k6.GetStatus().Waiting = msg
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnersListFailed, metav1.ConditionTrue)
+
+	if updated, _ := r.UpdateStatus(ctx, k6, log); updated {
+		r.recordEvent(k6, corev1.EventTypeWarning, "ListFailed", msg)
+	}
+}
+
+// listSucceeded clears the List failures of k6 recorded by listFailed.
+func (r *TestRunReconciler) listSucceeded(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun) {
+	r.listFailures.Delete(k6.NamespacedName())
+	if !v1alpha1.IsTrue(k6, v1alpha1.RunnersListFailed) {
+		return
+	}
+
+	log.Info("Listing of runners has recovered")
+	k6.GetStatus().Waiting = ""
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnersListFailed, metav1.ConditionFalse)
+	_, _ = r.UpdateStatus(ctx, k6, log)
+}
@@ -0,0 +1,525 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
+	"github.com/grafana/k6-operator/pkg/testrun"
+	"github.com/grafana/k6-operator/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_validateImages(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		image   string
+		isValid bool
+	}{
+		{"grafana/k6", true},
+		{"grafana/k6:1.5.0", true},
+		{"ghcr.io/grafana/k6-operator:latest-starter", true},
+		{"localhost:5000/xk6/k6-faker:v0.4.0", true},
+		{"registry.example.com/k6@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"grafana/K6", false},
+		{"grafana/k6:", false},
+		{"grafana//k6", false},
+		{"grafana/k6 :latest", false},
+		{"https://ghcr.io/grafana/k6", false},
+	}
+
+	r := &TestRunReconciler{}
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			Spec: v1alpha1.TestRunSpec{
+				Runner: v1alpha1.RunnerPod{Pod: v1alpha1.Pod{Image: tc.image}},
+			},
+		}
+
+		err := r.validateImages(k6)
+		if tc.isValid && err != nil {
+			t.Errorf("image `%s`: unexpected error: %v", tc.image, err)
+		}
+		if !tc.isValid && err == nil {
+			t.Errorf("image `%s`: expected an error", tc.image)
+		}
+	}
+}
+
+func Test_checkExtensions(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(`{"version":"v1.5.0","extensions":[` +
+		`{"module":"github.com/grafana/xk6-faker","version":"v0.4.0","imports":["k6/x/faker"]},` +
+		`{"module":"github.com/grafana/xk6-output-influxdb","version":"v0.5.0","outputs":["xk6-influxdb"]}]}`)
+
+	if err := checkExtensions(output, []string{"k6/x/faker", "github.com/grafana/xk6-faker", "xk6-influxdb"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkExtensions(output, []string{"k6/x/faker", "k6/x/sql"}); err == nil {
+		t.Error("expected an error for the missing extension")
+	}
+	if err := checkExtensions([]byte("unknown flag: --json"), []string{"k6/x/faker"}); err == nil {
+		t.Error("expected an error for the unparsable output")
+	}
+}
+
+func Test_checkPrecondition(t *testing.T) {
+	t.Parallel()
+
+	var targetReady atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !targetReady.Load() {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "target", Namespace: "default"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+		},
+	}
+	r := &TestRunReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build()}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha1.TestRunSpec{
+			PreconditionProbe: &v1alpha1.K6PreconditionProbe{
+				URL:        srv.URL,
+				Deployment: "target",
+			},
+		},
+	}
+
+	if reason := r.checkPrecondition(context.Background(), k6); len(reason) == 0 {
+		t.Error("expected to wait for the URL")
+	}
+
+	targetReady.Store(true)
+	if reason := r.checkPrecondition(context.Background(), k6); len(reason) > 0 {
+		t.Errorf("expected the precondition to pass, got: %s", reason)
+	}
+
+	k6.Spec.PreconditionProbe.Deployment = "missing"
+	if reason := r.checkPrecondition(context.Background(), k6); len(reason) == 0 {
+		t.Error("expected to wait for the missing deployment")
+	}
+}
+
+func Test_validateRuntimeClass(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	gvisor := &nodev1.RuntimeClass{ObjectMeta: metav1.ObjectMeta{Name: "gvisor"}, Handler: "runsc"}
+	r := &TestRunReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(gvisor).Build()}
+
+	k6 := &v1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	if err := r.validateRuntimeClass(context.Background(), k6); err != nil {
+		t.Errorf("unexpected error without runtime class: %v", err)
+	}
+
+	k6.Spec.Runner.RuntimeClassName = &gvisor.Name
+	if err := r.validateRuntimeClass(context.Background(), k6); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	missing := "kata"
+	k6.Spec.Runner.RuntimeClassName = &missing
+	if err := r.validateRuntimeClass(context.Background(), k6); !k8sErrors.IsNotFound(err) {
+		t.Errorf("expected NotFound error for the missing runtime class, got: %v", err)
+	}
+}
+
+func Test_validateThresholdsOverride(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "thresholds", Namespace: "default"},
+		Data: map[string]string{
+			"thresholds.json": `{"http_req_duration{scenario:api}": ["p(95)<500"], "checks": [{"threshold": "rate>0.99", "abortOnFail": true}]}`,
+			"typo.json":       `{"http_req_duration": ["p95<500"]}`,
+			"broken.json":     `{"http_req_duration": "p(95)<500"}`,
+		},
+	}
+	// the ConfigMap isn't cached: it's read from the API server
+	r := &TestRunReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme).Build(),
+		APIReader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build(),
+	}
+
+	testCases := []struct {
+		name    string
+		cm      v1alpha1.K6Configmap
+		invalid bool
+	}{
+		{"valid thresholds", v1alpha1.K6Configmap{Name: "thresholds"}, false},
+		{"invalid expression", v1alpha1.K6Configmap{Name: "thresholds", File: "typo.json"}, true},
+		{"invalid format", v1alpha1.K6Configmap{Name: "thresholds", File: "broken.json"}, true},
+		{"missing key", v1alpha1.K6Configmap{Name: "thresholds", File: "missing.json"}, true},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       v1alpha1.TestRunSpec{ThresholdsOverride: &v1alpha1.K6ThresholdsOverride{ConfigMap: tc.cm}},
+		}
+		err := r.validateThresholdsOverride(context.Background(), k6)
+		if tc.invalid && !errors.Is(err, errInvalidThresholds) {
+			t.Errorf("%s: expected invalid thresholds, got: %v", tc.name, err)
+		}
+		if !tc.invalid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha1.TestRunSpec{ThresholdsOverride: &v1alpha1.K6ThresholdsOverride{
+			ConfigMap: v1alpha1.K6Configmap{Name: "missing"},
+		}},
+	}
+	if err := r.validateThresholdsOverride(context.Background(), k6); !k8sErrors.IsNotFound(err) {
+		t.Errorf("expected NotFound error for the missing ConfigMap, got: %v", err)
+	}
+}
+
+func Test_validateCloudHost(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		host    string
+		isValid bool
+	}{
+		{"", true},
+		{"https://ingest.k6.io", true},
+		{"http://k6-cloud.internal:8080/", true},
+		{"ingest.k6.io", false},
+		{"ftp://ingest.k6.io", false},
+		{"https://", false},
+		{"https://ingest k6.io", false},
+	}
+
+	for _, tc := range testCases {
+		err := validateCloudHost(tc.host)
+		if tc.isValid && err != nil {
+			t.Errorf("host `%s`: unexpected error: %v", tc.host, err)
+		}
+		if !tc.isValid && err == nil {
+			t.Errorf("host `%s`: expected an error", tc.host)
+		}
+	}
+}
+
+func Test_scriptFetchError(t *testing.T) {
+	t.Parallel()
+
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: types.ScriptFetchContainerName,
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 22,
+						Message:  "curl: (22) The requested URL returned error: 404\n",
+					},
+				},
+			}},
+		},
+	}
+
+	msg, failed := scriptFetchError(pod)
+	if !failed || msg != "curl: (22) The requested URL returned error: 404" {
+		t.Errorf("expected the fetch to fail with the curl message, got %v and %q", failed, msg)
+	}
+
+	pod.Status.InitContainerStatuses[0].State.Terminated.ExitCode = 0
+	if _, failed := scriptFetchError(pod); failed {
+		t.Error("expected the fetch to succeed")
+	}
+}
+
+func Test_listFailed(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status:     v1alpha1.TestRunStatus{Stage: "created"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	timeout := k8sErrors.NewTimeoutError("list pods", 1)
+	r.listFailed(ctx, logr.Discard(), k6, "pods", timeout)
+	if v1alpha1.IsTrue(k6, v1alpha1.RunnersListFailed) || len(k6.Status.Waiting) > 0 {
+		t.Error("expected a single transient failure not to be reported")
+	}
+
+	// the failure has lasted for a while
+	r.listFailures.Store(k6.NamespacedName(), time.Now().Add(-2*listFailureTimeout))
+	r.listFailed(ctx, logr.Discard(), k6, "pods", timeout)
+	if !v1alpha1.IsTrue(k6, v1alpha1.RunnersListFailed) || !strings.Contains(k6.Status.Waiting, "Cannot list pods") {
+		t.Errorf("expected the lasting failure to be reported, got waiting %q", k6.Status.Waiting)
+	}
+
+	r.listSucceeded(ctx, logr.Discard(), k6)
+	if !v1alpha1.IsFalse(k6, v1alpha1.RunnersListFailed) || len(k6.Status.Waiting) > 0 {
+		t.Errorf("expected the failure to be cleared, got waiting %q", k6.Status.Waiting)
+	}
+
+	// missing permissions are reported right away
+	forbidden := k8sErrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC denied"))
+	r.listFailed(ctx, logr.Discard(), k6, "pods", forbidden)
+	if !v1alpha1.IsTrue(k6, v1alpha1.RunnersListFailed) {
+		t.Error("expected the forbidden List to be reported")
+	}
+}
+
+func Test_listRunnerTargets(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+	pod := func(job string, phase corev1.PodPhase, ip string) *corev1.Pod {
+		labels := maps.Clone(runnerLabels)
+		labels["job-name"] = job
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: job + "-pod", Namespace: "default", Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+		}
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-service-1", Namespace: "default", Labels: runnerLabels},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.1", Selector: map[string]string{"job-name": "test-1"}},
+	}
+	objects := []client.Object{
+		service,
+		pod("test-1", corev1.PodRunning, "10.0.0.1"),
+		pod("test-2", corev1.PodPending, ""),
+		pod("test-3", corev1.PodRunning, "10.0.0.3"),
+	}
+
+	testCases := []struct {
+		name         string
+		runnerPodIPs bool
+		expected     []runnerTarget
+	}{
+		{"services", false, []runnerTarget{{runner: "test-1", hostname: "10.96.0.1"}}},
+		{"pod IPs", true, []runnerTarget{{runner: "test-1", hostname: "10.0.0.1"}}},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+			Spec:       v1alpha1.TestRunSpec{Parallelism: 3, RunnerPodIPs: tc.runnerPodIPs},
+			// the last runner was left out of the test
+			Status: v1alpha1.TestRunStatus{MissingRunners: []string{"test-3"}},
+		}
+		r := &TestRunReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), Scheme: scheme}
+
+		targets, err := r.listRunnerTargets(context.Background(), logr.Discard(), k6)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(targets, tc.expected) {
+			t.Errorf("%s: expected targets %v, got %v", tc.name, tc.expected, targets)
+		}
+	}
+}
+
+func Test_sendCloudEvents(t *testing.T) {
+	t.Parallel()
+
+	var requests, aborts, errs atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/orchestrator/v1/testruns/123/events" {
+			t.Errorf("unexpected request to %s", req.URL.Path)
+			return
+		}
+		if requests.Add(1) == 1 {
+			// the first delivery fails: the events must be sent again
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var events cloud.Events
+		_ = json.NewDecoder(req.Body).Decode(&events)
+		for _, e := range events {
+			switch e.EventType {
+			case cloud.AbortEvent(cloud.OriginUser).EventType:
+				aborts.Add(1)
+			default:
+				errs.Add(1)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	// the initializer has been stuck for too long: the test run is aborted
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 1, CloudHost: srv.URL, Token: "credentials"},
+		Status: v1alpha1.TestRunStatus{
+			Stage:     "initialization",
+			TestRunID: "123",
+			Conditions: []metav1.Condition{
+				{Type: v1alpha1.CloudTestRun, Status: metav1.ConditionTrue, LastTransitionTime: metav1.Now()},
+				{Type: v1alpha1.TestRunRunning, Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(time.Now().Add(-10 * time.Minute))},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, secret).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+	for i := 0; i < 3; i++ {
+		current := &v1alpha1.TestRun{}
+		if err := c.Get(context.Background(), k6.NamespacedName(), current); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := r.reconcile(context.Background(), ctrl.Request{}, logr.Discard(), current); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if aborts.Load() != 1 || errs.Load() != 1 {
+		t.Errorf("expected events to be sent once, got %d abort and %d error events", aborts.Load(), errs.Load())
+	}
+
+	stored := &v1alpha1.TestRun{}
+	if err := c.Get(context.Background(), k6.NamespacedName(), stored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stored.Status.SentCloudEvents) != 2 {
+		t.Errorf("expected sent events to be recorded, got %v", stored.Status.SentCloudEvents)
+	}
+
+	// another error is sent, but the test run is not aborted again
+	events := cloud.ErrorEvent(cloud.K6OperatorRunnerError).
+		WithDetail("another error").
+		WithAbort()
+	r.sendCloudEvents(context.Background(), logr.Discard(), stored, events)
+	if aborts.Load() != 1 || errs.Load() != 2 {
+		t.Errorf("expected only the new error event to be sent, got %d abort and %d error events", aborts.Load(), errs.Load())
+	}
+	if len(stored.Status.SentCloudEvents) != 3 {
+		t.Errorf("expected the new error event to be recorded, got %v", stored.Status.SentCloudEvents)
+	}
+}
+
+func Test_scriptTimeouts(t *testing.T) {
+	t.Parallel()
+
+	margin := testrun.RunnerClient().Timeout
+	testCases := []struct {
+		name       string
+		inspection string
+		setup      time.Duration
+		teardown   time.Duration
+	}{
+		{"no inspection", "", time.Minute + margin, time.Minute + margin},
+		{"default timeouts", `{"maxVUs":10}`, time.Minute + margin, time.Minute + margin},
+		{"script timeouts", `{"setupTimeout":"10m","teardownTimeout":"30s"}`, 10*time.Minute + margin, 30*time.Second + margin},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{Status: v1alpha1.TestRunStatus{Inspection: tc.inspection}}
+		if setup, teardown := scriptTimeouts(k6); setup != tc.setup || teardown != tc.teardown {
+			t.Errorf("%s: expected timeouts %v and %v, got %v and %v", tc.name, tc.setup, tc.teardown, setup, teardown)
+		}
+	}
+}
+
+func Test_createClient(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	secret := func(name, token string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte(token)},
+		}
+	}
+	testRun := func(name, token, host string) *v1alpha1.TestRun {
+		return &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1alpha1.TestRunSpec{Token: token, CloudHost: host},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret("token-a", "a"), secret("token-b", "b")).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	a, b := testRun("a", "token-a", "https://a.example.com"), testRun("b", "token-b", "https://b.example.com")
+	for _, k6 := range []*v1alpha1.TestRun{a, b} {
+		if found, err := r.createClient(ctx, k6, logr.Discard()); err != nil || !found {
+			t.Fatalf("expected the client of %s, got %v and error: %v", k6.Name, found, err)
+		}
+	}
+	if r.cloudClient(a) == r.cloudClient(b) {
+		t.Fatal("expected test runs to have their own clients")
+	}
+	if base := r.cloudClient(a).BaseURL(); base != "https://a.example.com/v1" {
+		t.Errorf("expected the client of test run a to use its host, got %s", base)
+	}
+
+	// the client is kept until the token changes
+	client := r.cloudClient(a)
+	if _, err := r.createClient(ctx, a, logr.Discard()); err != nil || r.cloudClient(a) != client {
+		t.Errorf("expected the client to be reused, error: %v", err)
+	}
+	a.Spec.Token = "token-b"
+	if _, err := r.createClient(ctx, a, logr.Discard()); err != nil || r.cloudClient(a) == client {
+		t.Errorf("expected a new client with the new token, error: %v", err)
+	}
+}
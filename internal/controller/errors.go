@@ -0,0 +1,33 @@
+package controllers
+
+import "errors"
+
+// Errors of the creation and the start of runners. They are wrapped with
+// the details of the failure and can be told apart with errors.Is: the
+// failures which cannot be fixed by a retry move the test run to the error stage.
+var (
+	// ErrPreviousRunExists means that a runner object cannot be created because
+	// an object with the same name, usually of a previous run, already exists.
+	ErrPreviousRunExists = errors.New("object of a previous run exists")
+
+	// ErrControllerReference means that the test run cannot be set as the
+	// controller of a runner object, e.g. its kind isn't registered in the
+	// scheme of the operator.
+	ErrControllerReference = errors.New("cannot set controller reference")
+
+	// ErrRunnerDeleting means that a runner object of the test run is being
+	// deleted, e.g. a replaced runner job, so it cannot be reused. The creation
+	// of runners is resumed once it's gone.
+	ErrRunnerDeleting = errors.New("runner object is being deleted")
+
+	// ErrRunnerNotReady means that the REST API of a runner doesn't respond yet.
+	ErrRunnerNotReady = errors.New("runner is not ready")
+
+	// ErrRunnerStartTimeout means that the runners were not ready
+	// or didn't confirm the start in time.
+	ErrRunnerStartTimeout = errors.New("runners did not start in time")
+
+	// ErrPartialStart means that the test was started only on some of the runners.
+	// The started runners cannot be paused again, so the start cannot be retried.
+	ErrPartialStart = errors.New("test was started only on some runners")
+)
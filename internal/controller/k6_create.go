@@ -2,24 +2,40 @@ package controllers
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/cloud"
 	"github.com/grafana/k6-operator/pkg/resources/jobs"
+	"github.com/grafana/k6-operator/pkg/segmentation"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
 )
 
 // CreateJobs creates jobs that will spawn k6 pods for distributed test
 func CreateJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	if k6.GetStatus().Stage != "creating" {
+		log.Info("Changing stage of TestRun status to creating")
+		k6.GetStatus().Stage = "creating"
+		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// needed for cloud tests
-	tokenInfo := cloud.NewTokenInfo(k6.GetSpec().Token, k6.NamespacedName().Namespace)
+	tokenInfo := newTokenInfo(k6)
 
 	if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) && v1alpha1.IsTrue(k6, v1alpha1.CloudTestRunCreated) {
 		log = log.WithValues("testRunId", k6.GetStatus().TestRunID)
@@ -27,99 +43,421 @@ func CreateJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *T
 		err := tokenInfo.Load(ctx, log, r.Client)
 		if err != nil {
 			// An error here means a very likely mis-configuration of the token.
-			// TODO: update status to error to let a user know quicker
-			log.Error(err, "A problem while getting token.")
-			return ctrl.Result{}, nil
+			return failOnToken(ctx, log, k6, r, err)
 		}
 		if !tokenInfo.Ready {
-			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+			return ctrl.Result{RequeueAfter: r.requeue().Medium}, nil
+		}
+	}
+
+	err := k6.ValidateRunnerCommand()
+	if err == nil {
+		err = k6.ValidateRunnerRestartPolicy()
+	}
+	if err != nil {
+		log.Info(err.Error())
+		if isCloudTestRun(k6) {
+			events := cloud.ErrorEvent(cloud.K6OperatorStartError).
+				WithDetail(err.Error()).
+				WithAbort()
+			r.sendCloudEvents(ctx, log, k6, events)
 		}
+
+		k6.GetStatus().Error = err.Error()
+		return abortStart(ctx, log, k6, r)
+	}
+
+	if k6.GetStatus().RunnerResources == nil {
+		if resources, err := autoRunnerResources(k6); err != nil {
+			log.Error(err, "Failed to compute resources of runners")
+		} else if resources != nil {
+			log.Info(fmt.Sprintf("Computed resources of runners: requests %v, limits %v", resources.Requests, resources.Limits))
+			k6.GetStatus().RunnerResources = resources
+		}
+	}
+
+	if warning := idleRunnersWarning(k6); len(warning) > 0 {
+		log.Info(warning)
+		k6.GetStatus().Warning = warning
+	}
+
+	if k6.GetSpec().DryRun {
+		return dryRunJobs(ctx, log, k6, r, tokenInfo)
 	}
 
 	log.Info("Creating test jobs")
 
+	creationStart := time.Now()
 	if res, recheck, err := createJobSpecs(ctx, log, k6, r, tokenInfo); err != nil {
 		if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) {
 			events := cloud.ErrorEvent(cloud.K6OperatorStartError).
 				WithDetail(fmt.Sprintf("Failed to create runner jobs: %v", err)).
 				WithAbort()
-			cloud.SendTestRunEvents(r.k6CloudClient, k6.TestRunID(), log, events)
+			r.sendCloudEvents(ctx, log, k6, events)
 		}
 
+		if goerrors.Is(err, ErrPreviousRunExists) || goerrors.Is(err, ErrControllerReference) {
+			// it won't go away on its own
+			k6.GetStatus().Error = err.Error()
+			return abortStart(ctx, log, k6, r)
+		}
 		return res, err
 	} else if recheck {
 		return res, nil
 	}
+	runnerJobsCreationDuration.Observe(time.Since(creationStart).Seconds())
 
 	log.Info("Changing stage of TestRun status to created")
 	k6.GetStatus().Stage = "created"
+	k6.GetStatus().Parallelism = k6.GetSpec().Parallelism
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnerJobsCreated, metav1.ConditionTrue)
 
 	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
 		return ctrl.Result{}, err
 	} else if updateHappened {
+		r.recordEvent(k6, corev1.EventTypeNormal, "Created", fmt.Sprintf("Created %d runners", k6.GetSpec().Parallelism))
 		return ctrl.Result{Requeue: true}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
+// autoRunnerResources computes the resources of runners with AutoResources
+// from the VUs of the busiest runner. It returns nil if the runners have
+// resources set explicitly or if the VUs of the script are unknown.
+func autoRunnerResources(k6 *v1alpha1.TestRun) (*corev1.ResourceRequirements, error) {
+	auto := k6.GetSpec().AutoResources
+	resources := k6.GetSpec().Runner.Resources
+	if auto == nil || k6.GetStatus().MaxVUs == 0 || len(resources.Requests) > 0 || len(resources.Limits) > 0 {
+		return nil, nil
+	}
+	if len(k6.GetSpec().PerRunnerScripts) > 0 {
+		// the VUs of the other scripts are unknown
+		return nil, nil
+	}
+
+	runnerVUs, err := segmentation.RunnerVUs(k6.GetStatus().MaxVUs, int(k6.GetSpec().Parallelism))
+	if err != nil {
+		return nil, err
+	}
+
+	computed := auto.Resources(slices.Max(runnerVUs))
+	return &computed, nil
+}
+
+// idleRunnersWarning returns a warning if some runners would get no VUs
+// of the script, according to its execution requirements.
+func idleRunnersWarning(k6 *v1alpha1.TestRun) string {
+	if k6.GetStatus().MaxVUs == 0 || len(k6.GetSpec().PerRunnerScripts) > 0 {
+		// the script wasn't inspected or it isn't split between the runners
+		return ""
+	}
+
+	idle, err := segmentation.IdleRunners(k6.GetStatus().MaxVUs, int(k6.GetSpec().Parallelism))
+	if err != nil || len(idle) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d of %d runners get no VUs of the %d required by the script and will be idle: "+
+		"consider parallelism of at most %d", len(idle), k6.GetSpec().Parallelism, k6.GetStatus().MaxVUs, k6.GetStatus().MaxVUs)
+}
+
+// dryRunJobs renders runner jobs and services into the status without creating them
+// and stops the test run.
+func dryRunJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, tokenInfo *cloud.TokenInfo) (ctrl.Result, error) {
+	log.Info("Dry run: rendering test jobs without creating them")
+
+	manifests, err := renderJobSpecs(k6, r, tokenInfo)
+	if err != nil {
+		log.Error(err, "Failed to render test jobs")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Changing stage of TestRun status to stopped")
+	k6.GetStatus().Stage = "stopped"
+	k6.GetStatus().Manifests = manifests
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionFalse)
+
+	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		return ctrl.Result{}, err
+	} else if updateHappened {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// renderJobSpecs returns YAML of all runner resources, exactly as they'd be created.
+func renderJobSpecs(k6 *v1alpha1.TestRun, r *TestRunReconciler, tokenInfo *cloud.TokenInfo) (string, error) {
+	var (
+		manifests []string
+		objects   []client.Object
+	)
+
+	if k6.GetSpec().DisruptionBudget {
+		objects = append(objects, jobs.NewRunnerPodDisruptionBudget(k6))
+	}
+
+	if k6.GetSpec().HeadlessService {
+		objects = append(objects, jobs.NewRunnerHeadlessService(k6))
+	}
+
+	if k6.GetSpec().IndexedRunners() {
+		job, err := jobs.NewIndexedRunnerJob(k6, tokenInfo)
+		if err != nil {
+			return "", err
+		}
+		objects = append(objects, job)
+	}
+
+	for i := 1; i <= int(k6.GetSpec().Parallelism); i++ {
+		if !k6.GetSpec().IndexedRunners() {
+			job, err := jobs.NewRunnerJob(k6, i, tokenInfo)
+			if err != nil {
+				return "", err
+			}
+			objects = append(objects, job)
+		}
+
+		if k6.GetSpec().HeadlessService || k6.Standalone() || k6.GetSpec().RunnerPodIPs {
+			continue
+		}
+
+		service, err := jobs.NewRunnerService(k6, i)
+		if err != nil {
+			return "", err
+		}
+		objects = append(objects, service)
+	}
+
+	for _, obj := range objects {
+		if err := ctrl.SetControllerReference(k6, obj, r.Scheme); err != nil {
+			return "", err
+		}
+
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return "", err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", err
+		}
+		manifests = append(manifests, string(manifest))
+	}
+
+	return strings.Join(manifests, "---\n"), nil
+}
+
 func createJobSpecs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, tokenInfo *cloud.TokenInfo) (ctrl.Result, bool, error) {
 	found := &batchv1.Job{}
 	namespacedName := types.NamespacedName{
 		Name:      fmt.Sprintf("%s-1", k6.NamespacedName().Name),
 		Namespace: k6.NamespacedName().Namespace,
 	}
+	if k6.GetSpec().IndexedRunners() {
+		namespacedName.Name = jobs.IndexedRunnerJobName(k6)
+	}
 
-	if err := r.Get(ctx, namespacedName, found); err == nil || !errors.IsNotFound(err) {
-		if err == nil {
-			err = fmt.Errorf("job with the name %s exists; make sure you've deleted your previous run", namespacedName.Name)
-		}
+	err := r.Get(ctx, namespacedName, found)
+	if err == nil && metav1.IsControlledBy(found, k6) {
+		// The runners of this test run were partially created by
+		// an interrupted reconcile: resume their creation.
+		log.Info(fmt.Sprintf("Job %s was already created for this test run, resuming", namespacedName.Name))
+	} else if err == nil {
+		err = fmt.Errorf("%w: job %s; make sure you've deleted your previous run", ErrPreviousRunExists, namespacedName.Name)
 		log.Info(err.Error())
 
-		// is it possible to implement this delay with resourceVersion of the job?
+		if !previousRunLeaving(k6, found) {
+			// it won't go away on its own
+			return ctrl.Result{}, false, err
+		}
 
 		t, condUpdated := v1alpha1.LastUpdate(k6, v1alpha1.CloudTestRun)
 		// If condition is unknown then resource hasn't been updated with `k6 inspect` results.
-		// If it has been updated but very recently, wait a bit before throwing an error.
-		if v1alpha1.IsUnknown(k6, v1alpha1.CloudTestRun) || !condUpdated || time.Since(t).Seconds() <= 30 {
-			// try again before returning an error
-			return ctrl.Result{RequeueAfter: time.Second * 10}, true, nil
+		// Otherwise, the previous run is given some time to be deleted.
+		if v1alpha1.IsUnknown(k6, v1alpha1.CloudTestRun) || !condUpdated || time.Since(t) <= r.previousRunGracePeriod() {
+			log.Info(fmt.Sprintf("Job %s of the previous run is being deleted, checking again", namespacedName.Name))
+			return ctrl.Result{RequeueAfter: r.requeue().PreviousRun}, true, nil
 		}
 
 		return ctrl.Result{}, false, err
+	} else if !errors.IsNotFound(err) {
+		// try again: it's unknown whether there is a previous run
+		log.Error(err, fmt.Sprintf("Could not get job %s", namespacedName.Name))
+		return ctrl.Result{RequeueAfter: r.requeue().PreviousRun}, true, nil
+	}
+
+	if err := createRunners(ctx, log, k6, r, tokenInfo); goerrors.Is(err, ErrRunnerDeleting) {
+		// the objects created so far are resumed by the next reconcile
+		log.Info(fmt.Sprintf("%v, checking again", err))
+		return ctrl.Result{RequeueAfter: r.requeue().Short}, true, nil
+	} else if err != nil {
+		return ctrl.Result{}, false, err
+	}
+	return ctrl.Result{}, false, nil
+}
+
+// createRunners creates all objects of the runners which weren't created yet.
+func createRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, tokenInfo *cloud.TokenInfo) error {
+	if k6.GetSpec().DisruptionBudget {
+		if err := createDisruptionBudget(ctx, k6, log, r); err != nil {
+			return err
+		}
+	}
+
+	if k6.GetSpec().HeadlessService {
+		if err := createHeadlessService(ctx, k6, log, r); err != nil {
+			return err
+		}
+	}
+
+	if k6.GetSpec().IndexedRunners() {
+		job, err := jobs.NewIndexedRunnerJob(k6, tokenInfo)
+		if err != nil {
+			log.Error(err, "Failed to generate k6 test job")
+			return err
+		}
+		if _, err := createRunnerJob(ctx, k6, log, r, job); err != nil {
+			return err
+		}
 	}
 
 	for i := 1; i <= int(k6.GetSpec().Parallelism); i++ {
 		if err := launchTest(ctx, k6, i, log, r, tokenInfo); err != nil {
-			return ctrl.Result{}, false, err
+			return err
 		}
 	}
-	return ctrl.Result{}, false, nil
+	return nil
 }
 
-func launchTest(ctx context.Context, k6 *v1alpha1.TestRun, index int, log logr.Logger, r *TestRunReconciler, tokenInfo *cloud.TokenInfo) error {
-	var job *batchv1.Job
-	var service *corev1.Service
-	var err error
+// setControllerReference makes the test run the controller of the runner object.
+// The object isn't created without its owner. The failures depend only on the
+// objects and the scheme, so they don't go away with a retry and the error
+// wraps ErrControllerReference. Failures of the API server on creation are
+// returned as is and retried with the next reconcile.
+func setControllerReference(k6 *v1alpha1.TestRun, obj client.Object, r *TestRunReconciler) error {
+	if err := ctrl.SetControllerReference(k6, obj, r.Scheme); err != nil {
+		return fmt.Errorf("%w: %w", ErrControllerReference, err)
+	}
+	return nil
+}
 
-	msg := fmt.Sprintf("Launching k6 test #%d", index)
-	log.Info(msg)
+func createDisruptionBudget(ctx context.Context, k6 *v1alpha1.TestRun, log logr.Logger, r *TestRunReconciler) error {
+	pdb := jobs.NewRunnerPodDisruptionBudget(k6)
+
+	if err := setControllerReference(k6, pdb, r); err != nil {
+		log.Error(err, "Failed to set controller reference for pod disruption budget")
+		return err
+	}
+
+	if err := r.Create(ctx, pdb); err != nil && !errors.IsAlreadyExists(err) {
+		log.Error(err, "Failed to create pod disruption budget")
+		return err
+	}
+
+	return nil
+}
+
+// createHeadlessService creates the single Service of all runners;
+// runners are then addressed by DNS names of their pods.
+func createHeadlessService(ctx context.Context, k6 *v1alpha1.TestRun, log logr.Logger, r *TestRunReconciler) error {
+	service := jobs.NewRunnerHeadlessService(k6)
+
+	if err := setControllerReference(k6, service, r); err != nil {
+		log.Error(err, "Failed to set controller reference for headless service")
+		return err
+	}
 
-	if job, err = jobs.NewRunnerJob(k6, index, tokenInfo); err != nil {
-		log.Error(err, "Failed to generate k6 test job")
+	if err := r.Create(ctx, service); err != nil && !errors.IsAlreadyExists(err) {
+		log.Error(err, "Failed to create headless service")
 		return err
 	}
 
+	return nil
+}
+
+// previousRunLeaving shows whether the job of a previous run with the same
+// name is going away: it's being deleted, or its TestRun was deleted and
+// it's left to the garbage collector. The TestRun is recognized by its UID.
+func previousRunLeaving(k6 *v1alpha1.TestRun, job *batchv1.Job) bool {
+	if job.DeletionTimestamp != nil {
+		return true
+	}
+	owner := metav1.GetControllerOf(job)
+	return owner != nil && owner.Kind == "TestRun" && owner.Name == k6.Name && owner.UID != k6.UID
+}
+
+// createOnce creates obj unless it was already created for this test run,
+// in which case it's loaded into existing and found is true. The existing
+// object is recognized by the UID of the TestRun in its controller reference.
+// An object with the same name not controlled by the test run is an error,
+// and so is an object of the test run which is being deleted: it must be
+// gone before it's created again.
+func createOnce(ctx context.Context, k6 *v1alpha1.TestRun, r *TestRunReconciler, obj, existing client.Object) (found bool, err error) {
+	if err = r.Get(ctx, client.ObjectKeyFromObject(obj), existing); err == nil {
+		if !metav1.IsControlledBy(existing, k6) {
+			return false, fmt.Errorf("%w: %s doesn't belong to this test run; make sure you've deleted your previous run", ErrPreviousRunExists, obj.GetName())
+		}
+		if existing.GetDeletionTimestamp() != nil {
+			return false, fmt.Errorf("%w: %s", ErrRunnerDeleting, obj.GetName())
+		}
+		return true, nil
+	} else if !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	return false, r.Create(ctx, obj)
+}
+
+// createRunnerJob creates the runner job unless it was already created for this test run.
+func createRunnerJob(ctx context.Context, k6 *v1alpha1.TestRun, log logr.Logger, r *TestRunReconciler, job *batchv1.Job) (found bool, err error) {
 	log.Info(fmt.Sprintf("Runner job is ready to start with image `%s` and command `%s`",
 		job.Spec.Template.Spec.Containers[0].Image, job.Spec.Template.Spec.Containers[0].Command))
 
-	if err = ctrl.SetControllerReference(k6, job, r.Scheme); err != nil {
+	if err = setControllerReference(k6, job, r); err != nil {
 		log.Error(err, "Failed to set controller reference for job")
-		return err
+		return false, err
 	}
 
-	if err = r.Create(ctx, job); err != nil {
+	if found, err = createOnce(ctx, k6, r, job, &batchv1.Job{}); err != nil {
 		log.Error(err, "Failed to launch k6 test")
-		return err
+		return false, err
+	}
+	return found, nil
+}
+
+// launchTest creates the job and the service of the runner with the given index.
+// With the Indexed completion mode, the runner is a pod of the Indexed Job,
+// which is created beforehand, and only the service is created.
+func launchTest(ctx context.Context, k6 *v1alpha1.TestRun, index int, log logr.Logger, r *TestRunReconciler, tokenInfo *cloud.TokenInfo) error {
+	var job *batchv1.Job
+	var service *corev1.Service
+	var err error
+
+	msg := fmt.Sprintf("Launching k6 test #%d", index)
+	log.Info(msg)
+
+	runnerName := fmt.Sprintf("%s-%d", k6.NamespacedName().Name, index)
+	jobFound := true
+	if !k6.GetSpec().IndexedRunners() {
+		if job, err = jobs.NewRunnerJob(k6, index, tokenInfo); err != nil {
+			log.Error(err, "Failed to generate k6 test job")
+			return err
+		}
+		if jobFound, err = createRunnerJob(ctx, k6, log, r, job); err != nil {
+			return err
+		}
+	}
+
+	if k6.GetSpec().HeadlessService {
+		// runner is reachable through the headless service
+		return nil
+	}
+
+	if k6.Standalone() || k6.GetSpec().RunnerPodIPs {
+		// runner is reachable by its pod IP
+		return nil
 	}
 
 	if service, err = jobs.NewRunnerService(k6, index); err != nil {
@@ -127,15 +465,43 @@ func launchTest(ctx context.Context, k6 *v1alpha1.TestRun, index int, log logr.L
 		return err
 	}
 
-	if err = ctrl.SetControllerReference(k6, service, r.Scheme); err != nil {
+	if err = setControllerReference(k6, service, r); err != nil {
 		log.Error(err, "Failed to set controller reference for service")
 		return err
 	}
 
-	if err = r.Create(ctx, service); err != nil {
+	existing := &corev1.Service{}
+	if found, err := createOnce(ctx, k6, r, service, existing); err != nil {
 		log.Error(err, "Failed to launch k6 test services")
+		if !jobFound {
+			// The runner mustn't be left without its service: the job is
+			// created again together with the service by the next reconcile.
+			deleteRunnerJob(ctx, log, r, job)
+		}
 		return err
+	} else if found {
+		service = existing
+	}
+
+	// NodePort and LoadBalancer services get their node port on creation:
+	// keep it in the status so that the runner can be reached from outside the cluster.
+	if nodePort := service.Spec.Ports[0].NodePort; nodePort > 0 {
+		if k6.GetStatus().NodePorts == nil {
+			k6.GetStatus().NodePorts = make(map[string]int32)
+		}
+		k6.GetStatus().NodePorts[runnerName] = nodePort
+		log.Info(fmt.Sprintf("Runner %s is exposed on node port %d", runnerName, nodePort))
 	}
 
 	return nil
 }
+
+// deleteRunnerJob deletes the runner job created without its service.
+// If it fails, the job is left behind and resumed by the next reconcile.
+func deleteRunnerJob(ctx context.Context, log logr.Logger, r *TestRunReconciler, job *batchv1.Job) {
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		log.Error(err, fmt.Sprintf("Failed to delete job %s", job.Name))
+		return
+	}
+	log.Info(fmt.Sprintf("Deleted job %s created without its service", job.Name))
+}
@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-test/deep"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_createJobSpecs_Resume(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	newTestRun := func() *v1alpha1.TestRun {
+		return &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism: 3,
+				Script: v1alpha1.K6Script{
+					ConfigMap: v1alpha1.K6Configmap{Name: "test", File: "test.js"},
+				},
+			},
+		}
+	}
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+
+	t.Run("partially created run", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		// the previous reconcile was interrupted after the first runner
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default", Labels: runnerLabels}}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-service-1", Namespace: "default", Labels: runnerLabels},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 6565}}},
+		}
+		for _, obj := range []client.Object{job, service} {
+			if err := ctrl.SetControllerReference(k6, obj, scheme); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, job, service).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		_, recheck, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if err != nil || recheck {
+			t.Fatalf("expected creation to resume, got recheck %v and error: %v", recheck, err)
+		}
+
+		jl := &batchv1.JobList{}
+		if err := c.List(context.Background(), jl, k6.ListOptions()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(jl.Items) != 3 {
+			t.Errorf("expected 3 runner jobs, got %d", len(jl.Items))
+		}
+	})
+
+	t.Run("runner being deleted", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		// the job of a replaced runner is still terminating
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-1",
+			Namespace:         "default",
+			Labels:            runnerLabels,
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			Finalizers:        []string{"foregroundDeletion"},
+		}}
+		if err := ctrl.SetControllerReference(k6, job, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, job).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		_, recheck, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if err != nil || !recheck {
+			t.Fatalf("expected creation to wait for the job, got recheck %v and error: %v", recheck, err)
+		}
+
+		sl := &corev1.ServiceList{}
+		if err := c.List(context.Background(), sl, k6.ListOptions()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(sl.Items) != 0 {
+			t.Errorf("expected no service of the terminating runner, got %d", len(sl.Items))
+		}
+	})
+
+	t.Run("invalid controller reference", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).Build()
+		// TestRun isn't registered in the scheme of the reconciler
+		operatorScheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(operatorScheme)
+		r := &TestRunReconciler{Client: c, Scheme: operatorScheme}
+
+		// it's not fixed by a retry
+		_, recheck, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if !errors.Is(err, ErrControllerReference) || recheck {
+			t.Fatalf("expected ErrControllerReference without recheck, got recheck %v and error: %v", recheck, err)
+		}
+	})
+
+	t.Run("stale previous run", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		// a job left by a deleted TestRun with the same name
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default", Labels: runnerLabels}}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, job).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		// it won't go away on its own: there is no need to wait
+		_, _, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if !errors.Is(err, ErrPreviousRunExists) {
+			t.Fatalf("expected ErrPreviousRunExists, got %v", err)
+		}
+
+		jl := &batchv1.JobList{}
+		if err := c.List(context.Background(), jl, k6.ListOptions()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(jl.Items) != 1 {
+			t.Errorf("expected only the stale job, got %d jobs", len(jl.Items))
+		}
+	})
+
+	t.Run("deleted previous run", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRun, metav1.ConditionFalse)
+		// a job of a deleted TestRun with the same name, not collected yet
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default", Labels: runnerLabels}}
+		previous := newTestRun()
+		previous.UID = "previous-uid"
+		if err := ctrl.SetControllerReference(previous, job, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, job).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		_, recheck, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if err != nil || !recheck {
+			t.Fatalf("expected to wait for the previous run, got recheck %v and error: %v", recheck, err)
+		}
+
+		r.PreviousRunGracePeriod = time.Nanosecond
+		_, _, err = createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if !errors.Is(err, ErrPreviousRunExists) {
+			t.Fatalf("expected ErrPreviousRunExists after the grace period, got %v", err)
+		}
+	})
+
+	t.Run("failed service", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		failed := false
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*corev1.Service); ok && obj.GetName() == "test-service-2" && !failed {
+					failed = true
+					return errors.New("service creation failed")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		if _, _, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", "")); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		jl := &batchv1.JobList{}
+		if err := c.List(context.Background(), jl, k6.ListOptions()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(jl.Items) != 1 || jl.Items[0].Name != "test-1" {
+			t.Fatalf("expected the job without service to be deleted, got %d jobs", len(jl.Items))
+		}
+
+		_, recheck, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if err != nil || recheck {
+			t.Fatalf("expected creation to resume, got recheck %v and error: %v", recheck, err)
+		}
+		if err := c.List(context.Background(), jl, k6.ListOptions()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(jl.Items) != 3 {
+			t.Errorf("expected 3 runner jobs, got %d", len(jl.Items))
+		}
+	})
+
+	t.Run("stale service", func(t *testing.T) {
+		t.Parallel()
+
+		k6 := newTestRun()
+		// a service left by a deleted TestRun with the same name
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service-2", Namespace: "default", Labels: runnerLabels}}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, service).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		_, _, err := createJobSpecs(context.Background(), logr.Discard(), k6, r, cloud.NewTokenInfo("", ""))
+		if !errors.Is(err, ErrPreviousRunExists) {
+			t.Fatalf("expected ErrPreviousRunExists, got %v", err)
+		}
+	})
+}
+
+func Test_idleRunnersWarning(t *testing.T) {
+	t.Parallel()
+
+	k6 := &v1alpha1.TestRun{Spec: v1alpha1.TestRunSpec{Parallelism: 4}}
+	if warning := idleRunnersWarning(k6); len(warning) > 0 {
+		t.Errorf("expected no warning without inspection, got %q", warning)
+	}
+
+	k6.Status.MaxVUs = 4
+	if warning := idleRunnersWarning(k6); len(warning) > 0 {
+		t.Errorf("expected no warning, got %q", warning)
+	}
+
+	// parallelism was increased after the inspection
+	k6.Spec.Parallelism = 6
+	if warning := idleRunnersWarning(k6); !strings.Contains(warning, "2 of 6 runners") {
+		t.Errorf("expected a warning about 2 idle runners, got %q", warning)
+	}
+}
+
+func Test_autoRunnerResources(t *testing.T) {
+	t.Parallel()
+
+	k6 := &v1alpha1.TestRun{
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism:   3,
+			AutoResources: &v1alpha1.K6AutoResources{},
+		},
+		Status: v1alpha1.TestRunStatus{MaxVUs: 100},
+	}
+
+	resources, err := autoRunnerResources(k6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the busiest runner gets 34 VUs
+	if memory := resources.Requests.Memory().String(); memory != "264Mi" {
+		t.Errorf("expected 264Mi of memory, got %s", memory)
+	}
+	if cpu := resources.Requests.Cpu().String(); cpu != "168m" {
+		t.Errorf("expected 168m of CPU, got %s", cpu)
+	}
+	if !resources.Limits.Memory().Equal(*resources.Requests.Memory()) {
+		t.Errorf("expected the memory limit to match the request, got %s", resources.Limits.Memory())
+	}
+
+	k6.Status.RunnerResources = resources
+	if diff := deep.Equal(k6.RunnerResources(), *resources); diff != nil {
+		t.Errorf("expected runners to get the computed resources, diff: %s", diff)
+	}
+
+	// explicit resources take precedence
+	k6.Spec.Runner.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	if resources, _ := autoRunnerResources(k6); resources != nil {
+		t.Errorf("expected no resources to be computed, got %v", resources)
+	}
+	if memory := k6.RunnerResources().Requests[corev1.ResourceMemory]; memory.String() != "1Gi" {
+		t.Errorf("expected the explicit resources, got %s of memory", memory.String())
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// debugContainerName is the name of the ephemeral container injected with DebugAnnotation.
+const debugContainerName = "k6-debug"
+
+// InjectDebugContainer adds an ephemeral debug container to the running pod
+// of the runner named in DebugAnnotation. The container shares the process
+// namespace of k6, so k6 can be inspected without restarting it.
+// Only the images allowed by the operator can be used, as the container is
+// injected with the privileges of the operator, and it runs with the security
+// context of k6.
+// The container is injected only once per pod: changing the image afterwards
+// has no effect on the same pod. Errors are only logged and reported with an
+// event since debugging must never disrupt the test run.
+func InjectDebugContainer(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) {
+	runner, ok := k6.GetAnnotations()[v1alpha1.DebugAnnotation]
+	if !ok || len(runner) == 0 {
+		return
+	}
+
+	image, err := r.debugImage(k6)
+	if err != nil {
+		log.Info(err.Error())
+		r.recordEvent(k6, corev1.EventTypeWarning, "DebugContainerFailed", err.Error())
+		return
+	}
+
+	pl := &corev1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		return
+	}
+
+	var pod *corev1.Pod
+	for i := range pl.Items {
+		if podRunner(&pl.Items[i]) == runner && pl.Items[i].Status.Phase == corev1.PodRunning {
+			pod = &pl.Items[i]
+			break
+		}
+	}
+	if pod == nil {
+		log.Info(fmt.Sprintf("No running pod of runner %s to debug", runner))
+		return
+	}
+
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == debugContainerName {
+			return
+		}
+	}
+
+	var securityContext *corev1.SecurityContext
+	for _, c := range pod.Spec.Containers {
+		if c.Name == "k6" {
+			securityContext = c.SecurityContext.DeepCopy()
+		}
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     debugContainerName,
+			Image:                    image,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			SecurityContext:          securityContext,
+		},
+		TargetContainerName: "k6",
+	})
+	if err := r.SubResource("ephemeralcontainers").Update(ctx, pod); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to inject debug container into pod %s", pod.Name))
+		r.recordEvent(k6, corev1.EventTypeWarning, "DebugContainerFailed",
+			fmt.Sprintf("Failed to inject debug container into pod %s: %v", pod.Name, err))
+		return
+	}
+
+	log.Info(fmt.Sprintf("Injected debug container into pod %s", pod.Name))
+	r.recordEvent(k6, corev1.EventTypeNormal, "DebugContainerInjected",
+		fmt.Sprintf("Debug container %s was injected into pod %s: kubectl attach -it -n %s %s -c %s",
+			debugContainerName, pod.Name, pod.Namespace, pod.Name, debugContainerName))
+}
+
+// debugImage returns the image of the debug container of the test run:
+// the one in DebugImageAnnotation or the first allowed image by default.
+func (r *TestRunReconciler) debugImage(k6 *v1alpha1.TestRun) (string, error) {
+	if len(r.DebugImages) == 0 {
+		return "", errors.New("debug containers are disabled: no debug images are allowed by the operator")
+	}
+
+	image, ok := k6.GetAnnotations()[v1alpha1.DebugImageAnnotation]
+	if !ok || len(image) == 0 {
+		return r.DebugImages[0], nil
+	}
+	if !slices.Contains(r.DebugImages, image) {
+		return "", fmt.Errorf("debug image %s is not allowed by the operator", image)
+	}
+	return image, nil
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_InjectDebugContainer(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+	nonRoot := true
+	newPod := func(name, job string, phase corev1.PodPhase, ephemeral ...corev1.EphemeralContainer) *corev1.Pod {
+		labels := map[string]string{"job-name": job}
+		for k, v := range runnerLabels {
+			labels[k] = v
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:            "k6",
+					SecurityContext: &corev1.SecurityContext{RunAsNonRoot: &nonRoot},
+				}},
+				EphemeralContainers: ephemeral,
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	injected := corev1.EphemeralContainer{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: debugContainerName}}
+
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		pods        []client.Object
+		debugImages []string
+		// image of the injected container, empty if nothing must be injected
+		image string
+	}{
+		{
+			name:        "default image",
+			annotations: map[string]string{v1alpha1.DebugAnnotation: "test-2"},
+			pods: []client.Object{
+				newPod("test-1-abcde", "test-1", corev1.PodRunning),
+				newPod("test-2-abcde", "test-2", corev1.PodRunning),
+			},
+			debugImages: []string{"busybox:stable", "nicolaka/netshoot"},
+			image:       "busybox:stable",
+		},
+		{
+			name: "custom image",
+			annotations: map[string]string{
+				v1alpha1.DebugAnnotation:      "test-2",
+				v1alpha1.DebugImageAnnotation: "nicolaka/netshoot",
+			},
+			pods:        []client.Object{newPod("test-2-abcde", "test-2", corev1.PodRunning)},
+			debugImages: []string{"busybox:stable", "nicolaka/netshoot"},
+			image:       "nicolaka/netshoot",
+		},
+		{
+			name: "image not allowed",
+			annotations: map[string]string{
+				v1alpha1.DebugAnnotation:      "test-2",
+				v1alpha1.DebugImageAnnotation: "attacker/image",
+			},
+			pods:        []client.Object{newPod("test-2-abcde", "test-2", corev1.PodRunning)},
+			debugImages: []string{"busybox:stable"},
+		},
+		{
+			name:        "no allowed images",
+			annotations: map[string]string{v1alpha1.DebugAnnotation: "test-2"},
+			pods:        []client.Object{newPod("test-2-abcde", "test-2", corev1.PodRunning)},
+		},
+		{
+			name:        "no annotation",
+			annotations: nil,
+			pods:        []client.Object{newPod("test-2-abcde", "test-2", corev1.PodRunning)},
+			debugImages: []string{"busybox:stable"},
+		},
+		{
+			name:        "pod is not running",
+			annotations: map[string]string{v1alpha1.DebugAnnotation: "test-2"},
+			pods:        []client.Object{newPod("test-2-abcde", "test-2", corev1.PodSucceeded)},
+		},
+		{
+			name:        "already injected",
+			annotations: map[string]string{v1alpha1.DebugAnnotation: "test-2"},
+			pods:        []client.Object{newPod("test-2-abcde", "test-2", corev1.PodRunning, injected)},
+		},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid", Annotations: tc.annotations},
+			Spec:       v1alpha1.TestRunSpec{Parallelism: 2},
+			Status:     v1alpha1.TestRunStatus{Stage: "started"},
+		}
+
+		var updated []*corev1.Pod
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.pods...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if subResourceName != "ephemeralcontainers" {
+						t.Errorf("%s: unexpected update of subresource %s", tc.name, subResourceName)
+					}
+					updated = append(updated, obj.(*corev1.Pod))
+					return nil
+				},
+			}).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme, DebugImages: tc.debugImages}
+
+		InjectDebugContainer(context.Background(), logr.Discard(), k6, r)
+
+		if len(tc.image) == 0 {
+			if len(updated) > 0 {
+				t.Errorf("%s: expected no debug container, got one in pod %s", tc.name, updated[0].Name)
+			}
+			continue
+		}
+		if len(updated) != 1 {
+			t.Fatalf("%s: expected 1 updated pod, got %d", tc.name, len(updated))
+		}
+		pod := updated[0]
+		if pod.Name != "test-2-abcde" {
+			t.Errorf("%s: expected the pod of runner test-2, got %s", tc.name, pod.Name)
+		}
+		if len(pod.Spec.EphemeralContainers) != 1 {
+			t.Fatalf("%s: expected 1 ephemeral container, got %d", tc.name, len(pod.Spec.EphemeralContainers))
+		}
+		ec := pod.Spec.EphemeralContainers[0]
+		if ec.Name != debugContainerName || ec.Image != tc.image || ec.TargetContainerName != "k6" {
+			t.Errorf("%s: unexpected debug container %+v", tc.name, ec)
+		}
+		if ec.SecurityContext == nil || ec.SecurityContext.RunAsNonRoot == nil || !*ec.SecurityContext.RunAsNonRoot {
+			t.Errorf("%s: expected the security context of k6, got %+v", tc.name, ec.SecurityContext)
+		}
+	}
+}
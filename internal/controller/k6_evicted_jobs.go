@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/resources/jobs"
+	"github.com/grafana/k6-operator/pkg/testrun"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rejoinAnnotation marks a replacement runner job which must still be
+// started by the operator to rejoin the test.
+const rejoinAnnotation = "k6.io/rejoin"
+
+// podEviction returns the message of the eviction if the pod was evicted
+// or preempted, as opposed to a failure of k6 itself.
+func podEviction(pod *corev1.Pod) (string, bool) {
+	if pod.Status.Phase != corev1.PodFailed {
+		return "", false
+	}
+	if pod.Status.Reason == "Evicted" {
+		return pod.Status.Message, true
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.DisruptionTarget && cond.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("%s: %s", cond.Reason, cond.Message), true
+		}
+	}
+	return "", false
+}
+
+// runnerDisruption returns the message of the disruption if the pod must be
+// replaced: on eviction with TolerateRunnerEviction and on loss of its node
+// with the Rejoin NodeLossPolicy.
+func runnerDisruption(k6 *v1alpha1.TestRun, pod *corev1.Pod) (string, bool) {
+	if k6.GetSpec().TolerateRunnerEviction {
+		if msg, ok := podEviction(pod); ok {
+			return msg, true
+		}
+	}
+	if k6.GetSpec().NodeLossPolicy == v1alpha1.RejoinOnNodeLoss {
+		return podNodeLoss(pod)
+	}
+	return "", false
+}
+
+// disrupted shows whether an eviction of the runner job was recorded since
+// the given time. The replacement job has the same name as the evicted one,
+// so only the disruptions after the creation of the job are relevant to it.
+func disrupted(k6 *v1alpha1.TestRun, name string, since metav1.Time) bool {
+	for _, d := range k6.GetStatus().Disruptions {
+		if d.Runner == name && !d.Time.Before(&since) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceEvictedRunners replaces the runners evicted during the test, with
+// TolerateRunnerEviction, or lost with their node, with the Rejoin
+// NodeLossPolicy. It takes several reconciles: the job of an evicted
+// runner is deleted and recorded in the status, then it's created again for
+// the same execution segment and, once its pod is running, the test is
+// started on it. The service of the runner is kept.
+// It returns true while a replacement is in progress.
+func ReplaceEvictedRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (replacing bool, err error) {
+	jl := &batchv1.JobList{}
+	if err = r.listRunners(ctx, k6, jl); err != nil {
+		log.Error(err, "Could not list jobs")
+		return false, err
+	}
+
+	pl := &corev1.PodList{}
+	if err = r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		return false, err
+	}
+
+	runnerJobs := make(map[string]*batchv1.Job, len(jl.Items))
+	for i := range jl.Items {
+		runnerJobs[jl.Items[i].Name] = &jl.Items[i]
+	}
+
+	if evicted, err := deleteEvictedRunners(ctx, log, k6, r, runnerJobs, pl.Items); err != nil || evicted {
+		return evicted, err
+	}
+
+	if created, err := recreateEvictedRunners(ctx, log, k6, r, runnerJobs); err != nil || created {
+		return created, err
+	}
+
+	return rejoinRunners(ctx, log, k6, r, runnerJobs, pl.Items), nil
+}
+
+// deleteEvictedRunners deletes the jobs of disrupted runner pods and records
+// the disruptions in the status.
+func deleteEvictedRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler,
+	runnerJobs map[string]*batchv1.Job, pods []corev1.Pod) (bool, error) {
+	var running int
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning {
+			running++
+		}
+	}
+
+	var evicted []*batchv1.Job
+	for i := range pods {
+		pod := &pods[i]
+		msg, ok := runnerDisruption(k6, pod)
+		if !ok {
+			continue
+		}
+
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil || owner.Kind != "Job" {
+			continue
+		}
+		job, found := runnerJobs[owner.Name]
+		if !found || job.UID != owner.UID || job.DeletionTimestamp != nil {
+			// the pod of a job which was already replaced
+			continue
+		}
+
+		if k6.RunsSetup() && running == 0 {
+			log.Info(fmt.Sprintf("Runner pod %s was evicted but cannot be replaced: there is no runner to copy setup data from", pod.Name))
+			continue
+		}
+
+		log.Info(fmt.Sprintf("Runner pod %s was evicted: %s", pod.Name, msg))
+		if !disrupted(k6, job.Name, job.CreationTimestamp) {
+			k6.GetStatus().Disruptions = append(k6.GetStatus().Disruptions, v1alpha1.RunnerDisruption{
+				Runner:  job.Name,
+				Message: msg,
+				Time:    metav1.Now(),
+			})
+		}
+		evicted = append(evicted, job)
+	}
+
+	if len(evicted) == 0 {
+		return false, nil
+	}
+
+	// The disruptions are recorded before the deletion: they are
+	// what the replacement jobs are created from.
+	if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		return true, err
+	}
+
+	for _, job := range evicted {
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete evicted runner job %s", job.Name))
+			return true, err
+		}
+		log.Info(fmt.Sprintf("Deleted evicted runner job %s", job.Name))
+		r.recordEvent(k6, corev1.EventTypeWarning, "Evicted", fmt.Sprintf("Runner %s was evicted and is being replaced", job.Name))
+	}
+
+	return true, nil
+}
+
+// recreateEvictedRunners creates the jobs of evicted runners again.
+// Jobs missing for other reasons are left alone.
+func recreateEvictedRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, runnerJobs map[string]*batchv1.Job) (bool, error) {
+	tokenInfo := newTokenInfo(k6)
+	created := false
+
+	for index := 1; index <= int(k6.GetStatus().Parallelism); index++ {
+		name := fmt.Sprintf("%s-%d", k6.NamespacedName().Name, index)
+		if _, found := runnerJobs[name]; found || !disrupted(k6, name, metav1.Time{}) {
+			continue
+		}
+
+		if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) && !tokenInfo.Ready {
+			if err := tokenInfo.Load(ctx, log, r.Client); err != nil {
+				return false, err
+			}
+			if !tokenInfo.Ready {
+				return true, nil
+			}
+		}
+
+		job, err := jobs.NewRunnerJob(k6, index, tokenInfo)
+		if err != nil {
+			log.Error(err, "Failed to generate k6 test job")
+			return false, err
+		}
+		if k6.RunnersPaused() {
+			if job.Annotations == nil {
+				job.Annotations = make(map[string]string)
+			}
+			job.Annotations[rejoinAnnotation] = "true"
+		}
+
+		if err = ctrl.SetControllerReference(k6, job, r.Scheme); err != nil {
+			log.Error(err, "Failed to set controller reference for job")
+			return false, err
+		}
+
+		if err = r.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to create replacement runner job %s", name))
+			return false, err
+		}
+		log.Info(fmt.Sprintf("Created replacement runner job %s", name))
+		created = true
+	}
+
+	return created, nil
+}
+
+// rejoinRunners starts the test on the replacement runners once their pods
+// are running, passing them the setup data of another runner if needed.
+// It returns true if some runner is still waiting to rejoin.
+func rejoinRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler,
+	runnerJobs map[string]*batchv1.Job, pods []corev1.Pod) (waiting bool) {
+	podOf := func(job *batchv1.Job) *corev1.Pod {
+		for i := range pods {
+			owner := metav1.GetControllerOf(&pods[i])
+			if owner != nil && owner.UID == job.UID && pods[i].Status.Phase == corev1.PodRunning && len(pods[i].Status.PodIP) > 0 {
+				return &pods[i]
+			}
+		}
+		return nil
+	}
+
+	for _, job := range runnerJobs {
+		if _, ok := job.Annotations[rejoinAnnotation]; !ok {
+			continue
+		}
+
+		pod := podOf(job)
+		if pod == nil {
+			log.Info(fmt.Sprintf("Waiting for replacement runner %s to be running", job.Name))
+			waiting = true
+			continue
+		}
+
+		if k6.RunsSetup() {
+			if err := copySetupData(ctx, k6, runnerJobs, podOf, pod.Status.PodIP); err != nil {
+				log.Error(err, fmt.Sprintf("Failed to pass setup data to replacement runner %s", job.Name))
+				waiting = true
+				continue
+			}
+		}
+
+		if _, err := StartK6FromOperators(ctx, log, k6, []string{pod.Status.PodIP}, r); err != nil {
+			log.Info(fmt.Sprintf("Replacement runner %s cannot be started yet: %v", job.Name, err))
+			waiting = true
+			continue
+		}
+
+		patch := client.MergeFrom(job.DeepCopy())
+		delete(job.Annotations, rejoinAnnotation)
+		if err := r.Patch(ctx, job, patch); err != nil {
+			// the runner is already running: it's not started again
+			log.Error(err, fmt.Sprintf("Failed to update replacement runner job %s", job.Name))
+		}
+
+		log.Info(fmt.Sprintf("Replacement runner %s has rejoined the test", job.Name))
+		r.recordEvent(k6, corev1.EventTypeNormal, "Rejoined", fmt.Sprintf("Runner %s has rejoined the test", job.Name))
+	}
+
+	return waiting
+}
+
+// copySetupData passes the setup data of any runner which was not replaced
+// to the runner at hostname.
+func copySetupData(ctx context.Context, k6 *v1alpha1.TestRun, runnerJobs map[string]*batchv1.Job,
+	podOf func(*batchv1.Job) *corev1.Pod, hostname string) error {
+	for _, job := range runnerJobs {
+		if _, ok := job.Annotations[rejoinAnnotation]; ok {
+			continue
+		}
+		pod := podOf(job)
+		if pod == nil {
+			continue
+		}
+
+		data, err := testrun.GetSetupData(ctx, pod.Status.PodIP)
+		if err != nil {
+			return err
+		}
+		return testrun.SetSetupData(ctx, []string{hostname}, data)
+	}
+
+	return fmt.Errorf("test run %s has no running runner to copy setup data from", k6.NamespacedName().Name)
+}
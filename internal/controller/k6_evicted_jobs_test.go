@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_ReplaceEvictedRunners(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism:            2,
+			TolerateRunnerEviction: true,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{Name: "test", File: "test.js"},
+			},
+		},
+		Status: v1alpha1.TestRunStatus{Stage: "started", Parallelism: 2},
+	}
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+
+	evictedJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default", UID: "job-1", Labels: runnerLabels}}
+	runningJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-2", Namespace: "default", UID: "job-2", Labels: runnerLabels}}
+	for _, job := range []*batchv1.Job{evictedJob, runningJob} {
+		if err := ctrl.SetControllerReference(k6, job, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	newPod := func(name string, job *batchv1.Job, status corev1.PodStatus) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: runnerLabels}, Status: status}
+		if err := ctrl.SetControllerReference(job, pod, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return pod
+	}
+	evictedPod := newPod("test-1-abcde", evictedJob, corev1.PodStatus{
+		Phase:   corev1.PodFailed,
+		Reason:  "Evicted",
+		Message: "The node was low on resource: memory.",
+	})
+	runningPod := newPod("test-2-abcde", runningJob, corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.2"})
+	// a runner which has failed on its own must not be replaced
+	crashedPod := newPod("test-2-fghij", runningJob, corev1.PodStatus{Phase: corev1.PodFailed})
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(k6, evictedJob, runningJob, evictedPod, runningPod, crashedPod).
+		WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	// the evicted job is deleted and the disruption is recorded
+	if replacing, err := ReplaceEvictedRunners(ctx, logr.Discard(), k6, r); err != nil || !replacing {
+		t.Fatalf("expected a replacement, got %v and error: %v", replacing, err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(evictedJob), &batchv1.Job{}); !k8sErrors.IsNotFound(err) {
+		t.Fatalf("expected %s to be deleted, got error: %v", evictedJob.Name, err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(runningJob), &batchv1.Job{}); err != nil {
+		t.Fatalf("expected %s to be kept, got error: %v", runningJob.Name, err)
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := updated.Status.Disruptions; len(d) != 1 || d[0].Runner != "test-1" || d[0].Message != "The node was low on resource: memory." {
+		t.Fatalf("expected a disruption of test-1, got %+v", d)
+	}
+
+	// the job is created again for the same segment, to be started by the operator
+	if replacing, err := ReplaceEvictedRunners(ctx, logr.Discard(), k6, r); err != nil || !replacing {
+		t.Fatalf("expected a replacement, got %v and error: %v", replacing, err)
+	}
+	replacement := &batchv1.Job{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(evictedJob), replacement); err != nil {
+		t.Fatalf("expected %s to be created again, got error: %v", evictedJob.Name, err)
+	}
+	if _, ok := replacement.Annotations[rejoinAnnotation]; !ok {
+		t.Errorf("expected %s to be marked for rejoining", replacement.Name)
+	}
+	if !metav1.IsControlledBy(replacement, k6) {
+		t.Errorf("expected %s to be controlled by the TestRun", replacement.Name)
+	}
+
+	// the pod of the evicted job doesn't make the replacement to be deleted
+	if replacing, err := ReplaceEvictedRunners(ctx, logr.Discard(), k6, r); err != nil || !replacing {
+		t.Fatalf("expected to wait for the replacement, got %v and error: %v", replacing, err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(evictedJob), &batchv1.Job{}); err != nil {
+		t.Errorf("expected %s to be kept, got error: %v", evictedJob.Name, err)
+	}
+	if len(k6.Status.Disruptions) != 1 {
+		t.Errorf("expected a single disruption, got %+v", k6.Status.Disruptions)
+	}
+}
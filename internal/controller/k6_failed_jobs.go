@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jobFailure returns a description of the failure if Kubernetes has terminated
+// the job for one of the given reasons.
+func jobFailure(job *batchv1.Job, reasons ...string) (string, bool) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type != batchv1.JobFailed || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		for _, reason := range reasons {
+			if cond.Reason == reason {
+				return fmt.Sprintf("runner job %s failed with %s: %s", job.Name, cond.Reason, cond.Message), true
+			}
+		}
+	}
+	return "", false
+}
+
+// FailedJobs checks if any of the runner jobs has been terminated by Kubernetes,
+// e.g. because it has reached its activeDeadlineSeconds, or if it has failed
+// more times than its backoffLimit allows before the test was started.
+// In that case, the test run is moved to the error stage.
+func FailedJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (failed bool, err error) {
+	if len(k6.GetStatus().TestRunID) > 0 {
+		log = log.WithValues("testRunId", k6.GetStatus().TestRunID)
+	}
+
+	jl := &batchv1.JobList{}
+	if err = r.listRunners(ctx, k6, jl); err != nil {
+		log.Error(err, "Could not list jobs")
+		return false, err
+	}
+
+	reasons := []string{batchv1.JobReasonDeadlineExceeded}
+	// Before the start, runners are not supposed to exit at all, so a failure
+	// of a runner job means the test cannot be started: there is no need
+	// to wait for the timeout of pods' readiness.
+	beforeStart := k6.GetStatus().Stage == "created"
+	if beforeStart {
+		reasons = append(reasons, batchv1.JobReasonBackoffLimitExceeded)
+	}
+
+	for i := range jl.Items {
+		job := &jl.Items[i]
+		msg, ok := jobFailure(job, reasons...)
+		if !ok && beforeStart && job.Spec.BackoffLimit != nil && job.Status.Failed > *job.Spec.BackoffLimit {
+			// the Failed condition might not be set yet
+			msg = fmt.Sprintf("runner job %s has %d failed pods, over its backoff limit of %d", job.Name, job.Status.Failed, *job.Spec.BackoffLimit)
+			ok = true
+		}
+		if !ok {
+			continue
+		}
+
+		log.Info(msg)
+		k6.GetStatus().Error = msg
+		k6.GetStatus().RunnerLogs = failedRunnerLogs(ctx, log, k6, r, job.Name)
+
+		if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) {
+			events := cloud.ErrorEvent(cloud.K6OperatorRunnerError).
+				WithDetail(msg).
+				WithAbort()
+			r.sendCloudEvents(ctx, log, k6, events)
+		}
+
+		log.Info("Changing stage of TestRun status to error")
+		k6.GetStatus().Stage = "error"
+		v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionFalse)
+
+		_, err = r.UpdateStatus(ctx, k6, log)
+		return true, err
+	}
+
+	return false, nil
+}
+
+// ChangedParallelism checks whether Parallelism was changed after the runners
+// were created. The load is already segmented between the existing runners,
+// so the test would run with a wrong distribution: it's aborted instead.
+// An increase before the start is handled with ScaleOutRunners instead.
+func ChangedParallelism(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (bool, error) {
+	created := k6.GetStatus().Parallelism
+	if created == 0 || created == k6.GetSpec().Parallelism {
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("Parallelism was changed from %d to %d after the runners were created: the load cannot be redistributed, create a new TestRun instead.",
+		created, k6.GetSpec().Parallelism)
+	log.Info(msg)
+
+	if isCloudTestRun(k6) {
+		events := cloud.ErrorEvent(cloud.K6OperatorAbortError).
+			WithDetail(msg).
+			WithAbort()
+		r.sendCloudEvents(ctx, log, k6, events)
+	}
+
+	k6.GetStatus().Error = msg
+	_, err := abortStart(ctx, log, k6, r)
+	return true, err
+}
+
+// abortRunners deletes the runner jobs and services of the test run which
+// couldn't be started. Jobs are deleted first, so that their pods stop
+// consuming resources as soon as possible, and services after them.
+// Only the resources controlled by this TestRun are deleted.
+// The grace period of a deletion doesn't reach the pods of a job, so with
+// AbortGracePeriodSeconds the pods of the deleted jobs are deleted as well.
+func abortRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) error {
+	jl := &batchv1.JobList{}
+	if err := r.listRunners(ctx, k6, jl); err != nil {
+		log.Error(err, "Could not list jobs")
+		return err
+	}
+
+	sl := &corev1.ServiceList{}
+	if err := r.listRunners(ctx, k6, sl); err != nil {
+		log.Error(err, "Could not list services")
+		return err
+	}
+
+	objects := make([]client.Object, 0, len(jl.Items)+len(sl.Items))
+	for i := range jl.Items {
+		objects = append(objects, &jl.Items[i])
+	}
+	for i := range sl.Items {
+		objects = append(objects, &sl.Items[i])
+	}
+
+	// the order of listed objects is not guaranteed
+	sort.SliceStable(objects, func(i, j int) bool {
+		_, iJob := objects[i].(*batchv1.Job)
+		_, jJob := objects[j].(*batchv1.Job)
+		if iJob != jJob {
+			return iJob
+		}
+		return objects[i].GetName() < objects[j].GetName()
+	})
+
+	var (
+		errs    []error
+		deleted []client.Object
+	)
+	for _, obj := range objects {
+		if !metav1.IsControlledBy(obj, k6) {
+			log.Info(fmt.Sprintf("Skipping %s: it is not controlled by the TestRun", obj.GetName()))
+			continue
+		}
+
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete %s", obj.GetName()))
+			errs = append(errs, err)
+			continue
+		}
+		log.Info(fmt.Sprintf("Deleted %s", obj.GetName()))
+		deleted = append(deleted, obj)
+	}
+
+	if grace := k6.GetSpec().AbortGracePeriodSeconds; grace != nil {
+		if err := deleteRunnerPods(ctx, log, k6, r, deleted, *grace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteRunnerPods deletes the pods of the deleted runner jobs with the grace
+// period. Deleting them explicitly, after their jobs so that they aren't
+// recreated, is the only way to shorten the terminationGracePeriodSeconds
+// they would be terminated with by the garbage collector.
+func deleteRunnerPods(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, owners []client.Object, grace int64) error {
+	pl := &corev1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		return err
+	}
+
+	var errs []error
+	for i := range pl.Items {
+		pod := &pl.Items[i]
+		if !slices.ContainsFunc(owners, func(job client.Object) bool { return metav1.IsControlledBy(pod, job) }) {
+			continue
+		}
+
+		if err := r.Delete(ctx, pod, client.GracePeriodSeconds(grace)); client.IgnoreNotFound(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete pod %s", pod.Name))
+			errs = append(errs, err)
+			continue
+		}
+		log.Info(fmt.Sprintf("Deleted pod %s with a grace period of %ds", pod.Name, grace))
+	}
+
+	return errors.Join(errs...)
+}
+
+// abortStart cleans up the runners after an abort of the test run
+// before its start and moves the test run to the error stage.
+func abortStart(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	if err := abortRunners(ctx, log, k6, r); err != nil {
+		// try again: runners must not be left behind
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Changing stage of TestRun status to error")
+	k6.GetStatus().Stage = "error"
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionFalse)
+
+	if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_abortRunners(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	grace := int64(5)
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 1, AbortGracePeriodSeconds: &grace},
+	}
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+
+	ownedJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default", UID: "job-1", Labels: runnerLabels}}
+	ownedService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service-1", Namespace: "default", Labels: runnerLabels}}
+	for _, obj := range []client.Object{ownedJob, ownedService} {
+		if err := ctrl.SetControllerReference(k6, obj, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	ownedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-1-abcde", Namespace: "default", Labels: runnerLabels}}
+	if err := ctrl.SetControllerReference(ownedJob, ownedPod, scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a user resource which happens to have the same labels
+	userJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "user-job", Namespace: "default", Labels: runnerLabels}}
+
+	podGrace := map[string]*int64{}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, ownedJob, ownedService, ownedPod, userJob).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if _, ok := obj.(*corev1.Pod); ok {
+					do := &client.DeleteOptions{}
+					do.ApplyOptions(opts)
+					podGrace[obj.GetName()] = do.GracePeriodSeconds
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+	if err := abortRunners(context.Background(), logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(ownedJob), &batchv1.Job{}); !k8sErrors.IsNotFound(err) {
+		t.Errorf("expected %s to be deleted, got error: %v", ownedJob.Name, err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(ownedService), &corev1.Service{}); !k8sErrors.IsNotFound(err) {
+		t.Errorf("expected %s to be deleted, got error: %v", ownedService.Name, err)
+	}
+
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(userJob), &batchv1.Job{}); err != nil {
+		t.Errorf("expected %s to be kept, got error: %v", userJob.Name, err)
+	}
+
+	if g, ok := podGrace[ownedPod.Name]; !ok || g == nil || *g != grace {
+		t.Errorf("expected %s to be deleted with a grace period of %d, got %v", ownedPod.Name, grace, g)
+	}
+}
+
+func Test_ChangedParallelism(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 2},
+		Status:     v1alpha1.TestRunStatus{Stage: "started", Parallelism: 2},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+	if changed, err := ChangedParallelism(context.Background(), logr.Discard(), k6, r); err != nil || changed {
+		t.Fatalf("expected no change, got %v and error: %v", changed, err)
+	}
+
+	k6.Spec.Parallelism = 3
+	if changed, err := ChangedParallelism(context.Background(), logr.Discard(), k6, r); err != nil || !changed {
+		t.Fatalf("expected a change, got %v and error: %v", changed, err)
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.Stage != "error" || len(updated.Status.Error) == 0 {
+		t.Errorf("expected the error stage with an error, got stage %q and error %q", updated.Status.Stage, updated.Status.Error)
+	}
+}
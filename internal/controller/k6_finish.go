@@ -3,13 +3,17 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/cloud"
 	batchv1 "k8s.io/api/batch/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // FinishJobs checks if the runners pods have finished execution.
@@ -20,50 +24,326 @@ func FinishJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *T
 
 	log.Info("Checking if all runner pods are finished")
 
-	selector := labels.SelectorFromSet(map[string]string{
-		"app":    "k6",
-		"k6_cr":  k6.NamespacedName().Name,
-		"runner": "true",
-	})
-
-	opts := &client.ListOptions{LabelSelector: selector, Namespace: k6.NamespacedName().Namespace}
 	jl := &batchv1.JobList{}
 	var err error
 
-	if err = r.List(ctx, jl, opts); err != nil {
+	if err = r.listRunners(ctx, k6, jl); err != nil {
 		log.Error(err, "Could not list jobs")
 		return
 	}
 
-	// TODO: We should distinguish between Suceeded/Failed/Unknown
 	var (
 		finished, failed int32
+		completionTime   time.Time
 	)
 	for _, job := range jl.Items {
-		if job.Status.Active != 0 {
+		done, jobFailed, at := jobTerminalState(&job)
+		jobFinished, jobFailures := finishedRunners(&job, done, jobFailed)
+		finished += jobFinished
+		failed += jobFailures
+		if !done {
 			continue
 		}
-		finished++
 
-		if job.Status.Failed > 0 {
-			failed++
+		if at.After(completionTime) {
+			completionTime = at
+		}
+
+		if jobFailed && len(k6.GetStatus().RunnerLogs) == 0 {
+			// The other runners might still be running: the logs are kept
+			// in the status right away, so that they are read only once.
+			k6.GetStatus().RunnerLogs = failedRunnerLogs(ctx, log, k6, r, job.Name)
+			if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+				log.Error(err, "Could not keep the logs of the failed runner")
+			}
 		}
 	}
 
-	msg := fmt.Sprintf("%d/%d jobs complete, %d failed", finished, k6.GetSpec().Parallelism, failed)
+	msg := fmt.Sprintf("%d/%d jobs complete, %d failed", finished, k6.Runners(), failed)
 	log.Info(msg)
 
 	if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) && failed > 0 {
 		events := cloud.ErrorEvent(cloud.K6OperatorRunnerError).
 			WithDetail(msg).
 			WithAbort()
-		cloud.SendTestRunEvents(r.k6CloudClient, k6.TestRunID(), log, events)
+		r.sendCloudEvents(ctx, log, k6, events)
 	}
 
-	if finished < k6.GetSpec().Parallelism {
+	if finished < k6.Runners() {
 		return
 	}
 
+	k6.GetStatus().Result = v1alpha1.TestRunSucceeded
+	if failed > 0 {
+		k6.GetStatus().Result = v1alpha1.TestRunFailed
+	}
+	k6.GetStatus().CompletionTime = &metav1.Time{Time: completionTime}
+	if startTime := k6.GetStatus().StartTime; startTime != nil {
+		k6.GetStatus().Duration = &metav1.Duration{Duration: completionTime.Sub(startTime.Time)}
+	}
+
 	allFinished = true
 	return
 }
+
+// KeepRunners records in the status until when the runner pods are kept
+// with KeepRunnersAfterFinish, once k6 has exited in all of them.
+// The pods finish on their own at that time.
+func KeepRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) {
+	keep := k6.GetSpec().KeepRunnersAfterFinish
+	startTime := k6.GetStatus().StartTime
+	if keep == nil || k6.GetStatus().RunnersKeptUntil != nil || startTime == nil {
+		return
+	}
+
+	pl := &corev1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		return
+	}
+
+	// in case of restarts, a runner has finished only if none of its pods runs k6
+	exited, running := map[string]bool{}, map[string]bool{}
+	for i := range pl.Items {
+		pod := &pl.Items[i]
+		runner := podRunner(pod)
+		if k6.IsMissingRunner(runner) {
+			continue
+		}
+		if keptRunnerExited(pod, startTime.Time) {
+			exited[runner] = true
+		} else {
+			running[runner] = true
+		}
+	}
+	for runner := range running {
+		delete(exited, runner)
+	}
+	if int32(len(exited)) != k6.Runners() {
+		return
+	}
+
+	until := metav1.NewTime(time.Now().Add(keep.Duration))
+	k6.GetStatus().RunnersKeptUntil = &until
+	msg := fmt.Sprintf("Runner pods are kept for inspection until %s", until.Format(time.RFC3339))
+	log.Info(msg)
+
+	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		log.Error(err, "Failed to record until when the runners are kept")
+	} else if updateHappened {
+		r.recordEvent(k6, corev1.EventTypeNormal, "RunnersKept", msg)
+	}
+}
+
+// keptRunnerExited shows whether k6 has exited in the pod of a runner kept
+// with KeepRunnersAfterFinish: the pod or its k6 container has terminated,
+// or the container still runs the wrapper of k6 but the pod has become unready
+// since the start of the test, as the REST API of k6 checked by the default
+// readiness probe is gone.
+func keptRunnerExited(pod *corev1.Pod, startTime time.Time) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == "k6" && cs.State.Terminated != nil {
+			return true
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionFalse {
+			return !cond.LastTransitionTime.Time.Before(startTime.Truncate(time.Second))
+		}
+	}
+	return false
+}
+
+// jobTerminalState returns whether the job has finished, whether it has failed,
+// and when it has finished.
+func jobTerminalState(job *batchv1.Job) (finished, failed bool, at time.Time) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, false, c.LastTransitionTime.Time
+		case batchv1.JobFailed:
+			return true, true, c.LastTransitionTime.Time
+		}
+	}
+	return false, false, time.Time{}
+}
+
+// finishedRunners returns how many runners of the job have finished and failed.
+// The Indexed Job has a runner per completion: once the job is done, its
+// runners which haven't completed are terminated as well.
+func finishedRunners(job *batchv1.Job, done, failed bool) (int32, int32) {
+	if !isIndexed(job) {
+		switch {
+		case !done:
+			return 0, 0
+		case failed:
+			return 1, 1
+		default:
+			return 1, 0
+		}
+	}
+
+	// failed pods might be retried with backoffLimit
+	finished, failures := min(job.Status.Succeeded+job.Status.Failed, *job.Spec.Completions), job.Status.Failed
+	if done {
+		finished = *job.Spec.Completions
+	}
+	if failed {
+		// e.g. with the activeDeadlineSeconds exceeded, no pod might have failed
+		failures = max(failures, 1)
+	}
+	return finished, failures
+}
+
+// isIndexed shows whether the job is the Indexed Job of all runners.
+func isIndexed(job *batchv1.Job) bool {
+	return job.Spec.CompletionMode != nil && *job.Spec.CompletionMode == batchv1.IndexedCompletion &&
+		job.Spec.Completions != nil
+}
+
+// podRunner returns the name of the runner of the pod: its job or, for a pod
+// of the Indexed Job, `<name>-<i+1>` for the completion index i.
+func podRunner(pod *corev1.Pod) string {
+	if index, ok := pod.Annotations[batchv1.JobCompletionIndexAnnotation]; ok {
+		if i, err := strconv.Atoi(index); err == nil {
+			return fmt.Sprintf("%s-%d", pod.Labels["k6_cr"], i+1)
+		}
+	}
+	return pod.Labels["job-name"]
+}
+
+// thresholdsFailedExitCode is the exit code of k6 when some thresholds have failed.
+const thresholdsFailedExitCode = 99
+
+// runnersResult combines the exit codes of the finished runner pods.
+// Each runner evaluates thresholds only for its own segment of the test,
+// so the test has passed its thresholds only if all runners exited successfully.
+// thresholdsFailed is true if at least one runner exited because of failed thresholds.
+func runnersResult(pods []corev1.Pod) (passed, thresholdsFailed bool, summary string) {
+	// in case of restarts, only the latest pod of each job matters
+	latest := map[string]*corev1.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		job := podRunner(pod)
+		if prev, ok := latest[job]; !ok || prev.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			latest[job] = pod
+		}
+	}
+
+	jobNames := make([]string, 0, len(latest))
+	for job := range latest {
+		jobNames = append(jobNames, job)
+	}
+	sort.Strings(jobNames)
+
+	var (
+		succeeded int
+		failures  []string
+	)
+	for _, job := range jobNames {
+		exitCode := int32(-1)
+		for _, status := range latest[job].Status.ContainerStatuses {
+			if status.Name == "k6" && status.State.Terminated != nil {
+				exitCode = status.State.Terminated.ExitCode
+			}
+		}
+
+		switch exitCode {
+		case 0:
+			succeeded++
+		case thresholdsFailedExitCode:
+			thresholdsFailed = true
+			failures = append(failures, fmt.Sprintf("%s failed thresholds", job))
+		default:
+			failures = append(failures, fmt.Sprintf("%s exited with code %d", job, exitCode))
+		}
+	}
+
+	summary = fmt.Sprintf("%d/%d runners passed", succeeded, len(jobNames))
+	if len(failures) > 0 {
+		summary = fmt.Sprintf("%s: %s", summary, strings.Join(failures, ", "))
+	}
+
+	return len(jobNames) > 0 && len(failures) == 0, thresholdsFailed, summary
+}
+
+// SetRunnersResult records the combined result of all finished runners in the status.
+func SetRunnersResult(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) {
+	pl := &corev1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		return
+	}
+
+	passed, thresholdsFailed, summary := runnersResult(pl.Items)
+	log.Info(fmt.Sprintf("Result of the runners: %s", summary))
+
+	k6.GetStatus().ThresholdsPassed = &passed
+	k6.GetStatus().Summary = summary
+
+	// a runner which crashed tells nothing about thresholds
+	if thresholdsFailed {
+		v1alpha1.UpdateCondition(k6, v1alpha1.ThresholdsMet, metav1.ConditionFalse)
+	} else if passed {
+		v1alpha1.UpdateCondition(k6, v1alpha1.ThresholdsMet, metav1.ConditionTrue)
+	}
+}
+
+// FinalizeCloudTestRun reports to the cloud that the test run has finished,
+// with the combined result of the runners: it's passed only if all runners
+// have succeeded.
+func FinalizeCloudTestRun(log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) error {
+	passed := k6.GetStatus().ThresholdsPassed == nil || *k6.GetStatus().ThresholdsPassed
+	if err := cloud.FinishTestRun(r.cloudClient(k6), k6.TestRunID(), passed); err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Cloud test run %s was finalized successfully, passed: %v", k6.TestRunID(), passed))
+	v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunFinalized, metav1.ConditionTrue)
+	return nil
+}
+
+const (
+	// cloudFinalizeTimeout is how long the finalization of a cloud test run
+	// is retried before the test run is finished without it.
+	cloudFinalizeTimeout = 30 * time.Minute
+
+	// maxCloudFinalizeDelay limits the delay between attempts to finalize
+	// a cloud test run.
+	maxCloudFinalizeDelay = 5 * time.Minute
+)
+
+// retryCloudFinalize handles a failed finalization of the cloud test run, which
+// has been failing for the given time. It returns the delay before the next
+// attempt, which grows with that time: the cloud might be unavailable for a while.
+// After cloudFinalizeTimeout, it returns 0: the failure is reported in the status
+// and the test run is finished locally without the finalization.
+func retryCloudFinalize(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, err error, failingFor time.Duration) time.Duration {
+	if failingFor < cloudFinalizeTimeout {
+		delay := min(max(failingFor/4, r.requeue().Medium), maxCloudFinalizeDelay)
+		log.Error(err, fmt.Sprintf("Failed to finalize the test run with cloud output, retrying in %s", delay))
+
+		msg := fmt.Sprintf("Cloud test run %s is not finalized yet: %v", k6.TestRunID(), err)
+		if k6.GetStatus().Waiting != msg {
+			k6.GetStatus().Waiting = msg
+			_, _ = r.UpdateStatus(ctx, k6, log)
+		}
+		return delay
+	}
+
+	msg := fmt.Sprintf("Cloud test run %s could not be finalized within %s: %v", k6.TestRunID(), cloudFinalizeTimeout, err)
+	log.Info(msg)
+	k6.GetStatus().Waiting = ""
+	k6.GetStatus().Error = msg
+	r.recordEvent(k6, corev1.EventTypeWarning, "CloudFinalizeFailed", msg)
+	return 0
+}
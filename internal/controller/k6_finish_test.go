@@ -0,0 +1,428 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
+	"go.k6.io/k6/cloudapi"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func runnerPod(job string, created time.Time, phase corev1.PodPhase, exitCode int32) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              job + "-pod",
+			Labels:            map[string]string{"job-name": job},
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "k6",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode},
+				},
+			}},
+		},
+	}
+}
+
+func Test_runnersResult(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name                     string
+		pods                     []corev1.Pod
+		expectedPassed           bool
+		expectedThresholdsFailed bool
+		expectedSummary          string
+	}{
+		{
+			"all runners passed",
+			[]corev1.Pod{
+				runnerPod("test-1", now, corev1.PodSucceeded, 0),
+				runnerPod("test-2", now, corev1.PodSucceeded, 0),
+			},
+			true,
+			false,
+			"2/2 runners passed",
+		},
+		{
+			"one runner failed thresholds",
+			[]corev1.Pod{
+				runnerPod("test-1", now, corev1.PodSucceeded, 0),
+				runnerPod("test-2", now, corev1.PodFailed, 99),
+				runnerPod("test-3", now, corev1.PodFailed, 107),
+			},
+			false,
+			true,
+			"1/3 runners passed: test-2 failed thresholds, test-3 exited with code 107",
+		},
+		{
+			"only the latest pod of a job is considered",
+			[]corev1.Pod{
+				runnerPod("test-1", now.Add(-time.Minute), corev1.PodFailed, 107),
+				runnerPod("test-1", now, corev1.PodSucceeded, 0),
+			},
+			true,
+			false,
+			"1/1 runners passed",
+		},
+		{
+			"pods of the Indexed Job",
+			[]corev1.Pod{
+				indexedRunnerPod(0, now, corev1.PodSucceeded, 0),
+				indexedRunnerPod(1, now, corev1.PodFailed, 99),
+			},
+			false,
+			true,
+			"1/2 runners passed: test-2 failed thresholds",
+		},
+		{
+			"no finished runners",
+			[]corev1.Pod{
+				runnerPod("test-1", now, corev1.PodRunning, 0),
+			},
+			false,
+			false,
+			"0/0 runners passed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			passed, thresholdsFailed, summary := runnersResult(testCase.pods)
+			if passed != testCase.expectedPassed {
+				t.Errorf("expected passed %v, got %v", testCase.expectedPassed, passed)
+			}
+			if thresholdsFailed != testCase.expectedThresholdsFailed {
+				t.Errorf("expected thresholds failed %v, got %v", testCase.expectedThresholdsFailed, thresholdsFailed)
+			}
+			if summary != testCase.expectedSummary {
+				t.Errorf("expected summary %q, got %q", testCase.expectedSummary, summary)
+			}
+		})
+	}
+}
+
+func runnerJob(name string, condition batchv1.JobConditionType, finished time.Time) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"},
+		},
+	}
+	if len(condition) > 0 {
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type:               condition,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Time{Time: finished},
+		}}
+	}
+	return job
+}
+
+// indexedRunnerPod returns the pod of the Indexed Job with the given completion index.
+func indexedRunnerPod(index int, created time.Time, phase corev1.PodPhase, exitCode int32) corev1.Pod {
+	pod := runnerPod("test-runners", created, phase, exitCode)
+	pod.Name = fmt.Sprintf("test-runners-%d-pod", index)
+	pod.Labels["k6_cr"] = "test"
+	pod.Annotations = map[string]string{batchv1.JobCompletionIndexAnnotation: strconv.Itoa(index)}
+	return pod
+}
+
+// indexedRunnerJob returns the Indexed Job of 2 runners.
+func indexedRunnerJob(condition batchv1.JobConditionType, finished time.Time, succeeded, failed int32) *batchv1.Job {
+	job := runnerJob("test-runners", condition, finished)
+	completionMode, completions := batchv1.IndexedCompletion, int32(2)
+	job.Spec.CompletionMode = &completionMode
+	job.Spec.Completions = &completions
+	job.Status.Succeeded = succeeded
+	job.Status.Failed = failed
+	return job
+}
+
+func Test_FinishJobs(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	now := time.Now().Truncate(time.Second)
+	startTime := now.Add(-5 * time.Minute)
+
+	testCases := []struct {
+		name           string
+		jobs           []*batchv1.Job
+		allFinished    bool
+		result         v1alpha1.TestRunResult
+		completionTime time.Time
+	}{
+		{
+			name: "running",
+			jobs: []*batchv1.Job{
+				runnerJob("test-1", batchv1.JobComplete, now),
+				runnerJob("test-2", "", time.Time{}),
+			},
+		},
+		{
+			name: "succeeded",
+			jobs: []*batchv1.Job{
+				runnerJob("test-1", batchv1.JobComplete, now.Add(-time.Minute)),
+				runnerJob("test-2", batchv1.JobComplete, now),
+			},
+			allFinished:    true,
+			result:         v1alpha1.TestRunSucceeded,
+			completionTime: now,
+		},
+		{
+			name: "failed",
+			jobs: []*batchv1.Job{
+				runnerJob("test-1", batchv1.JobFailed, now),
+				runnerJob("test-2", batchv1.JobComplete, now.Add(-time.Minute)),
+			},
+			allFinished:    true,
+			result:         v1alpha1.TestRunFailed,
+			completionTime: now,
+		},
+		{
+			name: "indexed running",
+			jobs: []*batchv1.Job{indexedRunnerJob("", time.Time{}, 1, 0)},
+		},
+		{
+			name:           "indexed succeeded",
+			jobs:           []*batchv1.Job{indexedRunnerJob(batchv1.JobComplete, now, 2, 0)},
+			allFinished:    true,
+			result:         v1alpha1.TestRunSucceeded,
+			completionTime: now,
+		},
+		{
+			// the other runner is terminated together with the job
+			name:           "indexed failed",
+			jobs:           []*batchv1.Job{indexedRunnerJob(batchv1.JobFailed, now, 0, 1)},
+			allFinished:    true,
+			result:         v1alpha1.TestRunFailed,
+			completionTime: now,
+		},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+			Spec:       v1alpha1.TestRunSpec{Parallelism: 2},
+			Status:     v1alpha1.TestRunStatus{StartTime: &metav1.Time{Time: startTime}},
+		}
+
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		for _, job := range tc.jobs {
+			builder = builder.WithObjects(job)
+		}
+		r := &TestRunReconciler{Client: builder.Build(), Scheme: scheme}
+
+		if allFinished := FinishJobs(context.Background(), logr.Discard(), k6, r); allFinished != tc.allFinished {
+			t.Errorf("%s: expected all finished to be %v", tc.name, tc.allFinished)
+		}
+		if k6.Status.Result != tc.result {
+			t.Errorf("%s: expected result %q, got %q", tc.name, tc.result, k6.Status.Result)
+		}
+		if tc.allFinished && !k6.Status.CompletionTime.Time.Equal(tc.completionTime) {
+			t.Errorf("%s: expected completion time %v, got %v", tc.name, tc.completionTime, k6.Status.CompletionTime)
+		}
+		if tc.allFinished && k6.Status.Duration.Duration != tc.completionTime.Sub(startTime) {
+			t.Errorf("%s: expected duration %v, got %v", tc.name, tc.completionTime.Sub(startTime), k6.Status.Duration)
+		}
+	}
+}
+
+func Test_KeepRunners(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	startTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	pod := func(job string, phase corev1.PodPhase, ready corev1.ConditionStatus, transition time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      job + "-pod",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid", "job-name": job},
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             ready,
+					LastTransitionTime: metav1.NewTime(transition),
+				}},
+			},
+		}
+	}
+	// k6 has exited: the REST API checked by the readiness probe is gone
+	exited := func(job string) *corev1.Pod {
+		return pod(job, corev1.PodRunning, corev1.ConditionFalse, startTime.Add(time.Minute))
+	}
+	keep := &metav1.Duration{Duration: 10 * time.Minute}
+
+	testCases := []struct {
+		name string
+		keep *metav1.Duration
+		pods []client.Object
+		kept bool
+	}{
+		{"runners are not kept", nil, []client.Object{exited("test-1"), exited("test-2")}, false},
+		{"runners are kept", keep, []client.Object{exited("test-1"), exited("test-2")}, true},
+		{"terminated pods", keep, []client.Object{
+			pod("test-1", corev1.PodSucceeded, corev1.ConditionFalse, startTime.Add(time.Minute)),
+			pod("test-2", corev1.PodFailed, corev1.ConditionFalse, startTime.Add(time.Minute)),
+		}, true},
+		{"runner is running", keep, []client.Object{
+			exited("test-1"),
+			pod("test-2", corev1.PodRunning, corev1.ConditionTrue, startTime.Add(-time.Minute)),
+		}, false},
+		{"runner was never ready since the start", keep, []client.Object{
+			exited("test-1"),
+			pod("test-2", corev1.PodRunning, corev1.ConditionFalse, startTime.Add(-time.Minute)),
+		}, false},
+		{"runner is missing", keep, []client.Object{exited("test-1")}, false},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+			Spec:       v1alpha1.TestRunSpec{Parallelism: 2, KeepRunnersAfterFinish: tc.keep},
+			Status:     v1alpha1.TestRunStatus{Stage: "started", StartTime: &metav1.Time{Time: startTime}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithObjects(tc.pods...).WithStatusSubresource(k6).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		before := time.Now()
+		KeepRunners(context.Background(), logr.Discard(), k6, r)
+
+		if !tc.kept {
+			if k6.Status.RunnersKeptUntil != nil {
+				t.Errorf("%s: expected no runners kept, got until %v", tc.name, k6.Status.RunnersKeptUntil)
+			}
+			continue
+		}
+		until := k6.Status.RunnersKeptUntil
+		if until == nil || until.Time.Before(before.Add(tc.keep.Duration).Truncate(time.Second)) {
+			t.Fatalf("%s: expected runners kept for %v, got until %v", tc.name, tc.keep.Duration, until)
+		}
+
+		// the time is recorded only once
+		KeepRunners(context.Background(), logr.Discard(), k6, r)
+		if !k6.Status.RunnersKeptUntil.Equal(until) {
+			t.Errorf("%s: expected runners kept until %v, got %v", tc.name, until, k6.Status.RunnersKeptUntil)
+		}
+		if maxDurationExceeded(k6) {
+			t.Errorf("%s: expected kept runners not to exceed max duration", tc.name)
+		}
+	}
+}
+
+func Test_FinalizeCloudTestRun(t *testing.T) {
+	t.Parallel()
+
+	var (
+		available    atomic.Bool
+		resultStatus atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !available.Load() {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			ResultStatus cloudapi.ResultStatus `json:"result_status"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		resultStatus.Store(int32(body.ResultStatus))
+	}))
+	defer srv.Close()
+
+	passed := false
+	k6 := &v1alpha1.TestRun{
+		Status: v1alpha1.TestRunStatus{TestRunID: "123", ThresholdsPassed: &passed},
+	}
+	v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunFinalized, metav1.ConditionFalse)
+	r := &TestRunReconciler{}
+	r.cloudClients.Store(k6.NamespacedName(), &testRunCloudClient{client: cloud.NewClient(logr.Discard(), "token", srv.URL)})
+
+	// the cloud is unavailable: the test run stays unfinalized
+	if err := FinalizeCloudTestRun(logr.Discard(), k6, r); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !v1alpha1.IsFalse(k6, v1alpha1.CloudTestRunFinalized) {
+		t.Fatal("expected the test run not to be finalized")
+	}
+
+	available.Store(true)
+	if err := FinalizeCloudTestRun(logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v1alpha1.IsTrue(k6, v1alpha1.CloudTestRunFinalized) {
+		t.Error("expected the test run to be finalized")
+	}
+	if cloudapi.ResultStatus(resultStatus.Load()) != cloudapi.ResultStatusFailed {
+		t.Errorf("expected a failed result, got %v", resultStatus.Load())
+	}
+}
+
+func Test_retryCloudFinalize(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status:     v1alpha1.TestRunStatus{Stage: "stopped", TestRunID: "123"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	err := fmt.Errorf("cloud is unavailable")
+
+	var previous time.Duration
+	for _, failingFor := range []time.Duration{0, time.Minute, 10 * time.Minute, 29 * time.Minute} {
+		delay := retryCloudFinalize(context.Background(), logr.Discard(), k6, r, err, failingFor)
+		if delay < previous || delay > maxCloudFinalizeDelay {
+			t.Errorf("expected a growing delay up to %s after %s, got %s", maxCloudFinalizeDelay, failingFor, delay)
+		}
+		previous = delay
+	}
+	if len(k6.Status.Waiting) == 0 || len(k6.Status.Error) > 0 {
+		t.Errorf("expected the failure in waiting while retrying, got waiting %q and error %q", k6.Status.Waiting, k6.Status.Error)
+	}
+
+	if delay := retryCloudFinalize(context.Background(), logr.Discard(), k6, r, err, cloudFinalizeTimeout); delay != 0 {
+		t.Errorf("expected to give up after %s, got delay %s", cloudFinalizeTimeout, delay)
+	}
+	if len(k6.Status.Waiting) > 0 || len(k6.Status.Error) == 0 {
+		t.Errorf("expected the failure in error after the timeout, got waiting %q and error %q", k6.Status.Waiting, k6.Status.Error)
+	}
+}
@@ -14,12 +14,13 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // InitializeJobs creates jobs that will run initial checks for distributed test if any are necessary
 func InitializeJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (res ctrl.Result, err error) {
 	// initializer is a quick job so check in frequently
-	res = ctrl.Result{RequeueAfter: time.Second * 5}
+	res = ctrl.Result{RequeueAfter: r.requeue().Medium}
 
 	// validation has already happened, so error here can be ignored
 	cli, _ := types.ParseCLI(k6.GetSpec().Arguments)
@@ -49,7 +50,7 @@ func RunValidations(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 	res ctrl.Result, ready bool, err error,
 ) {
 	// initializer is a quick job so check in frequently
-	res = ctrl.Result{RequeueAfter: time.Second * 5}
+	res = ctrl.Result{RequeueAfter: r.requeue().Medium}
 
 	// validation has already happened, so error here can be ignored
 	cli, _ := types.ParseCLI(k6.GetSpec().Arguments)
@@ -63,10 +64,11 @@ func RunValidations(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 			events := cloud.ErrorEvent(cloud.K6OperatorStartError).
 				WithDetail(fmt.Sprintf("Failed to inspect the test script: %v", err)).
 				WithAbort()
-			cloud.SendTestRunEvents(r.k6CloudClient, k6.TestRunID(), log, events)
+			r.sendCloudEvents(ctx, log, k6, events)
 		} else {
 			// if there is any error, we have to reflect it on the TestRun manifest
 			k6.GetStatus().Stage = "error"
+			k6.GetStatus().Error = err.Error()
 			if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
 				return ctrl.Result{}, ready, err
 			}
@@ -82,6 +84,7 @@ func RunValidations(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 	}
 
 	log.Info(fmt.Sprintf("k6 inspect: %+v", inspectOutput))
+	setExecutionRequirements(k6, inspectOutput)
 
 	if int32(inspectOutput.MaxVUs) < k6.GetSpec().Parallelism {
 		err = fmt.Errorf("number of instances > number of VUs")
@@ -93,6 +96,8 @@ func RunValidations(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 			"parallelism", k6.GetSpec().Parallelism)
 
 		k6.GetStatus().Stage = "error"
+		k6.GetStatus().Error = fmt.Sprintf("parallelism %d is larger than the %d VUs required by the script: some runners would be idle",
+			k6.GetSpec().Parallelism, inspectOutput.MaxVUs)
 
 		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
 			return ctrl.Result{}, ready, err
@@ -102,7 +107,8 @@ func RunValidations(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 		return ctrl.Result{}, ready, nil
 	}
 
-	if cli.HasCloudOut {
+	// With CloudOutputToken, runners stream to the cloud on their own.
+	if cli.HasCloudOut && k6.GetSpec().CloudOutputToken == nil {
 		v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRun, metav1.ConditionTrue)
 
 		if v1alpha1.IsUnknown(k6, v1alpha1.CloudTestRunCreated) {
@@ -124,42 +130,48 @@ func RunValidations(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 	return res, ready, nil
 }
 
+// setExecutionRequirements records the requirements of the script in the status.
+func setExecutionRequirements(k6 *v1alpha1.TestRun, inspectOutput cloud.InspectOutput) {
+	k6.GetStatus().MaxVUs = int64(inspectOutput.MaxVUs)
+	if inspectOutput.TotalDuration.Valid {
+		k6.GetStatus().TotalDuration = &metav1.Duration{Duration: time.Duration(inspectOutput.TotalDuration.Duration)}
+	}
+}
+
 // SetupCloudTest inspects the output of initializer and creates a new
 // test run. It is meant to be used only in cloud output mode.
 func SetupCloudTest(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (res ctrl.Result, err error) {
-	res = ctrl.Result{RequeueAfter: time.Second * 5}
+	res = ctrl.Result{RequeueAfter: r.requeue().Medium}
 
 	inspectOutput, inspectReady, err := inspectTestRun(ctx, log, k6, r.Client)
 	if err != nil {
-		// This *shouldn't* fail since it was already done once. Don't requeue.
-		// Alternatively: store inspect options in TestRun Status? Get rid off reading logs?
+		// This *shouldn't* fail since the output is kept in the status
+		// by RunValidations. Don't requeue.
 		return ctrl.Result{}, nil
 	}
 	if !inspectReady {
 		return res, nil
 	}
 
-	tokenInfo := cloud.NewTokenInfo(k6.GetSpec().Token, k6.NamespacedName().Namespace)
+	tokenInfo := newTokenInfo(k6)
 	err = tokenInfo.Load(ctx, log, r.Client)
 	if err != nil {
 		// An error here means a very likely mis-configuration of the token.
-		// Consider updating status to error to let a user know quicker?
-		log.Error(err, "A problem while getting token.")
-		return ctrl.Result{}, nil
+		return failOnToken(ctx, log, k6, r, err)
 	}
 	if !tokenInfo.Ready {
 		return res, nil
 	}
 
-	host := getEnvVar(k6.GetSpec().Runner.Env, "K6_CLOUD_HOST")
+	host := cloudHost(k6)
 
 	if v1alpha1.IsFalse(k6, v1alpha1.CloudTestRunCreated) {
 
 		// If CloudTestRunCreated has just been updated, wait for a bit before
 		// acting, to avoid race condition between different reconcile loops.
 		t, _ := v1alpha1.LastUpdate(k6, v1alpha1.CloudTestRunCreated)
-		if time.Since(t) < 5*time.Second {
-			return ctrl.Result{RequeueAfter: time.Second * 2}, nil
+		if time.Since(t) < conditionSettleDelay {
+			return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
 		}
 
 		if len(inspectOutput.TestName()) < 1 {
@@ -176,10 +188,16 @@ func SetupCloudTest(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 			log = log.WithValues("testRunId", testRunData.ReferenceID)
 			log.Info(fmt.Sprintf("Created cloud test run: %s", testRunData.ReferenceID))
 
+			aggregationVars := cloud.EncodeAggregationConfig(testRunData.ConfigOverride)
+			if err := persistCloudTestRun(ctx, k6, r, testRunData.ReferenceID, aggregationVars); err != nil {
+				// the status update below might still succeed
+				log.Error(err, "Failed to persist the ID of the cloud test run")
+			}
+
 			k6.GetStatus().TestRunID = testRunData.ReferenceID
 			v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunCreated, metav1.ConditionTrue)
 
-			k6.GetStatus().AggregationVars = cloud.EncodeAggregationConfig(testRunData.ConfigOverride)
+			k6.GetStatus().AggregationVars = aggregationVars
 
 			_, err := r.UpdateStatus(ctx, k6, log)
 			if err != nil {
@@ -190,3 +208,38 @@ func SetupCloudTest(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun,
 
 	return ctrl.Result{}, nil
 }
+
+// persistCloudTestRun keeps the ID and aggregation config of the created cloud test run
+// in annotations of the TestRun. Unlike the status, they are written right away, so
+// that the ID is not lost if the operator restarts before the status is updated.
+// A copy of k6 is patched: the response mustn't overwrite the status in progress.
+func persistCloudTestRun(ctx context.Context, k6 *v1alpha1.TestRun, r *TestRunReconciler, testRunID, aggregationVars string) error {
+	patched := k6.DeepCopy()
+	patch := client.MergeFrom(k6)
+	if patched.Annotations == nil {
+		patched.Annotations = make(map[string]string)
+	}
+	patched.Annotations[v1alpha1.CloudTestRunIDAnnotation] = testRunID
+	patched.Annotations[v1alpha1.CloudAggregationVarsAnnotation] = aggregationVars
+
+	if err := r.Patch(ctx, patched, patch); err != nil {
+		return err
+	}
+	k6.Annotations = patched.Annotations
+	return nil
+}
+
+// restoreCloudTestRun restores the cloud test run in the status from the annotations
+// of the TestRun if it was created but the status was not updated. It returns true
+// if the status was changed.
+func restoreCloudTestRun(k6 *v1alpha1.TestRun) bool {
+	testRunID := k6.GetAnnotations()[v1alpha1.CloudTestRunIDAnnotation]
+	if len(testRunID) == 0 || len(k6.GetStatus().TestRunID) > 0 {
+		return false
+	}
+
+	k6.GetStatus().TestRunID = testRunID
+	k6.GetStatus().AggregationVars = k6.GetAnnotations()[v1alpha1.CloudAggregationVarsAnnotation]
+	v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunCreated, metav1.ConditionTrue)
+	return true
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_setExecutionRequirements(t *testing.T) {
+	t.Parallel()
+
+	var inspectOutput cloud.InspectOutput
+	if err := json.Unmarshal([]byte(`{"maxVUs":50,"totalDuration":"1m30s"}`), &inspectOutput); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	k6 := &v1alpha1.TestRun{}
+	setExecutionRequirements(k6, inspectOutput)
+
+	if k6.Status.MaxVUs != 50 {
+		t.Errorf("expected 50 max VUs, got %d", k6.Status.MaxVUs)
+	}
+	if k6.Status.TotalDuration == nil || k6.Status.TotalDuration.Duration != 90*time.Second {
+		t.Errorf("expected total duration of 1m30s, got %v", k6.Status.TotalDuration)
+	}
+
+	// the recorded requirements are kept on later updates
+	proposed := v1alpha1.TestRunStatus{MaxVUs: 10}
+	if k6.Status.SetIfNewer(proposed); k6.Status.MaxVUs != 50 {
+		t.Errorf("expected max VUs to be kept, got %d", k6.Status.MaxVUs)
+	}
+}
+
+func Test_inspectTestRunFromStatus(t *testing.T) {
+	t.Parallel()
+
+	// the initializer pod is gone, but its output was kept
+	c := fake.NewClientBuilder().Build()
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status:     v1alpha1.TestRunStatus{Inspection: `{"maxVUs":50,"totalDuration":"1m30s"}`},
+	}
+
+	inspectOutput, ready, err := inspectTestRun(context.Background(), logr.Discard(), k6, c)
+	if err != nil || !ready {
+		t.Fatalf("expected the inspection to be ready, got %v and error: %v", ready, err)
+	}
+	if inspectOutput.MaxVUs != 50 {
+		t.Errorf("expected 50 max VUs, got %d", inspectOutput.MaxVUs)
+	}
+}
+
+func Test_restoreCloudTestRun(t *testing.T) {
+	t.Parallel()
+
+	k6 := &v1alpha1.TestRun{}
+	if restoreCloudTestRun(k6) {
+		t.Error("expected nothing to restore without annotations")
+	}
+
+	k6.Annotations = map[string]string{
+		v1alpha1.CloudTestRunIDAnnotation:       "123",
+		v1alpha1.CloudAggregationVarsAnnotation: "2|5s|3s|10s|10",
+	}
+	if k6.TestRunID() != "123" {
+		t.Errorf("expected test run ID from the annotation, got %q", k6.TestRunID())
+	}
+
+	v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunCreated, metav1.ConditionFalse)
+	if !restoreCloudTestRun(k6) {
+		t.Fatal("expected the cloud test run to be restored")
+	}
+	if k6.Status.TestRunID != "123" || k6.Status.AggregationVars != "2|5s|3s|10s|10" {
+		t.Errorf("unexpected restored status: ID %q, aggregation vars %q", k6.Status.TestRunID, k6.Status.AggregationVars)
+	}
+	if !v1alpha1.IsTrue(k6, v1alpha1.CloudTestRunCreated) {
+		t.Error("expected the cloud test run to be marked as created")
+	}
+
+	// the status is not overwritten once it's set
+	k6.Annotations[v1alpha1.CloudTestRunIDAnnotation] = "456"
+	if restoreCloudTestRun(k6) || k6.Status.TestRunID != "123" {
+		t.Errorf("expected the status to be kept, got ID %q", k6.Status.TestRunID)
+	}
+}
+
+func Test_persistCloudTestRun(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Status:     v1alpha1.TestRunStatus{Stage: "initialization"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+	// the status in progress isn't stored yet
+	k6.Status.TestRunID = "123"
+	if err := persistCloudTestRun(context.Background(), k6, r, "123", "2|5s|3s|10s|10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if k6.Status.TestRunID != "123" {
+		t.Errorf("expected the status in progress to be kept, got ID %q", k6.Status.TestRunID)
+	}
+	if k6.Annotations[v1alpha1.CloudTestRunIDAnnotation] != "123" {
+		t.Errorf("expected the annotations to be set, got %v", k6.Annotations)
+	}
+
+	stored := &v1alpha1.TestRun{}
+	if err := c.Get(context.Background(), k6.NamespacedName(), stored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.Annotations[v1alpha1.CloudAggregationVarsAnnotation] != "2|5s|3s|10s|10" {
+		t.Errorf("expected the annotations to be stored, got %v", stored.Annotations)
+	}
+}
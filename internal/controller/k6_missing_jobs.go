@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// canLeaveOutRunners shows whether the test can start without the runners
+// which are not ready, given the number of ready ones.
+func canLeaveOutRunners(k6 *v1alpha1.TestRun, ready int) bool {
+	minReady := k6.GetSpec().MinReadyRunners
+	return minReady > 0 && !isCloudTestRun(k6) && ready >= int(minReady)
+}
+
+// runningRunners returns the names of the runner Jobs with a running pod.
+func runningRunners(pods []corev1.Pod) map[string]bool {
+	running := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(&pod); owner != nil && pod.Status.Phase == corev1.PodRunning {
+			running[owner.Name] = true
+		}
+	}
+	return running
+}
+
+// LeaveOutUnreadyRunners records the runners which are not ready in the
+// status and deletes them together with their Services, with MinReadyRunners.
+// ready contains the names of the ready runner Jobs.
+// The test is then started on the rest of the runners.
+func LeaveOutUnreadyRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, ready map[string]bool) (ctrl.Result, error) {
+	jl := &batchv1.JobList{}
+	if err := r.listRunners(ctx, k6, jl); err != nil {
+		log.Error(err, "Could not list jobs")
+		return ctrl.Result{}, err
+	}
+
+	if len(k6.GetStatus().MissingRunners) == 0 {
+		for _, job := range jl.Items {
+			if !ready[job.Name] {
+				k6.GetStatus().MissingRunners = append(k6.GetStatus().MissingRunners, job.Name)
+			}
+		}
+
+		// the segments of the missing runners are not executed by anyone
+		msg := fmt.Sprintf("%d/%d runners are not ready, starting the test without them and their share of the load: %s",
+			len(k6.GetStatus().MissingRunners), k6.GetSpec().Parallelism, strings.Join(k6.GetStatus().MissingRunners, ", "))
+		log.Info(msg)
+
+		// The runners are recorded before the deletion, so that
+		// the rest of the test doesn't wait for them anymore.
+		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recordEvent(k6, corev1.EventTypeWarning, "RunnersMissing", msg)
+	}
+
+	sl := &corev1.ServiceList{}
+	if err := r.listRunners(ctx, k6, sl); err != nil {
+		log.Error(err, "Could not list services")
+		return ctrl.Result{}, err
+	}
+
+	for i := range jl.Items {
+		job := &jl.Items[i]
+		if !k6.IsMissingRunner(job.Name) {
+			continue
+		}
+
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete runner job %s", job.Name))
+			return ctrl.Result{}, err
+		}
+
+		for j := range sl.Items {
+			if sl.Items[j].Spec.Selector["job-name"] != job.Name {
+				continue
+			}
+			if err := r.Delete(ctx, &sl.Items[j]); client.IgnoreNotFound(err) != nil {
+				log.Error(err, fmt.Sprintf("Failed to delete runner service %s", sl.Items[j].Name))
+				return ctrl.Result{}, err
+			}
+		}
+		log.Info(fmt.Sprintf("Deleted runner job %s which was not ready", job.Name))
+	}
+
+	return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_LeaveOutUnreadyRunners(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism:     3,
+			MinReadyRunners: 2,
+		},
+		Status: v1alpha1.TestRunStatus{Stage: "created", Parallelism: 3},
+	}
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+
+	objects := []client.Object{k6}
+	var pods []corev1.Pod
+	for i := 1; i <= 3; i++ {
+		name := fmt.Sprintf("test-%d", i)
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), Labels: runnerLabels}}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("test-service-%d", i), Namespace: "default", Labels: runnerLabels},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"job-name": name}},
+		}
+
+		// the last runner cannot be scheduled
+		phase := corev1.PodRunning
+		if i == 3 {
+			phase = corev1.PodPending
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-abcde", Namespace: "default", Labels: runnerLabels},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if err := ctrl.SetControllerReference(job, pod, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		objects = append(objects, job, service, pod)
+		pods = append(pods, *pod)
+	}
+
+	if !canLeaveOutRunners(k6, 2) {
+		t.Fatal("expected to start with 2/3 ready runners")
+	}
+	if canLeaveOutRunners(k6, 1) {
+		t.Fatal("expected not to start with 1/3 ready runners")
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	if _, err := LeaveOutUnreadyRunners(ctx, logr.Discard(), k6, r, runningRunners(pods)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if missing := updated.Status.MissingRunners; len(missing) != 1 || missing[0] != "test-3" {
+		t.Fatalf("expected test-3 to be missing, got %v", missing)
+	}
+	if updated.Runners() != 2 {
+		t.Errorf("expected 2 runners in the test, got %d", updated.Runners())
+	}
+
+	key := client.ObjectKey{Namespace: "default"}
+	for name, obj := range map[string]client.Object{"test-3": &batchv1.Job{}, "test-service-3": &corev1.Service{}} {
+		key.Name = name
+		if err := c.Get(ctx, key, obj); !k8sErrors.IsNotFound(err) {
+			t.Errorf("expected %s to be deleted, got error: %v", name, err)
+		}
+	}
+	for name, obj := range map[string]client.Object{"test-2": &batchv1.Job{}, "test-service-2": &corev1.Service{}} {
+		key.Name = name
+		if err := c.Get(ctx, key, obj); err != nil {
+			t.Errorf("expected %s to be kept, got error: %v", name, err)
+		}
+	}
+}
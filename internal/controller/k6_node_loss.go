@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podNodeLoss returns the message of the loss if the pod was lost with its
+// node: its node became unreachable or was shut down. Such a pod might still
+// look running, as its status cannot be updated by the kubelet anymore.
+func podNodeLoss(pod *corev1.Pod) (string, bool) {
+	switch pod.Status.Reason {
+	case "NodeLost", "NodeShutdown":
+		return pod.Status.Message, true
+	case "Evicted":
+		// the kubelet evicts pods on node pressure, while the node is still there
+		return "", false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type != corev1.DisruptionTarget || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		// DeletionByTaintManager is set on pods of unreachable and not ready nodes,
+		// DeletionByPodGC on pods of deleted nodes.
+		switch cond.Reason {
+		case "DeletionByTaintManager", "DeletionByPodGC", corev1.PodReasonTerminationByKubelet:
+			return fmt.Sprintf("%s: %s", cond.Reason, cond.Message), true
+		}
+	}
+	return "", false
+}
+
+// replacedRunner returns the message of the loss if a runner pod was lost
+// without being seen by FailedNodes, e.g. because PodGC has already deleted it:
+// the Job controller then counts it as failed and creates a new pod, which
+// would execute the segment of the runner from the beginning.
+func replacedRunner(k6 *v1alpha1.TestRun, pods []corev1.Pod, jobs []batchv1.Job) (string, bool) {
+	if startTime := k6.GetStatus().StartTime; startTime != nil {
+		for i := range pods {
+			if pods[i].CreationTimestamp.After(startTime.Time) {
+				return fmt.Sprintf("Runner pod %s was created after the start of the test, replacing a lost pod", pods[i].Name), true
+			}
+		}
+	}
+	for i := range jobs {
+		// a failed pod without a new one is a failure of the job instead
+		if jobs[i].Status.Failed > 0 && jobs[i].Status.Active > 0 {
+			return fmt.Sprintf("Runner job %s has %d failed pods and is running a new one", jobs[i].Name, jobs[i].Status.Failed), true
+		}
+	}
+	return "", false
+}
+
+// FailedNodes checks if any of the runner pods was lost with its node during
+// the test, with the Fail NodeLossPolicy. A lost pod might have been deleted
+// already, so a runner job with failed pods or a runner pod created after
+// the start is treated as a loss too. In that case, the other runners
+// are stopped, since their results wouldn't reflect the intended load, and
+// the test run is moved to the error stage.
+func FailedNodes(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (failed bool, err error) {
+	if len(k6.GetStatus().TestRunID) > 0 {
+		log = log.WithValues("testRunId", k6.GetStatus().TestRunID)
+	}
+
+	pl := &corev1.PodList{}
+	if err = r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		return false, err
+	}
+	jl := &batchv1.JobList{}
+	if err = r.listRunners(ctx, k6, jl); err != nil {
+		log.Error(err, "Could not list jobs")
+		return false, err
+	}
+
+	var msg string
+	for i := range pl.Items {
+		pod := &pl.Items[i]
+		if lost, ok := podNodeLoss(pod); ok {
+			msg = fmt.Sprintf("Runner pod %s was lost with node %s: %s", pod.Name, pod.Spec.NodeName, lost)
+			break
+		}
+	}
+	if len(msg) == 0 {
+		var ok bool
+		if msg, ok = replacedRunner(k6, pl.Items, jl.Items); !ok {
+			return false, nil
+		}
+	}
+
+	log.Info(msg)
+	k6.GetStatus().Error = msg
+	r.recordEvent(k6, corev1.EventTypeWarning, "RunnerNodeLost", msg)
+
+	if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) {
+		events := cloud.ErrorEvent(cloud.K6OperatorRunnerError).
+			WithDetail(msg).
+			WithAbort()
+		r.sendCloudEvents(ctx, log, k6, events)
+	}
+
+	var hostnames []string
+	if k6.Standalone() {
+		hostnames, err = r.runnerPodIPs(ctx, k6)
+	} else {
+		hostnames, err = r.hostnames(ctx, log, k6)
+	}
+	if err == nil {
+		_, err = StopK6FromOperators(ctx, log, k6, hostnames, r)
+	}
+	if err != nil {
+		// the test run fails anyway: the runners are left to their jobs
+		log.Error(err, "Failed to stop the remaining runners")
+	}
+
+	log.Info("Changing stage of TestRun status to error")
+	k6.GetStatus().Stage = "error"
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionFalse)
+
+	_, err = r.UpdateStatus(ctx, k6, log)
+	return true, err
+}
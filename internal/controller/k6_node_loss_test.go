@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_runnerDisruption(t *testing.T) {
+	t.Parallel()
+
+	unreachable := corev1.PodStatus{
+		// the kubelet of the node cannot report the pod as failed
+		Phase: corev1.PodRunning,
+		Conditions: []corev1.PodCondition{{
+			Type:    corev1.DisruptionTarget,
+			Status:  corev1.ConditionTrue,
+			Reason:  "DeletionByTaintManager",
+			Message: "Taint manager: deleting due to NoExecute taint",
+		}},
+	}
+	evicted := corev1.PodStatus{
+		Phase:  corev1.PodFailed,
+		Reason: "Evicted",
+		Conditions: []corev1.PodCondition{{
+			Type:   corev1.DisruptionTarget,
+			Status: corev1.ConditionTrue,
+			Reason: corev1.PodReasonTerminationByKubelet,
+		}},
+	}
+	crashed := corev1.PodStatus{Phase: corev1.PodFailed}
+
+	testCases := []struct {
+		name      string
+		spec      v1alpha1.TestRunSpec
+		status    corev1.PodStatus
+		disrupted bool
+	}{
+		{"node loss with Rejoin", v1alpha1.TestRunSpec{NodeLossPolicy: v1alpha1.RejoinOnNodeLoss}, unreachable, true},
+		{"node loss with Fail", v1alpha1.TestRunSpec{NodeLossPolicy: v1alpha1.FailOnNodeLoss}, unreachable, false},
+		{"node loss by default", v1alpha1.TestRunSpec{}, unreachable, false},
+		{"node loss with TolerateRunnerEviction", v1alpha1.TestRunSpec{TolerateRunnerEviction: true}, unreachable, false},
+		{"eviction with Rejoin", v1alpha1.TestRunSpec{NodeLossPolicy: v1alpha1.RejoinOnNodeLoss}, evicted, false},
+		{"eviction with TolerateRunnerEviction", v1alpha1.TestRunSpec{TolerateRunnerEviction: true}, evicted, true},
+		{"crash with Rejoin", v1alpha1.TestRunSpec{NodeLossPolicy: v1alpha1.RejoinOnNodeLoss}, crashed, false},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{Spec: tc.spec}
+		if _, disrupted := runnerDisruption(k6, &corev1.Pod{Status: tc.status}); disrupted != tc.disrupted {
+			t.Errorf("%s: expected disruption to be %v", tc.name, tc.disrupted)
+		}
+	}
+}
+
+func Test_FailedNodes(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 2, NodeLossPolicy: v1alpha1.FailOnNodeLoss},
+		Status:     v1alpha1.TestRunStatus{Stage: "started", Parallelism: 2},
+	}
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionTrue)
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+
+	runningPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1-abcde", Namespace: "default", Labels: runnerLabels},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	lostPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-2-abcde", Namespace: "default", Labels: runnerLabels},
+		Spec:       corev1.PodSpec{NodeName: "node-2"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.DisruptionTarget,
+				Status:  corev1.ConditionTrue,
+				Reason:  "DeletionByPodGC",
+				Message: "PodGC: node no longer exists",
+			}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, runningPod).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	if failed, err := FailedNodes(ctx, logr.Discard(), k6, r); err != nil || failed {
+		t.Fatalf("expected no failure, got %v and error: %v", failed, err)
+	}
+
+	if err := c.Create(ctx, lostPod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failed, err := FailedNodes(ctx, logr.Discard(), k6, r); err != nil || !failed {
+		t.Fatalf("expected a failure, got %v and error: %v", failed, err)
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.Stage != "error" || !strings.Contains(updated.Status.Error, "node-2") {
+		t.Errorf("expected the error stage with the lost node, got stage %q and error %q", updated.Status.Stage, updated.Status.Error)
+	}
+	if v1alpha1.IsTrue(updated, v1alpha1.TestRunRunning) {
+		t.Error("expected the test run not to be running")
+	}
+}
+
+func Test_replacedRunner(t *testing.T) {
+	t.Parallel()
+
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	pod := func(created time.Time) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-1-abcde", CreationTimestamp: metav1.NewTime(created)}}
+	}
+	job := func(failed, active int32) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Status:     batchv1.JobStatus{Failed: failed, Active: active},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		pods     []corev1.Pod
+		jobs     []batchv1.Job
+		replaced bool
+	}{
+		{"running runner", []corev1.Pod{pod(start.Add(-time.Minute))}, []batchv1.Job{job(0, 1)}, false},
+		// PodGC has deleted the lost pod and the Job controller has created a new one
+		{"pod created after the start", []corev1.Pod{pod(start.Add(time.Second))}, []batchv1.Job{job(0, 1)}, true},
+		{"failed pod replaced", []corev1.Pod{pod(start.Add(-time.Minute))}, []batchv1.Job{job(1, 1)}, true},
+		{"failed pod without a replacement", nil, []batchv1.Job{job(1, 0)}, false},
+	}
+
+	k6 := &v1alpha1.TestRun{Status: v1alpha1.TestRunStatus{StartTime: &start}}
+	for _, tc := range testCases {
+		if _, replaced := replacedRunner(k6, tc.pods, tc.jobs); replaced != tc.replaced {
+			t.Errorf("%s: expected replacement to be %v", tc.name, tc.replaced)
+		}
+	}
+}
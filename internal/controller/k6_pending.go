@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// isActive shows whether the runners of the TestRun are counted towards MaxActiveTestRuns.
+func isActive(k6 *v1alpha1.TestRun) bool {
+	stage := k6.GetStatus().Stage
+	return stage == "creating" || stage == "created" || stage == "started"
+}
+
+// admitted checks whether the runners of the TestRun can be created without
+// exceeding MaxActiveTestRuns. Pending TestRuns are admitted in the order
+// of their creation. If the TestRun is not admitted, the returned message
+// describes what it waits for.
+func (r *TestRunReconciler) admitted(ctx context.Context, k6 *v1alpha1.TestRun) (bool, string, error) {
+	if r.MaxActiveTestRuns <= 0 {
+		return true, "", nil
+	}
+
+	list := &v1alpha1.TestRunList{}
+	if err := r.List(ctx, list); err != nil {
+		return false, "", err
+	}
+
+	var (
+		active  int
+		pending = []*v1alpha1.TestRun{k6}
+	)
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.UID == k6.UID {
+			continue
+		}
+		if isActive(other) {
+			active++
+		} else if other.GetStatus().Stage == "pending" {
+			pending = append(pending, other)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if !pending[i].CreationTimestamp.Equal(&pending[j].CreationTimestamp) {
+			return pending[i].CreationTimestamp.Before(&pending[j].CreationTimestamp)
+		}
+		return pending[i].NamespacedName().String() < pending[j].NamespacedName().String()
+	})
+
+	var position int
+	for position = range pending {
+		if pending[position].UID == k6.UID {
+			break
+		}
+	}
+
+	if active+position < r.MaxActiveTestRuns {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%d TestRuns are running, at most %d are allowed: %d TestRuns are ahead in the queue",
+		active, r.MaxActiveTestRuns, position), nil
+}
+
+// AdmitJobs creates the runners if the TestRun is admitted by MaxActiveTestRuns;
+// otherwise, the TestRun waits in the pending stage.
+func AdmitJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	ok, reason, err := r.admitted(ctx, k6)
+	if err != nil {
+		log.Error(err, "Could not list TestRuns")
+		return ctrl.Result{}, err
+	}
+
+	if !ok {
+		log.Info(fmt.Sprintf("Waiting for other TestRuns: %s", reason))
+		if k6.GetStatus().Stage != "pending" {
+			log.Info("Changing stage of TestRun status to pending")
+			k6.GetStatus().Stage = "pending"
+		}
+		k6.GetStatus().Waiting = reason
+		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: r.requeue().Medium}, nil
+	}
+
+	// cleared together with the change of stage
+	k6.GetStatus().Waiting = ""
+	return CreateJobs(ctx, log, k6, r)
+}
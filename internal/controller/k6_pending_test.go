@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_admitted(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	created := time.Now()
+	newTestRun := func(name string, stage v1alpha1.Stage) *v1alpha1.TestRun {
+		created = created.Add(time.Second)
+		return &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               types.UID(name),
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Status: v1alpha1.TestRunStatus{Stage: stage},
+		}
+	}
+
+	running := newTestRun("running", "started")
+	first := newTestRun("first", "pending")
+	second := newTestRun("second", "pending")
+	newest := newTestRun("newest", "initialized")
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(running, first, second, newest).
+		WithStatusSubresource(running).Build()
+	r := &TestRunReconciler{Client: c, MaxActiveTestRuns: 2}
+	ctx := context.Background()
+
+	testCases := []struct {
+		k6       *v1alpha1.TestRun
+		admitted bool
+	}{
+		{first, true},
+		{second, false},
+		{newest, false},
+	}
+	for _, tc := range testCases {
+		ok, reason, err := r.admitted(ctx, tc.k6)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.k6.Name, err)
+		}
+		if ok != tc.admitted {
+			t.Errorf("%s: expected admitted to be %v, got %v", tc.k6.Name, tc.admitted, ok)
+		}
+		if !ok && len(reason) == 0 {
+			t.Errorf("%s: expected a reason to wait", tc.k6.Name)
+		}
+	}
+
+	// the next pending TestRun is admitted once the running one finishes
+	running.Status.Stage = "finished"
+	if err := c.Status().Update(ctx, running); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _, _ := r.admitted(ctx, second); !ok {
+		t.Error("expected the second TestRun to be admitted")
+	}
+	if ok, _, _ := r.admitted(ctx, newest); ok {
+		t.Error("expected the newest TestRun to wait for the pending ones")
+	}
+
+	r.MaxActiveTestRuns = 0
+	if ok, _, _ := r.admitted(ctx, newest); !ok {
+		t.Error("expected all TestRuns to be admitted without a limit")
+	}
+}
+
+func Test_AdmitJobs_Pending(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	running := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default", UID: "running"},
+		Status:     v1alpha1.TestRunStatus{Stage: "started"},
+	}
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test"},
+		Status:     v1alpha1.TestRunStatus{Stage: "initialized"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(running, k6).
+		WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, MaxActiveTestRuns: 1}
+	ctx := context.Background()
+
+	res, err := AdmitJobs(ctx, logr.Discard(), k6, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.RequeueAfter == 0 {
+		t.Error("expected the pending TestRun to be requeued")
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.Stage != "pending" || len(updated.Status.Waiting) == 0 {
+		t.Errorf("expected the TestRun to be pending with a reason, got stage %q and %q", updated.Status.Stage, updated.Status.Waiting)
+	}
+}
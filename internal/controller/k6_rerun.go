@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rerunRequested shows whether RerunAnnotation was changed since the start of the current run.
+// The runs of PLZ are managed by Grafana Cloud k6, so they cannot be rerun.
+func rerunRequested(k6 *v1alpha1.TestRun) bool {
+	rerun, ok := k6.GetAnnotations()[v1alpha1.RerunAnnotation]
+	return ok && rerun != k6.GetStatus().Rerun && !v1alpha1.IsTrue(k6, v1alpha1.CloudPLZTestRun)
+}
+
+// RerunTestRun starts a finished TestRun again: it deletes the objects of
+// the previous run and, once they are gone, resets the status so that the
+// test is initialized from scratch.
+func RerunTestRun(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	rerun := k6.GetAnnotations()[v1alpha1.RerunAnnotation]
+	log.Info(fmt.Sprintf("Rerun %s is requested, cleaning up the previous run", rerun))
+
+	opts := []client.ListOption{
+		client.InNamespace(k6.NamespacedName().Namespace),
+		client.MatchingLabels{"app": "k6", "k6_cr": k6.NamespacedName().Name},
+	}
+
+	// The runners, the initializer and the starter are all jobs of the test run.
+	if err := deleteRunObjects(ctx, log, k6, r, &batchv1.JobList{}, "job", opts,
+		client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := deleteRunObjects(ctx, log, k6, r, &corev1.ServiceList{}, "service", opts); err != nil {
+		return ctrl.Result{}, err
+	}
+	// Claims and ConfigMaps owned by the test run keep data of the previous run.
+	if err := deleteRunObjects(ctx, log, k6, r, &corev1.PersistentVolumeClaimList{}, "persistent volume claim", opts); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := deleteRunObjects(ctx, log, k6, r, &corev1.ConfigMapList{}, "config map", opts); err != nil {
+		return ctrl.Result{}, err
+	}
+	if k6.GetSpec().DisruptionBudget {
+		r.deleteDisruptionBudget(ctx, log, k6)
+	}
+
+	// Pods of the previous run would be mistaken for the new runners.
+	pl := &corev1.PodList{}
+	if err := r.List(ctx, pl, opts...); err != nil {
+		log.Error(err, "Could not list pods")
+		return ctrl.Result{}, err
+	}
+	if len(pl.Items) > 0 {
+		log.Info(fmt.Sprintf("Waiting for %d pods of the previous run to be deleted", len(pl.Items)))
+		return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+	}
+
+	// The cloud test run of the previous run must not be restored.
+	if _, ok := k6.GetAnnotations()[v1alpha1.CloudTestRunIDAnnotation]; ok {
+		patch := client.MergeFrom(k6.DeepCopy())
+		delete(k6.Annotations, v1alpha1.CloudTestRunIDAnnotation)
+		delete(k6.Annotations, v1alpha1.CloudAggregationVarsAnnotation)
+		if err := r.Patch(ctx, k6, patch); err != nil {
+			log.Error(err, "Failed to remove the cloud test run from annotations")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The stage cannot go back with UpdateStatus, so the status is replaced.
+	k6.Status = v1alpha1.TestRunStatus{Rerun: rerun}
+	if err := r.Status().Update(ctx, k6); err != nil {
+		log.Error(err, "Could not reset status of custom resource")
+		return ctrl.Result{}, err
+	}
+
+	log.Info(fmt.Sprintf("Status of TestRun was reset for rerun %s", rerun))
+	r.recordEvent(k6, corev1.EventTypeNormal, "Rerun", fmt.Sprintf("Test is run again for rerun %s", rerun))
+	return ctrl.Result{}, nil
+}
+
+// deleteRunObjects deletes the objects of the kind of list which are controlled by the test run.
+// They are listed from the API server, as claims and ConfigMaps aren't watched by the operator.
+func deleteRunObjects(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler,
+	list client.ObjectList, kind string, opts []client.ListOption, deleteOpts ...client.DeleteOption) error {
+	if err := r.apiReader().List(ctx, list, opts...); err != nil {
+		log.Error(err, fmt.Sprintf("Could not list %ss", kind))
+		return err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok || !metav1.IsControlledBy(obj, k6) {
+			continue
+		}
+		if err := r.Delete(ctx, obj, deleteOpts...); client.IgnoreNotFound(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete %s %s", kind, obj.GetName()))
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_RerunTestRun(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       "test-uid",
+			Annotations: map[string]string{
+				v1alpha1.RerunAnnotation:                "2",
+				v1alpha1.CloudTestRunIDAnnotation:       "123",
+				v1alpha1.CloudAggregationVarsAnnotation: "vars",
+			},
+		},
+		Spec: v1alpha1.TestRunSpec{Parallelism: 1},
+		Status: v1alpha1.TestRunStatus{
+			Stage:     "finished",
+			TestRunID: "123",
+			Rerun:     "1",
+			Result:    v1alpha1.TestRunSucceeded,
+		},
+	}
+	if !rerunRequested(k6) {
+		t.Fatal("expected a rerun to be requested")
+	}
+
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default", Labels: runnerLabels}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service-1", Namespace: "default", Labels: runnerLabels}}
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "test-output", Namespace: "default", Labels: runnerLabels}}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-data", Namespace: "default", Labels: runnerLabels}}
+	for _, obj := range []client.Object{job, service, claim, configMap} {
+		if err := ctrl.SetControllerReference(k6, obj, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-1-abcde", Namespace: "default", Labels: runnerLabels}}
+	// the script isn't owned by the test run
+	script := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-script", Namespace: "default", Labels: runnerLabels}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(k6, job, service, claim, configMap, pod, script).
+		WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	// the objects of the previous run are deleted first
+	if res, err := RerunTestRun(ctx, logr.Discard(), k6, r); err != nil || res.RequeueAfter == 0 {
+		t.Fatalf("expected to wait for the pods, got %+v and error: %v", res, err)
+	}
+	for _, obj := range []client.Object{job, service, claim, configMap} {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); !k8sErrors.IsNotFound(err) {
+			t.Errorf("expected %s to be deleted, got error: %v", obj.GetName(), err)
+		}
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(script), script); err != nil {
+		t.Errorf("expected the script to be kept, got error: %v", err)
+	}
+	if k6.Status.Stage != "finished" {
+		t.Fatalf("expected the status to be kept while pods exist, got stage %q", k6.Status.Stage)
+	}
+
+	// garbage collection of the pod
+	if err := c.Delete(ctx, pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := RerunTestRun(ctx, logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.Stage != "" || len(updated.Status.TestRunID) > 0 || len(updated.Status.Result) > 0 || updated.Status.Rerun != "2" {
+		t.Errorf("expected the status to be reset for rerun 2, got %+v", updated.Status)
+	}
+	if _, ok := updated.Annotations[v1alpha1.CloudTestRunIDAnnotation]; ok {
+		t.Error("expected the cloud test run annotation to be removed")
+	}
+	if len(updated.TestRunID()) > 0 {
+		t.Errorf("expected no cloud test run, got %s", updated.TestRunID())
+	}
+	if rerunRequested(updated) {
+		t.Error("expected the rerun to be done")
+	}
+}
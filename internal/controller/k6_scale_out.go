@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// canScaleOut shows whether Parallelism was increased after the runners were
+// created but before the test was started. None of the runners has executed
+// its segment yet, so they can be recreated with the new segmentation.
+// Cloud test runs are excluded: the number of their instances is registered
+// in k6 Cloud on creation.
+func canScaleOut(k6 *v1alpha1.TestRun) bool {
+	created := k6.GetStatus().Parallelism
+	if created == 0 || created >= k6.GetSpec().Parallelism ||
+		k6.GetStatus().Stage != "created" ||
+		v1alpha1.IsTrue(k6, v1alpha1.TestStarted) ||
+		isCloudTestRun(k6) ||
+		len(k6.GetStatus().MissingRunners) > 0 {
+		return false
+	}
+
+	// a standalone runner has started the test on its own already
+	previous := k6.DeepCopy()
+	previous.Spec.Parallelism = created
+	return !previous.Standalone()
+}
+
+// ScaleOutRunners recreates the runners of the test run with the increased
+// Parallelism, so that the segments of all runners cover the whole test again.
+// The segment of a runner is part of its Pod template, which can't be changed:
+// the existing runner Jobs are deleted first and then all of them are created
+// anew, on a later reconcile. The Services of the existing runners are kept.
+func ScaleOutRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	created, parallelism := k6.GetStatus().Parallelism, k6.GetSpec().Parallelism
+
+	jl := &batchv1.JobList{}
+	if err := r.listRunners(ctx, k6, jl); err != nil {
+		log.Error(err, "Could not list jobs")
+		return ctrl.Result{}, err
+	}
+
+	if len(jl.Items) > 0 {
+		if v1alpha1.IsTrue(k6, v1alpha1.RunnerJobsCreated) {
+			log.Info(fmt.Sprintf("Parallelism was increased from %d to %d before the start: recreating the runners", created, parallelism))
+			k6.GetStatus().Waiting = fmt.Sprintf("runners to be recreated with parallelism %d", parallelism)
+			v1alpha1.UpdateCondition(k6, v1alpha1.RunnerJobsCreated, metav1.ConditionFalse)
+			v1alpha1.UpdateCondition(k6, v1alpha1.RunnersReady, metav1.ConditionFalse)
+			if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		for _, job := range jl.Items {
+			if !metav1.IsControlledBy(&job, k6) || !job.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if err := r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				log.Error(err, fmt.Sprintf("Failed to delete runner job %s", job.Name))
+				return ctrl.Result{}, err
+			}
+		}
+
+		// wait for the jobs to be gone before they're created with the same names
+		return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+	}
+
+	log.Info(fmt.Sprintf("Creating %d runners", parallelism))
+	if res, recheck, err := createJobSpecs(ctx, log, k6, r, newTokenInfo(k6)); err != nil || recheck {
+		return res, err
+	}
+
+	k6.GetStatus().Parallelism = parallelism
+	k6.GetStatus().Waiting = ""
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnerJobsCreated, metav1.ConditionTrue)
+
+	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		return ctrl.Result{}, err
+	} else if updateHappened {
+		r.recordEvent(k6, corev1.EventTypeNormal, "ScaledOut", fmt.Sprintf("Recreated runners to scale out from %d to %d", created, parallelism))
+	}
+	return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+}
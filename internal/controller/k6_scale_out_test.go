@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_ScaleOutRunners(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 3,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{Name: "test", File: "test.js"},
+			},
+		},
+		Status: v1alpha1.TestRunStatus{Stage: "created", Parallelism: 2},
+	}
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnerJobsCreated, metav1.ConditionTrue)
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestStarted, metav1.ConditionFalse)
+
+	if !canScaleOut(k6) {
+		t.Fatal("expected the runners to be scaled out")
+	}
+
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"}
+	objects := []client.Object{k6}
+	for _, name := range []string{"test-1", "test-2"} {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: runnerLabels}}
+		if err := ctrl.SetControllerReference(k6, job, scheme); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		objects = append(objects, job)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	// the existing runners are deleted first
+	if _, err := ScaleOutRunners(ctx, logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jl := &batchv1.JobList{}
+	if err := c.List(ctx, jl, k6.ListOptions()); err != nil || len(jl.Items) > 0 {
+		t.Fatalf("expected the runner jobs to be deleted, got %d jobs and error: %v", len(jl.Items), err)
+	}
+	if !v1alpha1.IsFalse(k6, v1alpha1.RunnerJobsCreated) {
+		t.Error("expected the runners to be marked as not created")
+	}
+
+	// and then all of them are created with the new segments
+	if _, err := ScaleOutRunners(ctx, logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.List(ctx, jl, k6.ListOptions()); err != nil || len(jl.Items) != 3 {
+		t.Fatalf("expected 3 runner jobs, got %d and error: %v", len(jl.Items), err)
+	}
+	for _, job := range jl.Items {
+		if command := strings.Join(job.Spec.Template.Spec.Containers[0].Command, " "); !strings.Contains(command, "--execution-segment-sequence=0,1/3,2/3,1") {
+			t.Errorf("expected %s to be segmented between 3 runners, got command %s", job.Name, command)
+		}
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "test-service-3"}, &corev1.Service{}); err != nil {
+		t.Errorf("expected the service of the new runner, got error: %v", err)
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.Parallelism != 3 || !v1alpha1.IsTrue(updated, v1alpha1.RunnerJobsCreated) {
+		t.Errorf("expected 3 created runners in the status, got parallelism %d", updated.Status.Parallelism)
+	}
+	if canScaleOut(updated) {
+		t.Error("expected nothing more to scale out")
+	}
+
+	// runners can't be recreated once they were started
+	updated.Spec.Parallelism = 4
+	v1alpha1.UpdateCondition(updated, v1alpha1.TestStarted, metav1.ConditionTrue)
+	if canScaleOut(updated) {
+		t.Error("expected started runners not to be scaled out")
+	}
+}
@@ -2,75 +2,325 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/cloud"
 	"github.com/grafana/k6-operator/pkg/resources/jobs"
+	"github.com/grafana/k6-operator/pkg/testrun"
+	k6api "go.k6.io/k6/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func isServiceReady(log logr.Logger, service *v1.Service) bool {
-	resp, err := http.Get(fmt.Sprintf("http://%v:6565/v1/status", service.Spec.ClusterIP))
+// confirmStartTimeout is how long the runners have to report
+// that the test is running, once they were started.
+const confirmStartTimeout = time.Minute
 
+const (
+	// readinessTimeout limits a single status request of the readiness check,
+	// on top of the timeout of all requests to runners.
+	readinessTimeout = 2 * time.Second
+
+	// readinessAttempts is how many status requests the readiness check makes
+	// before the runner is considered not ready.
+	readinessAttempts = 3
+
+	// readinessJitter is the maximum random delay before each status request
+	// of the readiness check, so that the runners aren't probed all at once.
+	readinessJitter = 200 * time.Millisecond
+
+	// readinessConcurrency limits how many runners are probed at once.
+	readinessConcurrency = 10
+
+	// respondTimeout is how long the runners have to respond to the readiness
+	// check after their pods have started, before the ones which don't respond
+	// are left out with MinReadyRunners.
+	respondTimeout = 2 * time.Minute
+)
+
+// checkServiceReady returns an error if k6 at hostname doesn't respond to status requests.
+// The request is retried a few times after a random delay.
+func checkServiceReady(ctx context.Context, c *http.Client, hostname string) (err error) {
+	for range readinessAttempts {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rand.N(readinessJitter)):
+		}
+
+		if err = getStatus(ctx, c, hostname); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func getStatus(ctx context.Context, c *http.Client, hostname string) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL(hostname), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("status request returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// checkServicesReady runs the readiness check of all hostnames, a few of them
+// at once. It returns the results in the order of hostnames.
+func checkServicesReady(ctx context.Context, c *http.Client, hostnames []string) []error {
+	var (
+		results = make([]error, len(hostnames))
+		slots   = make(chan struct{}, readinessConcurrency)
+		wg      sync.WaitGroup
+	)
+	for i, hostname := range hostnames {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i] = checkServiceReady(ctx, c, hostname)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// respondingRunners runs the readiness check of all targets. It returns the
+// hostnames of the responding runners in the order of targets, the names
+// of their Jobs and the error of the last runner which doesn't respond.
+func respondingRunners(ctx context.Context, log logr.Logger, c *http.Client, targets []runnerTarget) (hostnames []string, responding map[string]bool, notReady error) {
+	hostnames = make([]string, 0, len(targets))
+	responding = make(map[string]bool, len(targets))
+
+	candidates := make([]string, len(targets))
+	for i, target := range targets {
+		candidates[i] = target.hostname
+	}
+
+	for i, err := range checkServicesReady(ctx, c, candidates) {
+		target := targets[i]
+		if err != nil {
+			notReady = fmt.Errorf("%v %w: %w", target.hostname, ErrRunnerNotReady, err)
+			log.Info(notReady.Error())
+			continue
+		}
+		log.Info(fmt.Sprintf("%v runner is ready", target.hostname))
+		hostnames = append(hostnames, target.hostname)
+		responding[target.runner] = true
+	}
+	return hostnames, responding, notReady
+}
+
+// isRunnerStarted checks that k6 at hostname reports the test as running
+// or, for a short test, as already stopped.
+func isRunnerStarted(log logr.Logger, hostname string) bool {
+	resp, err := testrun.RunnerClient().Get(fmt.Sprintf("http://%v/v1/status", net.JoinHostPort(hostname, "6565")))
 	if err != nil {
-		log.Error(err, fmt.Sprintf("failed to get status from %v", service.Name))
+		log.Error(err, fmt.Sprintf("failed to get status from %v", hostname))
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		return false
+	}
+
+	var status k6api.StatusJSONAPI
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		log.Error(err, fmt.Sprintf("failed to parse status from %v", hostname))
 		return false
 	}
 
-	return resp.StatusCode < 400
+	if status.Status().Stopped {
+		return true
+	}
+	return status.Status().Running && !status.Status().Paused.Bool
+}
+
+// exitedRunners returns the runners whose k6 has already exited after
+// running the test: successfully or with failed thresholds.
+func exitedRunners(pods []v1.Pod) map[string]bool {
+	finished := map[string]bool{}
+	for i := range pods {
+		pod := &pods[i]
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != "k6" || status.State.Terminated == nil {
+				continue
+			}
+			if code := status.State.Terminated.ExitCode; code == 0 || code == thresholdsFailedExitCode {
+				finished[podRunner(pod)] = true
+			}
+		}
+	}
+	return finished
 }
 
-// StartJobs in the Ready phase using a curl container
+// confirmStart waits until all runners report that the test is running
+// and only then marks the test run as started. The runners which have
+// already finished the test count as started.
+func confirmStart(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	res := ctrl.Result{RequeueAfter: r.requeue().Short}
+
+	targets, err := r.listRunnerTargets(ctx, log, k6)
+	if err != nil {
+		kind := runnerTargetsKind(k6)
+		log.Error(err, fmt.Sprintf("Could not list %s", kind))
+		r.listFailed(ctx, log, k6, kind, err)
+		return res, nil
+	}
+
+	pl := &v1.PodList{}
+	if err := r.listRunners(ctx, k6, pl); err != nil {
+		log.Error(err, "Could not list pods")
+		r.listFailed(ctx, log, k6, "pods", err)
+		return res, nil
+	}
+	r.listSucceeded(ctx, log, k6)
+
+	started := exitedRunners(pl.Items)
+	for runner := range started {
+		if k6.IsMissingRunner(runner) {
+			delete(started, runner)
+		}
+	}
+	for _, target := range targets {
+		if !started[target.runner] && isRunnerStarted(log, target.hostname) {
+			started[target.runner] = true
+		}
+	}
+	count := len(started)
+
+	log.Info(fmt.Sprintf("%d/%d runners confirmed the start", count, k6.Runners()))
+
+	if count != int(k6.Runners()) {
+		if t, ok := v1alpha1.LastUpdate(k6, v1alpha1.TestStarted); ok && time.Since(t) > confirmStartTimeout {
+			err := fmt.Errorf("%w: only %d/%d runners confirmed the start within %s", ErrRunnerStartTimeout, count, k6.Runners(), confirmStartTimeout)
+			msg := err.Error()
+			log.Info(msg)
+
+			if isCloudTestRun(k6) {
+				events := cloud.ErrorEvent(cloud.K6OperatorStartError).
+					WithDetail(msg).
+					WithAbort()
+				r.sendCloudEvents(ctx, log, k6, events)
+			}
+
+			k6.GetStatus().Error = msg
+			return abortStart(ctx, log, k6, r)
+		}
+		return res, nil
+	}
+
+	return markStarted(ctx, log, k6, r)
+}
+
+// StartJobs in the Ready phase using a curl container or, unless
+// UseLegacyStarter is set, directly from the operator
 func StartJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (res ctrl.Result, err error) {
 	// It may take some time to get Services up, so check in frequently
-	res = ctrl.Result{RequeueAfter: time.Second}
+	res = ctrl.Result{RequeueAfter: r.requeue().Short}
 
 	if len(k6.GetStatus().TestRunID) > 0 {
 		log = log.WithValues("testRunId", k6.GetStatus().TestRunID)
 	}
+	if len(k6.GetStatus().TraceID) > 0 {
+		log = log.WithValues("traceId", k6.GetStatus().TraceID)
+	}
 
-	log.Info("Waiting for pods to get ready")
+	if k6.GetSpec().ConfirmStart && v1alpha1.IsTrue(k6, v1alpha1.TestStarted) {
+		// the runners have already been started
+		return confirmStart(ctx, log, k6, r)
+	}
 
-	opts := k6.ListOptions()
+	log.Info("Waiting for pods to get ready")
 
 	pl := &v1.PodList{}
-	if err = r.List(ctx, pl, opts); err != nil {
+	if err = r.listRunners(ctx, k6, pl); err != nil {
 		log.Error(err, "Could not list pods")
+		r.listFailed(ctx, log, k6, "pods", err)
 		return res, nil
 	}
+	r.listSucceeded(ctx, log, k6)
 
 	var count int
 	for _, pod := range pl.Items {
-		if pod.Status.Phase != "Running" {
+		if owner := metav1.GetControllerOf(&pod); owner != nil && k6.IsMissingRunner(owner.Name) {
+			continue
+		}
+		if !pod.DeletionTimestamp.IsZero() {
+			// e.g. a pod of a runner recreated on scale-out
 			continue
 		}
+		if pod.Status.Phase != "Running" {
+			// a standalone runner might have finished its test already
+			if !k6.Standalone() || (pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed) {
+				continue
+			}
+		}
 		count++
 	}
 
-	log.Info(fmt.Sprintf("%d/%d runner pods ready", count, k6.GetSpec().Parallelism))
+	log.Info(fmt.Sprintf("%d/%d runner pods ready", count, k6.Runners()))
 
-	if count != int(k6.GetSpec().Parallelism) {
-		if t, ok := v1alpha1.LastUpdate(k6, v1alpha1.TestRunRunning); !ok {
+	if count != int(k6.Runners()) {
+		// Count the time from creation of the runners: the test run
+		// might have waited for its precondition before that.
+		t, ok := v1alpha1.LastUpdate(k6, v1alpha1.RunnerJobsCreated)
+		if !ok {
+			t, ok = v1alpha1.LastUpdate(k6, v1alpha1.TestRunRunning)
+		}
+		if !ok {
 			// this should never happen
 			return res, errors.New("cannot find condition TestRunRunning")
 		} else {
 			// let's try this approach
-			if time.Since(t).Minutes() > 5 {
+			if time.Since(t) > creationTimeout {
+				if canLeaveOutRunners(k6, count) {
+					return LeaveOutUnreadyRunners(ctx, log, k6, r, runningRunners(pl.Items))
+				}
+
 				msg := fmt.Sprintf(errMessageTooLong, "runner pods", "runner jobs and pods")
+				if failed := imagePullFailures(pl.Items); len(failed) > 0 {
+					msg += fmt.Sprintf(errImagePullHint, strings.Join(failed, ", "))
+				}
 				log.Info(msg)
 
 				if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) {
 					events := cloud.ErrorEvent(cloud.K6OperatorStartError).
 						WithDetail(msg).
 						WithAbort()
-					cloud.SendTestRunEvents(r.k6CloudClient, k6.TestRunID(), log, events)
+					r.sendCloudEvents(ctx, log, k6, events)
+
+					k6.GetStatus().Error = fmt.Errorf("%w: %s", ErrRunnerStartTimeout, msg).Error()
+					return abortStart(ctx, log, k6, r)
 				}
 			}
 		}
@@ -78,60 +328,164 @@ func StartJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *Te
 		return res, nil
 	}
 
+	if k6.Standalone() {
+		log.Info("Standalone runner has started the test on its own")
+		v1alpha1.UpdateCondition(k6, v1alpha1.RunnersReady, metav1.ConditionTrue)
+		return markStarted(ctx, log, k6, r)
+	}
+
 	// services
 
-	log.Info("Waiting for services to get ready")
+	kind := runnerTargetsKind(k6)
+	log.Info(fmt.Sprintf("Waiting for %s to respond", kind))
 
-	hostnames, err := r.hostnames(ctx, log, true, opts)
-	log.Info(fmt.Sprintf("err: %v, hostnames: %v", err, hostnames))
+	targets, err := r.listRunnerTargets(ctx, log, k6)
 	if err != nil {
-		return ctrl.Result{}, err
+		log.Error(err, fmt.Sprintf("Could not list %s", kind))
+		r.listFailed(ctx, log, k6, kind, err)
+		return res, nil
 	}
 
-	log.Info(fmt.Sprintf("%d/%d services ready", len(hostnames), k6.GetSpec().Parallelism))
+	hostnames, responding, notReady := respondingRunners(ctx, log, testrun.RunnerClient(), targets)
+	if len(hostnames) < int(k6.Runners()) {
+		msg := fmt.Sprintf("%d/%d %s responding", len(hostnames), k6.Runners(), kind)
+		log.Info(msg)
+
+		// The runners which don't respond are left out only after a while:
+		// their pods are running, so they're likely to respond soon.
+		started := runnersStartTime(pl.Items)
+		if canLeaveOutRunners(k6, len(hostnames)) && !started.IsZero() && time.Since(started) > respondTimeout {
+			return LeaveOutUnreadyRunners(ctx, log, k6, r, responding)
+		}
+
+		k6.GetStatus().Waiting = msg
+		hintUnreachableRunners(ctx, log, k6, r, notReady, started)
+		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+			return ctrl.Result{}, err
+		}
+		return res, nil
+	}
+	k6.GetStatus().Waiting = ""
+	if v1alpha1.IsTrue(k6, v1alpha1.RunnerAPIUnavailable) {
+		v1alpha1.UpdateCondition(k6, v1alpha1.RunnerAPIUnavailable, metav1.ConditionFalse)
+	}
+
+	log.Info(fmt.Sprintf("%d/%d %s ready", len(hostnames), k6.Runners(), kind))
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnersReady, metav1.ConditionTrue)
 
 	// setup
 
-	if v1alpha1.IsTrue(k6, v1alpha1.CloudPLZTestRun) {
-		if err, retry := runSetup(ctx, hostnames, log); err != nil {
-			if retry {
+	if k6.RunsSetup() {
+		if err, retry := runSetup(ctx, k6, hostnames, log); err != nil {
+			if retry || k6.RetriesSetup() {
+				log.Error(err, "Setup function failed, retrying.")
 				return ctrl.Result{}, err
 			}
 
 			log.Error(err, "Setup function failed, requesting abort.")
-			events := cloud.ErrorEvent(cloud.SetupError).
-				WithDetail(fmt.Sprintf("setup function failed: %v", err)).
-				WithAbort()
-			cloud.SendTestRunEvents(r.k6CloudClient, k6.TestRunID(), log, events)
+			startFailures.WithLabelValues("setup").Inc()
+			if isCloudTestRun(k6) {
+				events := cloud.ErrorEvent(cloud.SetupError).
+					WithDetail(fmt.Sprintf("setup function failed: %v", err)).
+					WithAbort()
+				r.sendCloudEvents(ctx, log, k6, events)
+			}
 
-			return ctrl.Result{Requeue: false}, nil
+			k6.GetStatus().SetupError = err.Error()
+			return abortStart(ctx, log, k6, r)
 		}
 	}
 
 	// starter
 
-	starter := jobs.NewStarterJob(k6, hostnames)
+	if r.UseLegacyStarter {
+		if err = createStarter(ctx, log, k6, r, hostnames); err != nil {
+			log.Error(err, "Failed to launch k6 test starter")
+			startFailures.WithLabelValues("starter_job").Inc()
+			return res, nil
+		}
+	} else {
+		if started, err := StartK6FromOperators(ctx, log, k6, hostnames, r); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to start k6 runners, %d/%d started", started, len(hostnames)))
+			startFailures.WithLabelValues("http_start").Inc()
 
-	if err = ctrl.SetControllerReference(k6, starter, r.Scheme); err != nil {
-		log.Error(err, "Failed to set controller reference for the start job")
+			if !errors.Is(err, ErrPartialStart) {
+				// Nothing has been started yet so it's safe to try again.
+				hintUnreachableRunners(ctx, log, k6, r, err, runnersStartTime(pl.Items))
+				return res, nil
+			}
+
+			// Some runners are already executing the test, and there is no way
+			// to bring them back to the paused state: the test is broken.
+			if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) {
+				events := cloud.ErrorEvent(cloud.K6OperatorStartError).
+					WithDetail(fmt.Sprintf("Failed to start all runners: %v", err)).
+					WithAbort()
+				r.sendCloudEvents(ctx, log, k6, events)
+			}
+
+			k6.GetStatus().Error = err.Error()
+			return abortStart(ctx, log, k6, r)
+		}
+
+		log.Info("Started k6 runners")
 	}
 
-	// TODO: add a check for existence of starter job
+	if k6.GetSpec().ConfirmStart {
+		log.Info("Waiting for runners to confirm the start")
+		v1alpha1.UpdateCondition(k6, v1alpha1.TestStarted, metav1.ConditionTrue)
 
-	if err = r.Create(ctx, starter); err != nil {
-		log.Error(err, "Failed to launch k6 test starter")
+		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+			return ctrl.Result{}, err
+		}
 		return res, nil
 	}
 
+	return markStarted(ctx, log, k6, r)
+}
+
+// createStarter creates the starter job unless it already exists, e.g. when
+// the status couldn't be updated after the starter was created last time.
+func createStarter(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, hostnames []string) error {
+	starter := jobs.NewStarterJob(k6, hostnames)
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(starter), &batchv1.Job{}); err == nil {
+		log.Info("Starter job already exists")
+		return nil
+	} else if !k8sErrors.IsNotFound(err) {
+		return err
+	}
+
+	if err := ctrl.SetControllerReference(k6, starter, r.Scheme); err != nil {
+		log.Error(err, "Failed to set controller reference for the start job")
+	}
+
+	if err := r.Create(ctx, starter); err != nil {
+		if k8sErrors.IsAlreadyExists(err) {
+			log.Info("Starter job already exists")
+			return nil
+		}
+		return err
+	}
+
 	log.Info("Created starter job")
+	return nil
+}
 
+func markStarted(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
 	log.Info("Changing stage of TestRun status to started")
 	k6.GetStatus().Stage = "started"
+	if k6.GetStatus().StartTime == nil {
+		now := metav1.Now()
+		k6.GetStatus().StartTime = &now
+	}
 	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionTrue)
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestStarted, metav1.ConditionTrue)
 
 	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
 		return ctrl.Result{}, err
 	} else if updateHappened {
+		r.recordEvent(k6, v1.EventTypeNormal, "Started", fmt.Sprintf("Started %d runners", k6.Runners()))
 		return ctrl.Result{Requeue: true}, nil
 	}
 	return ctrl.Result{}, nil
@@ -0,0 +1,423 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/testrun"
+	"github.com/grafana/k6-operator/pkg/types"
+	k6api "go.k6.io/k6/api/v1"
+)
+
+// The non-legacy start path: instead of creating a starter job with a curl
+// container, the operator sends the start PATCH requests to the runners itself.
+//
+// The start is done in two phases. First, every runner is "armed": its REST API
+// is checked to respond and to be paused, waiting for the start. These requests
+// are queued into the testRequests channel and processed by a fixed pool of
+// HTTP workers, so that a TestRun with high parallelism cannot open an unbounded
+// number of connections at once. Each request reports its outcome back to the
+// caller, so no runner is left paused silently.
+//
+// Then, once all runners are armed, the start PATCH requests are fired at once
+// from a goroutine per runner released by a common barrier, so that the runners
+// begin as close to simultaneously as possible. Connections to the runners are
+// usually kept alive since arming, so firing doesn't need to dial them again.
+
+const (
+	// DefaultHTTPWorkers is the number of HTTP workers used by default.
+	DefaultHTTPWorkers = 10
+
+	// defaultSendTimeout limits how long a start request can wait for
+	// a free slot in the testRequests channel.
+	defaultSendTimeout = 30 * time.Second
+)
+
+// errStartQueueFull is returned when a start request couldn't be queued
+// because all HTTP workers stayed busy for too long.
+var errStartQueueFull = errors.New("http request channel is full")
+
+// errAlreadyStarted is returned on arming of a runner which isn't paused anymore:
+// it was started by an earlier reconcile whose outcome wasn't recorded.
+var errAlreadyStarted = errors.New("test is already started")
+
+// startRequest is a single request to the REST API of one runner.
+// Despite the name, it's also used to arm and to stop the runners.
+type startRequest struct {
+	testName string
+	request  *http.Request
+	result   chan<- error
+	// check validates the body of a successful response, if set.
+	check func(body []byte) error
+}
+
+// httpWorkers is a pool of goroutines sending start requests to the runners.
+// It implements manager.Runnable and is started together with the manager.
+type httpWorkers struct {
+	size         int
+	testRequests chan startRequest
+	client       *http.Client
+	sendTimeout  time.Duration
+	log          logr.Logger
+
+	// statusID and statusType identify the status resource of k6 REST API
+	// in the PATCH requests.
+	statusID   string
+	statusType string
+}
+
+// newHTTPWorkers returns a pool of size workers. Up to queueSize requests
+// can wait for a free worker; if it's not positive, it's the same as size.
+func newHTTPWorkers(size, queueSize int, log logr.Logger) *httpWorkers {
+	if size <= 0 {
+		size = DefaultHTTPWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = size
+	}
+
+	return &httpWorkers{
+		size:         size,
+		testRequests: make(chan startRequest, queueSize),
+		client:       testrun.RunnerClient(),
+		sendTimeout:  defaultSendTimeout,
+		log:          log,
+		statusID:     types.DefaultStatusID,
+		statusType:   types.DefaultStatusType,
+	}
+}
+
+// Start runs the workers until the context is cancelled.
+func (w *httpWorkers) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < w.size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx)
+		}()
+	}
+
+	w.log.Info(fmt.Sprintf("Started %d HTTP workers for starting tests", w.size))
+	wg.Wait()
+	return nil
+}
+
+func (w *httpWorkers) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-w.testRequests:
+			httpRequestsQueued.Dec()
+			req.result <- w.do(req)
+		}
+	}
+}
+
+func (w *httpWorkers) do(req startRequest) error {
+	httpRequestsInFlight.Inc()
+	defer httpRequestsInFlight.Dec()
+
+	setTraceHeader(req.request)
+	resp, err := w.client.Do(req.request)
+	if err != nil {
+		return fmt.Errorf("test %s: request to %s failed: %w", req.testName, req.request.URL.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("test %s: request to %s returned %d: %s", req.testName, req.request.URL.Host, resp.StatusCode, body)
+	}
+
+	if req.check != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("test %s: cannot read response of %s: %w", req.testName, req.request.URL.Host, err)
+		}
+		if err := req.check(body); err != nil {
+			return fmt.Errorf("test %s: runner %s: %w", req.testName, req.request.URL.Host, err)
+		}
+	} else {
+		// drain the body so that the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}
+	return nil
+}
+
+// SendArmToHTTPWorker queues a request checking that the runner at hostname
+// is ready to be started: its REST API responds and the test is paused.
+// If the test is running or done already, errAlreadyStarted is reported.
+// The outcome of the request is reported to result, which must have
+// enough capacity to never block the worker.
+// If all workers are busy and the channel is full, it waits for a free slot
+// instead of dropping the request. The wait is bounded by sendTimeout;
+// if there is still no free slot by then, or the context is done, an error is returned.
+func (w *httpWorkers) SendArmToHTTPWorker(ctx context.Context, testName, hostname string, result chan<- error) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL(hostname), nil)
+	if err != nil {
+		return err
+	}
+
+	return w.enqueue(ctx, startRequest{
+		testName: testName,
+		request:  request,
+		result:   result,
+		check: func(body []byte) error {
+			var status k6api.StatusJSONAPI
+			if err := json.Unmarshal(body, &status); err != nil {
+				return fmt.Errorf("cannot parse status: %w", err)
+			}
+			if status.Status().Stopped || !status.Status().Paused.Bool {
+				return errAlreadyStarted
+			}
+			return nil
+		},
+	})
+}
+
+// SendStopToHTTPWorker queues a stop request for the runner at hostname.
+// It behaves the same way as SendArmToHTTPWorker.
+func (w *httpWorkers) SendStopToHTTPWorker(ctx context.Context, testName, hostname string, result chan<- error) error {
+	request, err := w.statusRequest(ctx, hostname, types.StatusAPIRequestDataAttributes{Stopped: true})
+	if err != nil {
+		return err
+	}
+
+	return w.enqueue(ctx, startRequest{testName: testName, request: request, result: result})
+}
+
+func (w *httpWorkers) enqueue(ctx context.Context, req startRequest) error {
+	sendCtx, cancel := context.WithTimeout(ctx, w.sendTimeout)
+	defer cancel()
+
+	select {
+	case w.testRequests <- req:
+		httpRequestsQueued.Inc()
+		return nil
+	case <-sendCtx.Done():
+		if ctx.Err() != nil {
+			return fmt.Errorf("test %s: request to %s was not sent: %w", req.testName, req.request.URL.Host, ctx.Err())
+		}
+		return fmt.Errorf("test %s: request to %s was not sent: %w", req.testName, req.request.URL.Host, errStartQueueFull)
+	}
+}
+
+func statusURL(hostname string) string {
+	return fmt.Sprintf("http://%s/v1/status", net.JoinHostPort(hostname, "6565"))
+}
+
+// statusRequest builds a PATCH request changing the status of the runner at hostname.
+func (w *httpWorkers) statusRequest(ctx context.Context, hostname string, attributes types.StatusAPIRequestDataAttributes) (*http.Request, error) {
+	req := types.NewStatusAPIRequest(w.statusID, w.statusType, attributes)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPatch, statusURL(hostname), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return request, nil
+}
+
+// startAll sends the start requests to all runners at once and waits for them.
+// It returns the number of runners started successfully and the spread
+// between the first and the last successful start.
+func (w *httpWorkers) startAll(ctx context.Context, testName string, hostnames []string) (started int, spread time.Duration, err error) {
+	// Build all requests before releasing any of them.
+	requests := make([]*http.Request, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		request, err := w.statusRequest(ctx, hostname, types.StatusAPIRequestDataAttributes{Paused: false})
+		if err != nil {
+			return 0, 0, err
+		}
+		requests = append(requests, request)
+	}
+
+	type outcome struct {
+		err error
+		at  time.Time
+	}
+
+	var (
+		barrier  = make(chan struct{})
+		outcomes = make(chan outcome, len(requests))
+	)
+	for _, request := range requests {
+		go func(request *http.Request) {
+			<-barrier
+			err := w.do(startRequest{testName: testName, request: request})
+			outcomes <- outcome{err: err, at: time.Now()}
+		}(request)
+	}
+	close(barrier)
+
+	var (
+		errs        []error
+		first, last time.Time
+	)
+	for range requests {
+		o := <-outcomes
+		if o.err != nil {
+			errs = append(errs, o.err)
+			continue
+		}
+		started++
+		if first.IsZero() || o.at.Before(first) {
+			first = o.at
+		}
+		if o.at.After(last) {
+			last = o.at
+		}
+	}
+
+	return started, last.Sub(first), errors.Join(errs...)
+}
+
+// armAll arms all runners and returns the ones which are paused, waiting for
+// the start, and the number of armed runners. The runners which are already
+// started count as armed, but they must not be started again.
+func (w *httpWorkers) armAll(ctx context.Context, log logr.Logger, testName string, hostnames []string) (paused []string, armed int, err error) {
+	var (
+		errs    []error
+		results = make([]chan error, 0, len(hostnames))
+	)
+	for _, hostname := range hostnames {
+		result := make(chan error, 1)
+		if err := w.SendArmToHTTPWorker(ctx, testName, hostname, result); err != nil {
+			// Don't queue the rest: the runners cannot be all armed anyway.
+			errs = append(errs, err)
+			break
+		}
+		results = append(results, result)
+	}
+
+	for i, result := range results {
+		select {
+		case err := <-result:
+			switch {
+			case err == nil:
+				paused = append(paused, hostnames[i])
+				armed++
+			case errors.Is(err, errAlreadyStarted):
+				armed++
+			default:
+				log.Error(err, "Request to runner failed")
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return paused, armed, ctx.Err()
+		}
+	}
+
+	return paused, armed, errors.Join(errs...)
+}
+
+// sendFunc queues a request for one runner to the HTTP workers.
+type sendFunc func(ctx context.Context, testName, hostname string, result chan<- error) error
+
+// StartK6FromOperators starts the test on all runners: it arms them
+// with the HTTP workers and then starts them all at once.
+// It returns the number of runners that were started successfully:
+// if it's less than the number of hostnames, the error describes what failed.
+// If some runner couldn't be armed, none of them is started. The runners
+// which are already started, e.g. when the status update after the start
+// was lost, count as started.
+func StartK6FromOperators(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, hostnames []string, r *TestRunReconciler) (int, error) {
+	if r.httpWorkers == nil {
+		return 0, errors.New("HTTP workers are not running")
+	}
+	ctx = withTrace(ctx, k6)
+
+	log.Info(fmt.Sprintf("Arming %d runners", len(hostnames)))
+	paused, armed, err := r.httpWorkers.armAll(ctx, log, k6.NamespacedName().Name, hostnames)
+	if err != nil {
+		return 0, fmt.Errorf("%d/%d runners armed: %w", armed, len(hostnames), err)
+	}
+
+	alreadyStarted := len(hostnames) - len(paused)
+	if alreadyStarted > 0 {
+		log.Info(fmt.Sprintf("%d/%d runners are already started", alreadyStarted, len(hostnames)))
+	}
+	if len(paused) == 0 {
+		return alreadyStarted, nil
+	}
+
+	log.Info(fmt.Sprintf("Starting %d runners from the operator", len(paused)))
+	started, spread, err := r.httpWorkers.startAll(ctx, k6.NamespacedName().Name, paused)
+	started += alreadyStarted
+	if started > alreadyStarted {
+		startSpread.Observe(spread.Seconds())
+		log.Info(fmt.Sprintf("%d/%d runners started within %s", started, len(hostnames), spread))
+	}
+	if started > 0 && err != nil {
+		return started, fmt.Errorf("%w: %d/%d runners started: %w", ErrPartialStart, started, len(hostnames), err)
+	}
+	return started, err
+}
+
+// StopK6FromOperators stops the test on all runners with the HTTP workers.
+// Like StartK6FromOperators, it returns the number of runners that were stopped successfully.
+func StopK6FromOperators(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, hostnames []string, r *TestRunReconciler) (int, error) {
+	if r.httpWorkers == nil {
+		return 0, errors.New("HTTP workers are not running")
+	}
+	ctx = withTrace(ctx, k6)
+
+	log.Info(fmt.Sprintf("Stopping %d runners from the operator", len(hostnames)))
+	return sendToRunners(ctx, log, k6, hostnames, r.httpWorkers.SendStopToHTTPWorker)
+}
+
+func sendToRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, hostnames []string, send sendFunc) (int, error) {
+	var (
+		errs      []error
+		queued    int
+		succeeded int
+		results   = make(chan error, len(hostnames))
+	)
+
+	for _, hostname := range hostnames {
+		if err := send(ctx, k6.NamespacedName().Name, hostname, results); err != nil {
+			// Don't queue the rest: the request cannot succeed on all runners anyway.
+			errs = append(errs, err)
+			break
+		}
+		queued++
+	}
+
+	// Wait for the requests that were queued, even if some weren't:
+	// the caller must know how many runners have actually received the request.
+	for i := 0; i < queued; i++ {
+		select {
+		case err := <-results:
+			if err != nil {
+				log.Error(err, "Request to runner failed")
+				errs = append(errs, err)
+			} else {
+				succeeded++
+			}
+		case <-ctx.Done():
+			return succeeded, ctx.Err()
+		}
+	}
+
+	return succeeded, errors.Join(errs...)
+}
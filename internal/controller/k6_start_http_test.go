@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestHTTPWorkers returns HTTP workers sending all requests to srv,
+// whatever the hostname of the runner.
+func newTestHTTPWorkers(size int, srv *httptest.Server) *httpWorkers {
+	w := newHTTPWorkers(size, 0, logr.Discard())
+	w.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+			},
+		},
+	}
+	return w
+}
+
+const pausedStatus = `{"data":{"type":"status","id":"default","attributes":{"paused":true,"stopped":false}}}`
+
+func Test_StartK6FromOperators_SaturatedChannel(t *testing.T) {
+	t.Parallel()
+
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/status" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch req.Method {
+		case http.MethodGet:
+			// slow runners keep the workers busy so that the channel fills up
+			time.Sleep(5 * time.Millisecond)
+			_, _ = rw.Write([]byte(pausedStatus))
+		case http.MethodPatch:
+			received.Add(1)
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestHTTPWorkers(2, srv)
+	go func() { _ = w.Start(ctx) }()
+
+	r := &TestRunReconciler{httpWorkers: w}
+	k6 := &v1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	hostnames := make([]string, 50)
+	for i := range hostnames {
+		hostnames[i] = "10.0.0.1"
+	}
+
+	started, err := StartK6FromOperators(ctx, logr.Discard(), k6, hostnames, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if started != len(hostnames) {
+		t.Errorf("expected %d started runners, got %d", len(hostnames), started)
+	}
+	if int(received.Load()) != len(hostnames) {
+		t.Errorf("expected %d start requests, got %d", len(hostnames), received.Load())
+	}
+}
+
+func Test_StartK6FromOperators_NotArmed(t *testing.T) {
+	t.Parallel()
+
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			if req.Host == "10.0.0.2:6565" {
+				// this is not the REST API of k6
+				_, _ = rw.Write([]byte(`<html></html>`))
+				return
+			}
+			_, _ = rw.Write([]byte(pausedStatus))
+		case http.MethodPatch:
+			received.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestHTTPWorkers(2, srv)
+	go func() { _ = w.Start(ctx) }()
+
+	r := &TestRunReconciler{httpWorkers: w}
+	k6 := &v1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	started, err := StartK6FromOperators(ctx, logr.Discard(), k6, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, r)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if started != 0 || received.Load() != 0 {
+		t.Errorf("expected no runner to be started, got %d (%d requests)", started, received.Load())
+	}
+	if errors.Is(err, ErrPartialStart) {
+		t.Errorf("expected the start to be retriable, got %v", err)
+	}
+}
+
+func Test_StartK6FromOperators_AlreadyStarted(t *testing.T) {
+	t.Parallel()
+
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			switch req.Host {
+			case "10.0.0.2:6565":
+				_, _ = rw.Write([]byte(`{"data":{"type":"status","id":"default","attributes":{"paused":false,"stopped":false}}}`))
+			case "10.0.0.3:6565":
+				// a short test is already over
+				_, _ = rw.Write([]byte(`{"data":{"type":"status","id":"default","attributes":{"paused":false,"stopped":true}}}`))
+			default:
+				_, _ = rw.Write([]byte(pausedStatus))
+			}
+		case http.MethodPatch:
+			if req.Host != "10.0.0.1:6565" {
+				t.Errorf("unexpected start of the runner %s", req.Host)
+			}
+			received.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestHTTPWorkers(2, srv)
+	go func() { _ = w.Start(ctx) }()
+
+	r := &TestRunReconciler{httpWorkers: w}
+	k6 := &v1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	// the previous start was lost before the status update: only the paused runner is started
+	started, err := StartK6FromOperators(ctx, logr.Discard(), k6, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if started != 3 || received.Load() != 1 {
+		t.Errorf("expected 3 started runners with 1 start request, got %d (%d requests)", started, received.Load())
+	}
+
+	started, err = StartK6FromOperators(ctx, logr.Discard(), k6, []string{"10.0.0.2", "10.0.0.3"}, r)
+	if err != nil || started != 2 {
+		t.Errorf("expected the runners to be started already, got %d and error: %v", started, err)
+	}
+}
+
+func Test_StartK6FromOperators_PartialStart(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			_, _ = rw.Write([]byte(pausedStatus))
+		case http.MethodPatch:
+			if req.Host == "10.0.0.2:6565" {
+				rw.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestHTTPWorkers(2, srv)
+	go func() { _ = w.Start(ctx) }()
+
+	r := &TestRunReconciler{httpWorkers: w}
+	k6 := &v1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	started, err := StartK6FromOperators(ctx, logr.Discard(), k6, []string{"10.0.0.1", "10.0.0.2"}, r)
+	if started != 1 {
+		t.Errorf("expected 1 started runner, got %d", started)
+	}
+	if !errors.Is(err, ErrPartialStart) {
+		t.Errorf("expected ErrPartialStart, got %v", err)
+	}
+}
+
+func Test_SendArmToHTTPWorker_FullChannel(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	// workers are not started so nothing drains the channel
+	w := newTestHTTPWorkers(1, srv)
+	w.sendTimeout = 10 * time.Millisecond
+
+	results := make(chan error, 2)
+	if err := w.SendArmToHTTPWorker(context.Background(), "test", "10.0.0.1", results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := w.SendArmToHTTPWorker(context.Background(), "test", "10.0.0.2", results)
+	if !errors.Is(err, errStartQueueFull) {
+		t.Fatalf("expected error %v, got %v", errStartQueueFull, err)
+	}
+	if len(w.testRequests) != 1 {
+		t.Errorf("expected 1 queued request, got %d", len(w.testRequests))
+	}
+}
+
+func Test_StopK6FromOperators(t *testing.T) {
+	t.Parallel()
+
+	var stopped atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var payload types.StatusAPIRequest
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil || !payload.Data.Attributes.Stopped {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		stopped.Add(1)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestHTTPWorkers(2, srv)
+	go func() { _ = w.Start(ctx) }()
+
+	r := &TestRunReconciler{httpWorkers: w}
+	k6 := &v1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	hostnames := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	n, err := StopK6FromOperators(ctx, logr.Discard(), k6, hostnames, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != len(hostnames) || int(stopped.Load()) != len(hostnames) {
+		t.Errorf("expected %d stopped runners, got %d (%d requests)", len(hostnames), n, stopped.Load())
+	}
+}
+
+func Test_newHTTPWorkers_QueueSize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		size, queueSize             int
+		expectedSize, expectedQueue int
+	}{
+		{0, 0, DefaultHTTPWorkers, DefaultHTTPWorkers},
+		{20, 0, 20, 20},
+		{20, 1000, 20, 1000},
+	}
+
+	for _, tc := range testCases {
+		w := newHTTPWorkers(tc.size, tc.queueSize, logr.Discard())
+		if w.size != tc.expectedSize || cap(w.testRequests) != tc.expectedQueue {
+			t.Errorf("size %d, queue size %d: expected %d workers and queue of %d, got %d and %d",
+				tc.size, tc.queueSize, tc.expectedSize, tc.expectedQueue, w.size, cap(w.testRequests))
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_createStarter_Twice(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 2},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+	hostnames := []string{"10.0.0.1", "10.0.0.2"}
+
+	// the second reconcile happens when the status wasn't updated after the first one
+	for i := 0; i < 2; i++ {
+		if err := createStarter(context.Background(), logr.Discard(), k6, r, hostnames); err != nil {
+			t.Fatalf("reconcile %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	jl := &batchv1.JobList{}
+	if err := c.List(context.Background(), jl); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jl.Items) != 1 {
+		t.Fatalf("expected 1 starter job, got %d", len(jl.Items))
+	}
+	if jl.Items[0].Name != "test-starter" || !metav1.IsControlledBy(&jl.Items[0], k6) {
+		t.Errorf("unexpected starter job %s, owners: %v", jl.Items[0].Name, jl.Items[0].OwnerReferences)
+	}
+}
+
+func Test_markStarted_Event(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 3},
+		Status:     v1alpha1.TestRunStatus{Stage: "created", TestRunID: "42"},
+	}
+	v1alpha1.Initialize(k6)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &TestRunReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+	if _, err := markStarted(context.Background(), logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if expected := "Normal Started Started 3 runners (test run ID 42)"; event != expected {
+			t.Errorf("expected event %q, got %q", expected, event)
+		}
+	default:
+		t.Error("expected an event")
+	}
+}
+
+func Test_checkServicesReady(t *testing.T) {
+	t.Parallel()
+
+	var (
+		requests atomic.Int32
+		seen     sync.Map
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		// every runner fails its first status request
+		if _, retried := seen.LoadOrStore(req.Host, true); !retried || strings.HasPrefix(req.Host, "unready") {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+			},
+		},
+	}
+
+	results := checkServicesReady(context.Background(), c, []string{"runner-1", "unready", "runner-2"})
+	if results[0] != nil || results[2] != nil {
+		t.Errorf("expected the requests to be retried, got errors: %v", results)
+	}
+	if results[1] == nil {
+		t.Error("expected the unready runner to fail all attempts")
+	}
+	if got := requests.Load(); got != 4+readinessAttempts {
+		t.Errorf("expected %d requests, got %d", 4+readinessAttempts, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := checkServiceReady(ctx, c, "runner-3"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the check to be canceled, got %v", err)
+	}
+}
+
+func Test_respondingRunners(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.Host, "unready") {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+			},
+		},
+	}
+
+	targets := []runnerTarget{
+		{runner: "test-1", hostname: "ready-1"},
+		{runner: "test-2", hostname: "unready"},
+		{runner: "test-3", hostname: "ready-3"},
+	}
+	hostnames, responding, notReady := respondingRunners(context.Background(), logr.Discard(), c, targets)
+
+	if strings.Join(hostnames, ",") != "ready-1,ready-3" {
+		t.Errorf("expected the responding hostnames in order, got %v", hostnames)
+	}
+	if !responding["test-1"] || responding["test-2"] || !responding["test-3"] {
+		t.Errorf("unexpected responding runners: %v", responding)
+	}
+	if !errors.Is(notReady, ErrRunnerNotReady) || !strings.Contains(notReady.Error(), "unready") {
+		t.Errorf("expected the error of the unready runner, got %v", notReady)
+	}
+}
+
+func Test_exitedRunners(t *testing.T) {
+	t.Parallel()
+
+	runnerPod := func(job string, phase corev1.PodPhase, k6State corev1.ContainerState) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: job + "-abcde", Labels: map[string]string{"job-name": job}},
+			Status: corev1.PodStatus{
+				Phase:             phase,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "k6", State: k6State}},
+			},
+		}
+	}
+	exited := func(code int32) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: code}}
+	}
+
+	pods := []corev1.Pod{
+		runnerPod("test-1", corev1.PodSucceeded, exited(0)),
+		runnerPod("test-2", corev1.PodFailed, exited(thresholdsFailedExitCode)),
+		runnerPod("test-3", corev1.PodFailed, exited(1)),
+		runnerPod("test-4", corev1.PodRunning, corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}),
+	}
+
+	finished := exitedRunners(pods)
+	if len(finished) != 2 || !finished["test-1"] || !finished["test-2"] {
+		t.Errorf("expected test-1 and test-2 to be finished, got %v", finished)
+	}
+}
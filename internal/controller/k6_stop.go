@@ -2,16 +2,16 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/cloud"
 	"github.com/grafana/k6-operator/pkg/resources/jobs"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // StopJobs in the Ready phase using a curl container
@@ -22,24 +22,22 @@ func StopJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *Tes
 		log = log.WithValues("testRunId", k6.GetStatus().TestRunID)
 	}
 
-	selector := labels.SelectorFromSet(map[string]string{
-		"app":    "k6",
-		"k6_cr":  k6.NamespacedName().Name,
-		"runner": "true",
-	})
-
-	opts := &client.ListOptions{LabelSelector: selector, Namespace: k6.NamespacedName().Namespace}
-
-	var hostnames []string
-	sl := &v1.ServiceList{}
-
-	if err = r.List(ctx, sl, opts); err != nil {
-		log.Error(err, "Could not list services")
+	targets, err := r.listRunnerTargets(ctx, log, k6)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Could not list %s", runnerTargetsKind(k6)))
 		return res, nil
 	}
 
-	for _, service := range sl.Items {
-		hostnames = append(hostnames, service.Spec.ClusterIP)
+	hostnames := make([]string, 0, len(targets))
+	for _, target := range targets {
+		hostnames = append(hostnames, target.hostname)
+	}
+
+	if k6.Standalone() {
+		if hostnames, err = r.runnerPodIPs(ctx, k6); err != nil {
+			log.Error(err, "Could not list pods")
+			return res, nil
+		}
 	}
 
 	stopJob := jobs.NewStopJob(k6, hostnames)
@@ -65,7 +63,93 @@ func StopJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *Tes
 	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
 		return ctrl.Result{}, err
 	} else if updateHappened {
-		return ctrl.Result{RequeueAfter: time.Second}, nil
+		return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// StopRunners stops the test on request of the user with spec.stopped.
+// Unlike deletion of the TestRun, the runner pods are kept,
+// so that their final summaries can be read.
+func StopRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	log.Info("TestRun is stopped by the user: stopping the runners")
+
+	reason := "The test run was stopped with spec.stopped of TestRun"
+	events := cloud.Events{cloud.AbortEvent(cloud.OriginUser)}
+	events.WithDetail(reason)
+	return stopRunners(ctx, log, k6, r, reason, &events)
+}
+
+// maxDurationExceeded shows whether the test has been running for longer
+// than MaxDuration, measured from the start of the runners.
+func maxDurationExceeded(k6 *v1alpha1.TestRun) bool {
+	maxDuration, startTime := k6.GetSpec().MaxDuration, k6.GetStatus().StartTime
+	if maxDuration == nil || maxDuration.Duration <= 0 || startTime == nil {
+		return false
+	}
+	if k6.GetStatus().RunnersKeptUntil != nil {
+		// the test is over, the runners are only kept for inspection
+		return false
+	}
+	return time.Since(startTime.Time) > maxDuration.Duration
+}
+
+// StopAfterMaxDuration stops the test which has been running for longer than MaxDuration.
+func StopAfterMaxDuration(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	reason := fmt.Sprintf("The test run has exceeded its maxDuration of %s", k6.GetSpec().MaxDuration.Duration)
+	log.Info(reason + ": stopping the runners")
+	r.recordEvent(k6, v1.EventTypeWarning, "MaxDurationExceeded", reason)
+
+	events := cloud.ErrorEvent(cloud.K6OperatorAbortError).
+		WithDetail(reason).
+		WithAbort()
+	return stopRunners(ctx, log, k6, r, reason, events)
+}
+
+// stopRunners stops the test on all runners for the given reason, which is
+// kept in the status and sent to the cloud with events for cloud test runs.
+func stopRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, reason string, events *cloud.Events) (ctrl.Result, error) {
+	if len(k6.GetStatus().TestRunID) > 0 {
+		log = log.WithValues("testRunId", k6.GetStatus().TestRunID)
+	}
+
+	if isCloudTestRun(k6) {
+		r.sendCloudEvents(ctx, log, k6, events)
+	}
+
+	k6.GetStatus().StopReason = reason
+
+	if r.UseLegacyStarter {
+		return StopJobs(ctx, log, k6, r)
+	}
+
+	var (
+		hostnames []string
+		err       error
+	)
+	if k6.Standalone() {
+		hostnames, err = r.runnerPodIPs(ctx, k6)
+	} else {
+		hostnames, err = r.hostnames(ctx, log, k6)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if stopped, err := StopK6FromOperators(ctx, log, k6, hostnames, r); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to stop k6 runners, %d/%d stopped", stopped, len(hostnames)))
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Changing stage of TestRun status to stopped")
+	k6.GetStatus().Stage = "stopped"
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionFalse)
+	v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunAborted, metav1.ConditionTrue)
+
+	if updateHappened, err := r.UpdateStatus(ctx, k6, log); err != nil {
+		return ctrl.Result{}, err
+	} else if updateHappened {
+		return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
 	}
 	return ctrl.Result{}, nil
 }
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_maxDurationExceeded(t *testing.T) {
+	t.Parallel()
+
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	testCases := []struct {
+		name        string
+		maxDuration *metav1.Duration
+		startTime   *metav1.Time
+		exceeded    bool
+	}{
+		{"no max duration", nil, &started, false},
+		{"not started", &metav1.Duration{Duration: time.Minute}, nil, false},
+		{"within max duration", &metav1.Duration{Duration: 2 * time.Hour}, &started, false},
+		{"exceeded", &metav1.Duration{Duration: 30 * time.Minute}, &started, true},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			Spec:   v1alpha1.TestRunSpec{MaxDuration: tc.maxDuration},
+			Status: v1alpha1.TestRunStatus{StartTime: tc.startTime},
+		}
+		if exceeded := maxDurationExceeded(k6); exceeded != tc.exceeded {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.exceeded, exceeded)
+		}
+	}
+}
+
+func Test_StopAfterMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	var stopped atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPatch {
+			stopped.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := newTestHTTPWorkers(1, srv)
+	go func() { _ = w.Start(ctx) }()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			MaxDuration: &metav1.Duration{Duration: 30 * time.Minute},
+		},
+		Status: v1alpha1.TestRunStatus{Stage: "started", StartTime: &started},
+	}
+	v1alpha1.Initialize(k6)
+	v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionTrue)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-1-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "k6", "k6_cr": "test", "runner": "true", "k6_uid": "test-uid"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6, pod).WithStatusSubresource(k6).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme, httpWorkers: w}
+
+	if _, err := StopAfterMaxDuration(ctx, logr.Discard(), k6, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stopped.Load() != 1 {
+		t.Errorf("expected 1 stop request, got %d", stopped.Load())
+	}
+
+	updated := &v1alpha1.TestRun{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(k6), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status.Stage != "stopped" || len(updated.Status.StopReason) == 0 {
+		t.Errorf("expected the test run to be stopped with a reason, got stage %q and %q", updated.Status.Stage, updated.Status.StopReason)
+	}
+}
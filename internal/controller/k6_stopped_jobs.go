@@ -5,20 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"net"
 
 	"github.com/go-logr/logr"
 	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/testrun"
 	k6api "go.k6.io/k6/api/v1"
 	batchv1 "k8s.io/api/batch/v1"
-	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func isJobRunning(log logr.Logger, service *v1.Service) bool {
-	resp, err := http.Get(fmt.Sprintf("http://%v:6565/v1/status", service.Spec.ClusterIP))
+func isJobRunning(log logr.Logger, hostname string) bool {
+	resp, err := testrun.RunnerClient().Get(fmt.Sprintf("http://%v/v1/status", net.JoinHostPort(hostname, "6565")))
 	if err != nil {
 		return false
 	}
@@ -26,7 +25,7 @@ func isJobRunning(log logr.Logger, service *v1.Service) bool {
 	// Response has been received so assume the job is running.
 
 	if resp.StatusCode >= 400 {
-		log.Error(err, fmt.Sprintf("status from from runner job %v is %d", service.Name, resp.StatusCode))
+		log.Error(err, fmt.Sprintf("status from from runner %v is %d", hostname, resp.StatusCode))
 		return true
 	}
 
@@ -34,13 +33,13 @@ func isJobRunning(log logr.Logger, service *v1.Service) bool {
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Error(err, fmt.Sprintf("Error on reading status of the runner job %v", service.Name))
+		log.Error(err, fmt.Sprintf("Error on reading status of the runner %v", hostname))
 		return true
 	}
 
 	var status k6api.StatusJSONAPI
 	if err := json.Unmarshal(data, &status); err != nil {
-		log.Error(err, fmt.Sprintf("Error on parsing status of the runner job %v", service.Name))
+		log.Error(err, fmt.Sprintf("Error on parsing status of the runner %v", hostname))
 		return true
 	}
 
@@ -55,30 +54,20 @@ func StoppedJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *
 
 	log.Info("Waiting for pods to stop the test run")
 
-	selector := labels.SelectorFromSet(map[string]string{
-		"app":    "k6",
-		"k6_cr":  k6.NamespacedName().Name,
-		"runner": "true",
-	})
-
-	opts := &client.ListOptions{LabelSelector: selector, Namespace: k6.NamespacedName().Namespace}
-
-	sl := &v1.ServiceList{}
-
-	if err := r.List(ctx, sl, opts); err != nil {
-		log.Error(err, "Could not list services")
+	targets, err := r.listRunnerTargets(ctx, log, k6)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Could not list %s", runnerTargetsKind(k6)))
 		return
 	}
 
 	var runningJobs int32
-	for _, service := range sl.Items {
-
-		if isJobRunning(log, &service) {
+	for _, target := range targets {
+		if isJobRunning(log, target.hostname) {
 			runningJobs++
 		}
 	}
 
-	log.Info(fmt.Sprintf("%d/%d runners stopped execution", k6.GetSpec().Parallelism-runningJobs, k6.GetSpec().Parallelism))
+	log.Info(fmt.Sprintf("%d/%d runners stopped execution", k6.Runners()-runningJobs, k6.Runners()))
 
 	if runningJobs > 0 {
 		return
@@ -99,16 +88,9 @@ func KillJobs(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *Tes
 
 	log.Info("Killing all runner jobs.")
 
-	selector := labels.SelectorFromSet(map[string]string{
-		"app":    "k6",
-		"k6_cr":  k6.NamespacedName().Name,
-		"runner": "true",
-	})
-
-	opts := &client.ListOptions{LabelSelector: selector, Namespace: k6.NamespacedName().Namespace}
 	jl := &batchv1.JobList{}
 
-	if err = r.List(ctx, jl, opts); err != nil {
+	if err = r.listRunners(ctx, k6, jl); err != nil {
 		log.Error(err, "Could not list jobs")
 		return
 	}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/resources/jobs"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// uploadFinalizer keeps a TestRun with ArtifactUpload until its output files are uploaded.
+const uploadFinalizer = "k6.io/artifact-upload"
+
+// ensureUploadFinalizer adds uploadFinalizer to the TestRun with ArtifactUpload
+// and removes it if ArtifactUpload was dropped. It returns true if the TestRun
+// was updated.
+func ensureUploadFinalizer(ctx context.Context, k6 *v1alpha1.TestRun, r *TestRunReconciler) (bool, error) {
+	if !k6.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+
+	var updated bool
+	if k6.GetSpec().ArtifactUpload != nil {
+		updated = controllerutil.AddFinalizer(k6, uploadFinalizer)
+	} else {
+		updated = controllerutil.RemoveFinalizer(k6, uploadFinalizer)
+	}
+	if !updated {
+		return false, nil
+	}
+	return true, r.Update(ctx, k6)
+}
+
+// UploadArtifacts runs the upload Job of the deleted TestRun and releases
+// uploadFinalizer once the Job has finished or the timeout is over, so that
+// a failed upload doesn't block the deletion forever. The runner Jobs are
+// deleted first and the upload starts once their pods are gone: the runners
+// mustn't write output files anymore and they may hold the volume.
+// The upload Job isn't owned by the TestRun: it would be deleted together
+// with it in case of foreground deletion. It's deleted by the operator instead.
+func UploadArtifacts(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(k6, uploadFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	upload := k6.GetSpec().ArtifactUpload
+	if upload == nil || k6.GetSpec().OutputVolume == nil {
+		return releaseUploadFinalizer(ctx, log, k6, r, nil)
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: k6.Namespace, Name: jobs.UploadJobName(k6)}, job)
+	if k8sErrors.IsNotFound(err) {
+		gone, err := deleteRunners(ctx, log, k6, r)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !gone {
+			if time.Since(k6.DeletionTimestamp.Time) > upload.TimeoutOrDefault() {
+				log.Info("Runners are still there after the upload timeout")
+				r.recordEvent(k6, corev1.EventTypeWarning, "ArtifactUploadFailed",
+					fmt.Sprintf("Runners were not gone within %v: output files were not uploaded", upload.TimeoutOrDefault()))
+				return releaseUploadFinalizer(ctx, log, k6, r, nil)
+			}
+			log.Info("Waiting for the runners to be gone before the upload")
+			return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+		}
+
+		job = jobs.NewUploadJob(k6)
+		if err = r.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+			log.Error(err, "Failed to create the upload job")
+			return ctrl.Result{}, err
+		}
+		log.Info(fmt.Sprintf("Created upload job %s", job.Name))
+		return ctrl.Result{RequeueAfter: r.requeue().Medium}, nil
+	} else if err != nil {
+		log.Error(err, "Failed to get the upload job")
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		log.Info("Output files were uploaded")
+		r.recordEvent(k6, corev1.EventTypeNormal, "ArtifactsUploaded", "Output files were uploaded")
+	case uploadFailed(job):
+		log.Info("Upload job has failed")
+		r.recordEvent(k6, corev1.EventTypeWarning, "ArtifactUploadFailed", "Upload job has failed: output files might be lost")
+	case time.Since(k6.DeletionTimestamp.Time) > upload.TimeoutOrDefault():
+		log.Info("Upload job has timed out")
+		r.recordEvent(k6, corev1.EventTypeWarning, "ArtifactUploadFailed",
+			fmt.Sprintf("Upload job has not finished within %v: output files might be lost", upload.TimeoutOrDefault()))
+	default:
+		log.Info("Waiting for the upload job to finish")
+		return ctrl.Result{RequeueAfter: r.requeue().Medium}, nil
+	}
+
+	return releaseUploadFinalizer(ctx, log, k6, r, job)
+}
+
+// deleteRunners deletes the runner Jobs of the TestRun and returns true once
+// none of their pods is left.
+func deleteRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler) (bool, error) {
+	opts := []client.ListOption{
+		client.InNamespace(k6.NamespacedName().Namespace),
+		client.MatchingLabels{"app": "k6", "k6_cr": k6.NamespacedName().Name, "runner": "true"},
+	}
+
+	if err := deleteRunObjects(ctx, log, k6, r, &batchv1.JobList{}, "job", opts,
+		client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		return false, err
+	}
+
+	pl := &corev1.PodList{}
+	if err := r.List(ctx, pl, opts...); err != nil {
+		log.Error(err, "Could not list pods")
+		return false, err
+	}
+	return len(pl.Items) == 0, nil
+}
+
+func uploadFailed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseUploadFinalizer deletes the upload Job, if any, and lets the deletion
+// of the TestRun proceed.
+func releaseUploadFinalizer(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, job *batchv1.Job) (ctrl.Result, error) {
+	if job != nil {
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			// the job is left behind but it mustn't block the deletion
+			log.Error(err, fmt.Sprintf("Failed to delete upload job %s", job.Name))
+		}
+	}
+
+	controllerutil.RemoveFinalizer(k6, uploadFinalizer)
+	if err := r.Update(ctx, k6); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
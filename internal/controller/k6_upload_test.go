@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/resources/jobs"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_UploadArtifacts(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	newDeletedTestRun := func(name string, deleted time.Time) *v1alpha1.TestRun {
+		return &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Finalizers:        []string{uploadFinalizer},
+				DeletionTimestamp: &metav1.Time{Time: deleted},
+			},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism:    2,
+				OutputVolume:   &v1alpha1.K6OutputVolume{ClaimName: "results"},
+				ArtifactUpload: &v1alpha1.K6ArtifactUpload{Image: "amazon/aws-cli"},
+			},
+		}
+	}
+	uploaded := newDeletedTestRun("uploaded", time.Now())
+	uploaded.UID = "uploaded-uid"
+	timedOut := newDeletedTestRun("timed-out", time.Now().Add(-time.Hour))
+
+	runnerLabels := map[string]string{"app": "k6", "k6_cr": "uploaded", "runner": "true"}
+	runnerJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:            "uploaded-1",
+		Namespace:       "default",
+		Labels:          runnerLabels,
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(uploaded, v1alpha1.GroupVersion.WithKind("TestRun"))},
+	}}
+	runnerPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "uploaded-1-abcde", Namespace: "default", Labels: runnerLabels}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(uploaded, timedOut, runnerJob, runnerPod).Build()
+	r := &TestRunReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+	ctx := context.Background()
+
+	for _, k6 := range []*v1alpha1.TestRun{uploaded, timedOut} {
+		if _, err := UploadArtifacts(ctx, logr.Discard(), k6, r); err != nil {
+			t.Fatalf("%s: unexpected error: %v", k6.Name, err)
+		}
+	}
+
+	// the upload waits for the runners to be gone
+	if err := c.Get(ctx, client.ObjectKeyFromObject(runnerJob), &batchv1.Job{}); !k8sErrors.IsNotFound(err) {
+		t.Fatalf("expected the runner job to be deleted, got error: %v", err)
+	}
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: jobs.UploadJobName(uploaded)}, job); !k8sErrors.IsNotFound(err) {
+		t.Fatalf("expected no upload job while the runner pod is there, got error: %v", err)
+	}
+	if err := c.Delete(ctx, runnerPod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := UploadArtifacts(ctx, logr.Discard(), uploaded, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the deletion waits for the upload
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: jobs.UploadJobName(uploaded)}, job); err != nil {
+		t.Fatalf("expected the upload job to be created, got error: %v", err)
+	}
+	if _, err := UploadArtifacts(ctx, logr.Discard(), uploaded, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(uploaded), &v1alpha1.TestRun{}); err != nil {
+		t.Fatalf("expected the test run to wait for the upload, got error: %v", err)
+	}
+
+	job.Status.Succeeded = 1
+	if err := c.Status().Update(ctx, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the deletion proceeds once the upload is done or it has timed out
+	for _, k6 := range []*v1alpha1.TestRun{uploaded, timedOut} {
+		if _, err := UploadArtifacts(ctx, logr.Discard(), k6, r); err != nil {
+			t.Fatalf("%s: unexpected error: %v", k6.Name, err)
+		}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(k6), &v1alpha1.TestRun{}); !k8sErrors.IsNotFound(err) {
+			t.Errorf("%s: expected the test run to be deleted, got error: %v", k6.Name, err)
+		}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: jobs.UploadJobName(k6)}, &batchv1.Job{}); !k8sErrors.IsNotFound(err) {
+			t.Errorf("%s: expected the upload job to be deleted, got error: %v", k6.Name, err)
+		}
+	}
+}
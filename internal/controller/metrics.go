@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics of k6-operator are registered with controller-runtime registry,
+// so they're exposed on the same `/metrics` endpoint as the rest of the manager metrics.
+
+var (
+	testRunsDesc = prometheus.NewDesc(
+		"k6_operator_testruns",
+		"Number of TestRuns by stage.",
+		[]string{"stage"}, nil,
+	)
+
+	timeToStage = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "k6_operator_testrun_time_to_stage_seconds",
+			Help:    "Time from creation of a TestRun until it reached the stage.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600, 7200, 14400},
+		},
+		[]string{"stage"},
+	)
+
+	runnerJobsCreationDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "k6_operator_runner_jobs_creation_duration_seconds",
+			Help:    "Time spent on creation of all runner jobs and services of a TestRun.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+		},
+	)
+
+	startSpread = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "k6_operator_start_spread_seconds",
+			Help:    "Time between the first and the last runner of a TestRun being started by the operator.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		},
+	)
+
+	startFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k6_operator_start_failures_total",
+			Help: "Number of failed attempts to start k6 runners.",
+		},
+		[]string{"reason"},
+	)
+
+	httpRequestsQueued = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6_operator_http_requests_queued",
+			Help: "Number of requests to runners waiting for a free HTTP worker.",
+		},
+	)
+
+	httpRequestsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6_operator_http_requests_in_flight",
+			Help: "Number of requests to runners being sent by the operator.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(timeToStage, runnerJobsCreationDuration, startSpread, startFailures,
+		httpRequestsQueued, httpRequestsInFlight)
+}
+
+// testRunsCollector counts TestRuns by stage on each scrape, using the cache of the manager.
+type testRunsCollector struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+func (c *testRunsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- testRunsDesc
+}
+
+func (c *testRunsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list := &v1alpha1.TestRunList{}
+	if err := c.client.List(ctx, list); err != nil {
+		c.log.Error(err, "Could not list TestRuns for metrics")
+		return
+	}
+
+	stages := map[v1alpha1.Stage]int{}
+	for _, k6 := range list.Items {
+		stages[k6.GetStatus().Stage]++
+	}
+
+	for stage, count := range stages {
+		ch <- prometheus.MustNewConstMetric(testRunsDesc, prometheus.GaugeValue, float64(count), string(stage))
+	}
+}
+
+// observeStage records the time it took the TestRun to reach its current stage.
+func observeStage(k6 *v1alpha1.TestRun) {
+	if len(k6.GetStatus().Stage) == 0 || k6.CreationTimestamp.IsZero() {
+		return
+	}
+	timeToStage.WithLabelValues(string(k6.GetStatus().Stage)).Observe(time.Since(k6.CreationTimestamp.Time).Seconds())
+}
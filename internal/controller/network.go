@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+const (
+	// unreachableAfter is how long connections to running runners can fail
+	// before it's reported as a likely network problem.
+	unreachableAfter = 2 * time.Minute
+
+	// apiUnavailableAfter is how long running runners can refuse connections
+	// before it's reported as a likely image problem. k6 opens its REST API
+	// right after the start, so it's shorter than unreachableAfter.
+	apiUnavailableAfter = time.Minute
+
+	// networkCheckTimeout limits connection attempts of the network check.
+	networkCheckTimeout = 5 * time.Second
+
+	errUnreachableHint = "Runners are running but the operator cannot connect to them on port 6565: " +
+		"check that no NetworkPolicy blocks traffic from the operator to the runners."
+
+	errAPIUnavailableHint = "Runners are running but refuse connections on port 6565: " +
+		"check that the runner image is k6 and that it exposes the k6 REST API on port 6565."
+)
+
+// isConnectionError shows whether err is a failure to connect to a runner,
+// as opposed to an error response from k6.
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isConnectionRefused shows whether err is a refused connection to a runner:
+// the pod is reachable but nothing listens on the port.
+func isConnectionRefused(err error) bool {
+	return isConnectionError(err) && errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// runnersStartTime returns when the last of the runner pods has started.
+func runnersStartTime(pods []corev1.Pod) (t time.Time) {
+	for _, pod := range pods {
+		if pod.Status.StartTime != nil && pod.Status.StartTime.After(t) {
+			t = pod.Status.StartTime.Time
+		}
+	}
+	return t
+}
+
+// hintUnreachableRunners reports in the status that the runners cannot be
+// reached, if connections to them keep failing a while after their start.
+// Refused connections usually mean that the runner image doesn't expose
+// the k6 REST API, and other failures that the traffic from the operator
+// is blocked.
+func hintUnreachableRunners(ctx context.Context, log logr.Logger, k6 *v1alpha1.TestRun, r *TestRunReconciler, err error, since time.Time) {
+	if !isConnectionError(err) || since.IsZero() {
+		return
+	}
+
+	switch {
+	case isConnectionRefused(err) && time.Since(since) >= apiUnavailableAfter:
+		log.Info(errAPIUnavailableHint)
+		k6.GetStatus().Waiting = errAPIUnavailableHint
+		v1alpha1.UpdateCondition(k6, v1alpha1.RunnerAPIUnavailable, metav1.ConditionTrue)
+	case time.Since(since) >= unreachableAfter:
+		log.Info(errUnreachableHint)
+		k6.GetStatus().Waiting = errUnreachableHint
+	default:
+		return
+	}
+
+	_, _ = r.UpdateStatus(ctx, k6, log)
+}
+
+// NetworkCheck returns a health checker which connects to address, to verify
+// that the operator can reach the network of the runners, e.g. a Service in
+// their namespace behind the same NetworkPolicies. There is nothing to check
+// if address is empty.
+func NetworkCheck(address string) healthz.Checker {
+	if len(address) == 0 {
+		return healthz.Ping
+	}
+
+	return func(_ *http.Request) error {
+		conn, err := net.DialTimeout("tcp", address, networkCheckTimeout)
+		if err != nil {
+			return fmt.Errorf("cannot connect to %s: %w", address, err)
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/testrun"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_isConnectionError(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	address := l.Addr().String()
+	_ = l.Close()
+
+	// nothing listens on the address anymore
+	_, notReady := testrun.RunnerClient().Get(fmt.Sprintf("http://%s/v1/status", address))
+	if notReady == nil {
+		t.Fatal("expected an error")
+	}
+	if !isConnectionError(fmt.Errorf("%v %w: %w", address, ErrRunnerNotReady, notReady)) {
+		t.Errorf("expected a connection error, got %v", notReady)
+	}
+
+	if isConnectionError(errors.New("status request returned 500")) {
+		t.Error("expected an error response not to be a connection error")
+	}
+}
+
+func Test_NetworkCheck(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	address := l.Addr().String()
+
+	if err := NetworkCheck(address)(nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_ = l.Close()
+	if err := NetworkCheck(address)(nil); err == nil {
+		t.Error("expected an error for the closed address")
+	}
+
+	if err := NetworkCheck("")(nil); err != nil {
+		t.Errorf("expected no check without an address, got %v", err)
+	}
+}
+
+func Test_hintUnreachableRunners(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	address := l.Addr().String()
+	_ = l.Close()
+
+	_, refused := testrun.RunnerClient().Get(fmt.Sprintf("http://%s/v1/status", address))
+	if !isConnectionRefused(refused) {
+		t.Fatalf("expected a refused connection, got %v", refused)
+	}
+	timedOut := &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	if isConnectionRefused(timedOut) {
+		t.Fatal("expected a timeout not to be a refused connection")
+	}
+
+	testCases := []struct {
+		name        string
+		err         error
+		since       time.Duration
+		waiting     string
+		unavailable bool
+	}{
+		{"refused shortly after the start", refused, 30 * time.Second, "", false},
+		{"refused for a while", refused, 90 * time.Second, errAPIUnavailableHint, true},
+		{"timeout for a while", timedOut, 90 * time.Second, "", false},
+		{"timeout for long", timedOut, 3 * time.Minute, errUnreachableHint, false},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Status:     v1alpha1.TestRunStatus{Stage: "created"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build()
+		r := &TestRunReconciler{Client: c, Scheme: scheme}
+
+		hintUnreachableRunners(context.Background(), logr.Discard(), k6, r, tc.err, time.Now().Add(-tc.since))
+
+		if k6.Status.Waiting != tc.waiting {
+			t.Errorf("%s: expected waiting %q, got %q", tc.name, tc.waiting, k6.Status.Waiting)
+		}
+		if v1alpha1.IsTrue(k6, v1alpha1.RunnerAPIUnavailable) != tc.unavailable {
+			t.Errorf("%s: expected RunnerAPIUnavailable to be %v", tc.name, tc.unavailable)
+		}
+	}
+}
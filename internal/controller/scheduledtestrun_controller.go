@@ -0,0 +1,238 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	"github.com/grafana/k6-operator/api/v1alpha1"
+)
+
+const (
+	scheduledTestRunLabel = "k6.io/scheduled-test-run"
+
+	// maxMissedSchedules limits how many missed ticks are looked through
+	// when the operator was down for a while.
+	maxMissedSchedules = 100
+)
+
+// ScheduledTestRunReconciler reconciles a ScheduledTestRun object
+type ScheduledTestRunReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=k6.io,resources=scheduledtestruns,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=k6.io,resources=scheduledtestruns/status;scheduledtestruns/finalizers,verbs=get;update;patch
+
+// Reconcile creates a new TestRun from the ScheduledTestRun at each tick of its schedule
+func (r *ScheduledTestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("namespace", req.Namespace, "name", req.Name, "reconcileID", controller.ReconcileIDFromContext(ctx))
+
+	str := &v1alpha1.ScheduledTestRun{}
+	if err := r.Get(ctx, req.NamespacedName, str); err != nil {
+		if k8sErrors.IsNotFound(err) {
+			log.Info("Request deleted. Nothing to reconcile.")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Could not fetch request")
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	schedule, err := cron.ParseStandard(str.Spec.Schedule)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Invalid schedule `%s`", str.Spec.Schedule))
+		return ctrl.Result{}, nil
+	}
+
+	active, err := r.cleanupTestRuns(ctx, log, str)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cleanObj := str.DeepCopy()
+	str.Status.Active = nil
+	for _, k6 := range active {
+		str.Status.Active = append(str.Status.Active, k6.Name)
+	}
+
+	now := time.Now()
+	lastTime := str.CreationTimestamp.Time
+	if str.Status.LastScheduleTime != nil {
+		lastTime = str.Status.LastScheduleTime.Time
+	}
+
+	missed, next := mostRecentScheduleTime(schedule, lastTime, now)
+	result := ctrl.Result{RequeueAfter: next.Sub(now)}
+
+	if missed == nil || str.Spec.Suspend {
+		return result, r.updateStatus(ctx, log, str, cleanObj)
+	}
+
+	log.Info(fmt.Sprintf("Scheduling a test run for %s", missed.Format(time.RFC3339)))
+	str.Status.LastScheduleTime = &metav1.Time{Time: *missed}
+
+	if len(active) > 0 {
+		switch str.Spec.ConcurrencyPolicy {
+		case v1alpha1.AllowConcurrent:
+		case v1alpha1.ReplaceConcurrent:
+			for i := range active {
+				log.Info(fmt.Sprintf("Deleting unfinished TestRun %s to replace it", active[i].Name))
+				if err := r.Delete(ctx, &active[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+					log.Error(err, fmt.Sprintf("Failed to delete TestRun %s", active[i].Name))
+					return ctrl.Result{}, err
+				}
+			}
+			str.Status.Active = nil
+		default:
+			log.Info(fmt.Sprintf("Skipping the test run: %d TestRuns haven't finished yet", len(active)))
+			return result, r.updateStatus(ctx, log, str, cleanObj)
+		}
+	}
+
+	k6, err := r.newTestRun(str, *missed)
+	if err != nil {
+		log.Error(err, "Failed to generate TestRun")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.Create(ctx, k6); err != nil && !k8sErrors.IsAlreadyExists(err) {
+		log.Error(err, fmt.Sprintf("Failed to create TestRun %s", k6.Name))
+		return ctrl.Result{}, err
+	}
+
+	log.Info(fmt.Sprintf("Created TestRun %s", k6.Name))
+	str.Status.Active = append(str.Status.Active, k6.Name)
+
+	return result, r.updateStatus(ctx, log, str, cleanObj)
+}
+
+// cleanupTestRuns deletes finished TestRuns over the history limit and
+// returns the TestRuns which haven't finished yet.
+func (r *ScheduledTestRunReconciler) cleanupTestRuns(ctx context.Context, log logr.Logger, str *v1alpha1.ScheduledTestRun) ([]v1alpha1.TestRun, error) {
+	list := &v1alpha1.TestRunList{}
+	if err := r.List(ctx, list, client.InNamespace(str.Namespace), client.MatchingLabels{scheduledTestRunLabel: str.Name}); err != nil {
+		log.Error(err, "Could not list TestRuns")
+		return nil, err
+	}
+
+	var active, finished []v1alpha1.TestRun
+	for _, k6 := range list.Items {
+		if !metav1.IsControlledBy(&k6, str) {
+			continue
+		}
+		if stage := k6.GetStatus().Stage; stage == "finished" || stage == "error" {
+			finished = append(finished, k6)
+		} else {
+			active = append(active, k6)
+		}
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreationTimestamp.Before(&finished[j].CreationTimestamp)
+	})
+
+	for i := 0; i < len(finished)-str.GetHistoryLimit(); i++ {
+		if err := r.Delete(ctx, &finished[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			log.Error(err, fmt.Sprintf("Failed to delete old TestRun %s", finished[i].Name))
+			continue
+		}
+		log.Info(fmt.Sprintf("Deleted old TestRun %s", finished[i].Name))
+	}
+
+	return active, nil
+}
+
+func (r *ScheduledTestRunReconciler) newTestRun(str *v1alpha1.ScheduledTestRun, scheduledTime time.Time) (*v1alpha1.TestRun, error) {
+	labels := make(map[string]string)
+	for k, v := range str.Spec.TestRunTemplate.Metadata.Labels {
+		labels[k] = v
+	}
+	labels[scheduledTestRunLabel] = str.Name
+
+	annotations := make(map[string]string)
+	for k, v := range str.Spec.TestRunTemplate.Metadata.Annotations {
+		annotations[k] = v
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			// same as CronJob: minutes are enough to be unique
+			Name:        fmt.Sprintf("%s-%d", str.Name, scheduledTime.Unix()/60),
+			Namespace:   str.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: *str.Spec.TestRunTemplate.Spec.DeepCopy(),
+	}
+
+	if err := ctrl.SetControllerReference(str, k6, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return k6, nil
+}
+
+func (r *ScheduledTestRunReconciler) updateStatus(ctx context.Context, log logr.Logger, str, cleanObj *v1alpha1.ScheduledTestRun) error {
+	if err := r.Status().Patch(ctx, str, client.MergeFrom(cleanObj)); err != nil {
+		log.Error(err, "Could not update status of custom resource")
+		return err
+	}
+	return nil
+}
+
+// mostRecentScheduleTime returns the latest tick of the schedule after lastTime
+// which is not later than now, if there is one, and the next tick after now.
+func mostRecentScheduleTime(schedule cron.Schedule, lastTime, now time.Time) (*time.Time, time.Time) {
+	var missed *time.Time
+
+	t := schedule.Next(lastTime)
+	for i := 0; !t.After(now) && i < maxMissedSchedules; i++ {
+		tick := t
+		missed = &tick
+		t = schedule.Next(t)
+	}
+
+	if !t.After(now) {
+		// too many ticks were missed: skip to the current time
+		t = schedule.Next(now)
+		missed = &now
+	}
+
+	return missed, t
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ScheduledTestRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ScheduledTestRun{}).
+		Owns(&v1alpha1.TestRun{}).
+		Complete(r)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func Test_mostRecentScheduleTime(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := cron.ParseStandard("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name           string
+		lastTime, now  time.Time
+		expectedMissed *time.Time
+		expectedNext   time.Time
+	}{
+		{
+			"no tick since last time",
+			at(10, 0), at(10, 30),
+			nil,
+			at(11, 0),
+		},
+		{
+			"one missed tick",
+			at(10, 0), at(11, 5),
+			timePtr(at(11, 0)),
+			at(12, 0),
+		},
+		{
+			"several missed ticks return the latest one",
+			at(10, 0), at(13, 30),
+			timePtr(at(13, 0)),
+			at(14, 0),
+		},
+		{
+			"tick exactly now",
+			at(10, 0), at(11, 0),
+			timePtr(at(11, 0)),
+			at(12, 0),
+		},
+		{
+			"too many missed ticks",
+			at(10, 0), at(10, 30).Add(200 * time.Hour),
+			timePtr(at(10, 30).Add(200 * time.Hour)),
+			at(11, 0).Add(200 * time.Hour),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			missed, next := mostRecentScheduleTime(schedule, testCase.lastTime, testCase.now)
+
+			if (missed == nil) != (testCase.expectedMissed == nil) ||
+				(missed != nil && !missed.Equal(*testCase.expectedMissed)) {
+				t.Errorf("expected missed tick %v, got %v", testCase.expectedMissed, missed)
+			}
+			if !next.Equal(testCase.expectedNext) {
+				t.Errorf("expected next tick %v, got %v", testCase.expectedNext, next)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusServerShutdownTimeout limits how long the status server waits for
+// requests in progress when the operator stops.
+const statusServerShutdownTimeout = 5 * time.Second
+
+// TestRunProgress is the status of a test run served by StatusServer.
+type TestRunProgress struct {
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Stage     v1alpha1.Stage `json:"stage"`
+
+	// RunnersReady shows whether all runners were ready to start the test.
+	RunnersReady bool  `json:"runnersReady"`
+	Runners      int32 `json:"runners"`
+
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Progress is the part of TotalDuration which has elapsed since StartTime,
+	// from 0 to 1. It's unknown until the test has started.
+	Progress *float64 `json:"progress,omitempty"`
+
+	Waiting          string                 `json:"waiting,omitempty"`
+	Error            string                 `json:"error,omitempty"`
+	ThresholdsPassed *bool                  `json:"thresholdsPassed,omitempty"`
+	Result           v1alpha1.TestRunResult `json:"result,omitempty"`
+}
+
+// NewTestRunProgress summarizes the status of k6 computed by the reconciler.
+func NewTestRunProgress(k6 *v1alpha1.TestRun, now time.Time) TestRunProgress {
+	status := k6.GetStatus()
+	p := TestRunProgress{
+		Namespace:        k6.Namespace,
+		Name:             k6.Name,
+		Stage:            status.Stage,
+		RunnersReady:     v1alpha1.IsTrue(k6, v1alpha1.RunnersReady),
+		Runners:          k6.Runners(),
+		StartTime:        status.StartTime,
+		Waiting:          status.Waiting,
+		Error:            status.Error,
+		ThresholdsPassed: status.ThresholdsPassed,
+		Result:           status.Result,
+	}
+
+	if status.StartTime != nil && status.TotalDuration != nil && status.TotalDuration.Duration > 0 {
+		end := now
+		if status.CompletionTime != nil {
+			end = status.CompletionTime.Time
+		}
+		progress := min(float64(end.Sub(status.StartTime.Time))/float64(status.TotalDuration.Duration), 1)
+		p.Progress = &progress
+	}
+
+	return p
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// StatusServer serves the status of test runs over HTTP at
+// /testruns/{namespace}/{name}, for external systems which don't need
+// to read pods or other objects of the test run. Requests must have
+// a bearer token of a user or service account which is allowed to get
+// the TestRun, e.g. with the testrun-viewer-role.
+type StatusServer struct {
+	Client client.Reader
+	// Reviewer creates the TokenReviews and SubjectAccessReviews of requests.
+	Reviewer client.Writer
+	Log      logr.Logger
+	Address  string
+}
+
+// Handler returns the handler of the status requests.
+func (s *StatusServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /testruns/{namespace}/{name}", s.serveTestRun)
+	return mux
+}
+
+// authorize checks that the bearer token of req belongs to a user who is
+// allowed to get the TestRun with the given key. It returns the HTTP status
+// of the response if they aren't.
+func (s *StatusServer) authorize(req *http.Request, key client.ObjectKey) (int, error) {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || len(token) == 0 {
+		return http.StatusUnauthorized, errors.New("bearer token is required")
+	}
+
+	tr := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := s.Reviewer.Create(req.Context(), tr); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to review the token: %w", err)
+	}
+	if !tr.Status.Authenticated {
+		return http.StatusUnauthorized, errors.New("invalid token")
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(tr.Status.User.Extra))
+	for k, v := range tr.Status.User.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		User:   tr.Status.User.Username,
+		UID:    tr.Status.User.UID,
+		Groups: tr.Status.User.Groups,
+		Extra:  extra,
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: key.Namespace,
+			Verb:      "get",
+			Group:     v1alpha1.GroupVersion.Group,
+			Resource:  "testruns",
+			Name:      key.Name,
+		},
+	}}
+	if err := s.Reviewer.Create(req.Context(), sar); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to review the access: %w", err)
+	}
+	if !sar.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("%s is not allowed to get the test run", tr.Status.User.Username)
+	}
+	return http.StatusOK, nil
+}
+
+func (s *StatusServer) serveTestRun(rw http.ResponseWriter, req *http.Request) {
+	k6 := &v1alpha1.TestRun{}
+	key := client.ObjectKey{Namespace: req.PathValue("namespace"), Name: req.PathValue("name")}
+	if code, err := s.authorize(req, key); err != nil {
+		if code == http.StatusInternalServerError {
+			s.Log.Error(err, "Failed to authorize the request", "testRun", key)
+		}
+		http.Error(rw, http.StatusText(code), code)
+		return
+	}
+
+	if err := s.Client.Get(req.Context(), key, k6); err != nil {
+		if k8sErrors.IsNotFound(err) {
+			http.Error(rw, "test run not found", http.StatusNotFound)
+			return
+		}
+		s.Log.Error(err, "Failed to get the test run", "testRun", key)
+		http.Error(rw, "failed to get the test run", http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(NewTestRunProgress(k6, time.Now())); err != nil {
+		s.Log.Error(err, "Failed to write the status of the test run", "testRun", key)
+	}
+}
+
+// Start serves the status until ctx is done.
+func (s *StatusServer) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), statusServerShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.Log.Info("Serving the status of test runs", "address", s.Address)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection is false: all replicas of the operator can serve the status.
+func (s *StatusServer) NeedLeaderElection() bool {
+	return false
+}
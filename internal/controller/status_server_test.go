@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_NewTestRunProgress(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	started := metav1.NewTime(now.Add(-5 * time.Minute))
+	k6 := &v1alpha1.TestRun{
+		Spec: v1alpha1.TestRunSpec{Parallelism: 2},
+		Status: v1alpha1.TestRunStatus{
+			Stage:         "started",
+			StartTime:     &started,
+			TotalDuration: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}
+	v1alpha1.UpdateCondition(k6, v1alpha1.RunnersReady, metav1.ConditionTrue)
+
+	p := NewTestRunProgress(k6, now)
+	if !p.RunnersReady || p.Runners != 2 {
+		t.Errorf("expected 2 ready runners, got %+v", p)
+	}
+	if p.Progress == nil || *p.Progress != 0.5 {
+		t.Errorf("expected half of the test to be done, got %v", p.Progress)
+	}
+
+	// the test might take longer than expected, e.g. with graceful stops
+	if p := NewTestRunProgress(k6, now.Add(time.Hour)); *p.Progress != 1 {
+		t.Errorf("expected the progress to be capped, got %v", *p.Progress)
+	}
+
+	k6.Status.StartTime = nil
+	if p := NewTestRunProgress(k6, now); p.Progress != nil {
+		t.Errorf("expected no progress before the start, got %v", *p.Progress)
+	}
+}
+
+func Test_StatusServer(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1alpha1.TestRunSpec{Parallelism: 1},
+		Status:     v1alpha1.TestRunStatus{Stage: "created", Waiting: "runner pods"},
+	}
+	// the token is the name of the user, only ci is allowed to get test runs
+	reviewer := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				if review.Spec.Token != "invalid" {
+					review.Status.Authenticated = true
+					review.Status.User.Username = review.Spec.Token
+				}
+			case *authorizationv1.SubjectAccessReview:
+				attrs := review.Spec.ResourceAttributes
+				review.Status.Allowed = review.Spec.User == "ci" && attrs.Verb == "get" &&
+					attrs.Group == "k6.io" && attrs.Resource == "testruns" && attrs.Namespace == "default"
+			}
+			return nil
+		},
+	}).Build()
+	s := &StatusServer{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(k6).WithStatusSubresource(k6).Build(),
+		Reviewer: reviewer,
+		Log:      logr.Discard(),
+	}
+	request := func(method, path, token string) *http.Request {
+		req := httptest.NewRequest(method, path, nil)
+		if len(token) > 0 {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, request(http.MethodGet, "/testruns/default/test", "ci"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var p TestRunProgress
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "test" || p.Stage != "created" || p.Waiting != "runner pods" {
+		t.Errorf("unexpected status: %+v", p)
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		code   int
+	}{
+		{"missing test run", http.MethodGet, "/testruns/default/missing", "ci", http.StatusNotFound},
+		{"no token", http.MethodGet, "/testruns/default/test", "", http.StatusUnauthorized},
+		{"invalid token", http.MethodGet, "/testruns/default/test", "invalid", http.StatusUnauthorized},
+		{"user without access", http.MethodGet, "/testruns/default/test", "other", http.StatusForbidden},
+		{"other namespace", http.MethodGet, "/testruns/kube-system/test", "ci", http.StatusForbidden},
+		{"delete", http.MethodDelete, "/testruns/default/test", "ci", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, request(tc.method, tc.path, tc.token))
+		if rec.Code != tc.code {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.code, rec.Code)
+		}
+	}
+}
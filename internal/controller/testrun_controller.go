@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.k6.io/k6/cloudapi"
@@ -31,11 +32,14 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -44,15 +48,100 @@ const (
 	k6CrLabelName = "k6_cr"
 )
 
+// RequeueIntervals are the delays between checks of a test run in progress.
+// Zero values fall back to DefaultRequeueIntervals.
+type RequeueIntervals struct {
+	// Short is used while waiting for something expected soon, e.g. runners to get ready.
+	Short time.Duration
+	// Medium is used while waiting for slower steps, e.g. initialization.
+	Medium time.Duration
+	// Long is used while the test is running.
+	Long time.Duration
+	// PreviousRun is used while the runners of a deleted previous run
+	// with the same name are going away.
+	PreviousRun time.Duration
+}
+
+// DefaultRequeueIntervals are the requeue intervals used by default.
+var DefaultRequeueIntervals = RequeueIntervals{
+	Short:  time.Second,
+	Medium: 5 * time.Second,
+	Long:   15 * time.Second,
+
+	PreviousRun: 10 * time.Second,
+}
+
+const (
+	// creationTimeout is how long the initializer and the runner pods may take
+	// to get ready before the configuration of the test run is considered off.
+	creationTimeout = 5 * time.Minute
+
+	// conditionSettleDelay is how long a test run waits after an update
+	// of a condition before acting on it, to avoid race conditions between
+	// different reconcile loops.
+	conditionSettleDelay = 5 * time.Second
+)
+
+// DefaultPreviousRunGracePeriod is how long a test run waits by default
+// for the runners of a deleted previous run with the same name to go away.
+const DefaultPreviousRunGracePeriod = 30 * time.Second
+
 // TestRunReconciler reconciles a K6 object
 type TestRunReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
 
-	// Note: here we assume that all users of the operator are allowed to use
-	// the same token / cloud client.
-	k6CloudClient *cloudapi.Client
+	// UseLegacyStarter makes the operator start the test with a starter job
+	// running curl, instead of sending the start requests itself.
+	UseLegacyStarter bool
+	// HTTPWorkers is the number of workers sending start requests to the runners
+	// when UseLegacyStarter is false.
+	HTTPWorkers int
+	// HTTPQueueSize is the number of requests which can wait for a free HTTP worker.
+	// If it's not positive, it's the same as HTTPWorkers.
+	HTTPQueueSize int
+	// StatusID and StatusType identify the status resource of k6 REST API
+	// in the requests of HTTP workers. Empty values default to the ones of k6.
+	StatusID   string
+	StatusType string
+	// MaxActiveTestRuns limits how many TestRuns can have runners at once,
+	// i.e. be in the created or started stage. Other TestRuns wait in the pending
+	// stage and are admitted in the order of their creation. Zero means no limit.
+	MaxActiveTestRuns int
+	// RequeueIntervals tune how often test runs in progress are checked.
+	RequeueIntervals RequeueIntervals
+	// PreviousRunGracePeriod is how long a test run waits for the runners
+	// of a deleted previous run with the same name to go away before it
+	// fails. If it's not positive, DefaultPreviousRunGracePeriod is used.
+	PreviousRunGracePeriod time.Duration
+	// Recorder records Kubernetes events of test runs. Events are not recorded if it's nil.
+	Recorder record.EventRecorder
+	// APIReader reads objects which aren't watched by the operator, e.g.
+	// ConfigMaps, directly from the API server, so that they aren't cached.
+	// Client is used if it's nil.
+	APIReader client.Reader
+	// DebugImages are the images of debug containers which can be injected
+	// into runners with DebugAnnotation, the first one by default.
+	// Debug containers are disabled if it's empty.
+	DebugImages []string
+
+	httpWorkers *httpWorkers
+
+	// listFailures keeps the time of the first of consecutive List failures
+	// of each test run, see listFailed.
+	listFailures sync.Map
+
+	// cloudClients keeps the cloud client of each cloud test run, since test
+	// runs might use different hosts and tokens, see createClient.
+	cloudClients sync.Map
+}
+
+// testRunCloudClient is the cloud client of a test run with the host and
+// the token it was created with.
+type testRunCloudClient struct {
+	host, token string
+	client      *cloudapi.Client
 }
 
 // Reconcile takes a K6 object and takes the appropriate action in the cluster
@@ -60,10 +149,17 @@ type TestRunReconciler struct {
 // +kubebuilder:rbac:groups=k6.io,resources=testruns/status;testruns/finalizers,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods;pods/log,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/ephemeralcontainers,verbs=update;patch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;create;update
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=list;watch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=create;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("namespace", req.Namespace, "name", req.Name, "reconcileID", controller.ReconcileIDFromContext(ctx))
@@ -74,6 +170,8 @@ func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	if err != nil {
 		if k8sErrors.IsNotFound(err) {
+			r.listFailures.Delete(req.NamespacedName)
+			r.cloudClients.Delete(req.NamespacedName)
 			log.Info("Request deleted. Nothing to reconcile.")
 			return ctrl.Result{}, nil
 		}
@@ -81,6 +179,13 @@ func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	if !k6.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(k6, uploadFinalizer) {
+		return UploadArtifacts(ctx, log, k6, r)
+	}
+	if updated, err := ensureUploadFinalizer(ctx, k6, r); err != nil || updated {
+		return ctrl.Result{Requeue: updated}, err
+	}
+
 	if k6.Spec.Parallelism < 1 {
 		err = fmt.Errorf("parallelism of TestRun cannot be less than 1; provided value is %d", k6.Spec.Parallelism)
 		log.Error(err, "Stopping reconciliation.")
@@ -100,17 +205,25 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 		// bootstrap the client
 		found, err := r.createClient(ctx, k6, log)
 		if err != nil {
-			log.Error(err, "A problem while getting token.")
-			return ctrl.Result{}, err
+			return failOnToken(ctx, log, k6, r, err)
 		}
 		if !found {
 			log.Info(fmt.Sprintf("Token `%s` is not found yet.", k6.GetSpec().Token))
-			return ctrl.Result{RequeueAfter: time.Second}, nil
+			return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
 		}
 	}
 
 	log.Info(fmt.Sprintf("Reconcile(); stage = %s", k6.GetStatus().Stage))
 
+	if restoreCloudTestRun(k6) {
+		// The cloud test run was created but the status wasn't updated:
+		// the stage is moved on from the ID in the next reconcile.
+		log.Info(fmt.Sprintf("Restoring cloud test run %s from annotations", k6.TestRunID()))
+		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Decision making here is now a mix between stages and conditions.
 	// TODO: refactor further.
 
@@ -122,6 +235,10 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 		}
 	}
 
+	if stage := k6.GetStatus().Stage; stage == "created" || stage == "started" {
+		InjectDebugContainer(ctx, log, k6, r)
+	}
+
 	switch k6.GetStatus().Stage {
 	case "":
 		log.Info("Initialize test")
@@ -134,7 +251,68 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 			return ctrl.Result{}, err
 		}
 
+		if err := r.validateServiceAccounts(ctx, k6); err != nil {
+			if !k8sErrors.IsNotFound(err) {
+				log.Error(err, "Could not get service account")
+				return ctrl.Result{}, err
+			}
+
+			log.Error(err, "Service account of TestRun does not exist")
+			log.Info("Changing stage of TestRun status to error")
+			k6.GetStatus().Stage = "error"
+			_, err := r.UpdateStatus(ctx, k6, log)
+			return ctrl.Result{}, err
+		}
+
+		if err := r.validateRuntimeClass(ctx, k6); err != nil {
+			if !k8sErrors.IsNotFound(err) {
+				log.Error(err, "Could not get runtime class")
+				return ctrl.Result{}, err
+			}
+
+			log.Error(err, "Runtime class of TestRun does not exist")
+			log.Info("Changing stage of TestRun status to error")
+			k6.GetStatus().Stage = "error"
+			k6.GetStatus().Error = err.Error()
+			_, err := r.UpdateStatus(ctx, k6, log)
+			return ctrl.Result{}, err
+		}
+
+		if err := r.validateThresholdsOverride(ctx, k6); err != nil {
+			if !k8sErrors.IsNotFound(err) && !errors.Is(err, errInvalidThresholds) {
+				log.Error(err, "Could not get thresholds override")
+				return ctrl.Result{}, err
+			}
+
+			log.Error(err, "Thresholds override of TestRun is invalid")
+			log.Info("Changing stage of TestRun status to error")
+			k6.GetStatus().Stage = "error"
+			k6.GetStatus().Error = err.Error()
+			_, err := r.UpdateStatus(ctx, k6, log)
+			return ctrl.Result{}, err
+		}
+
+		err := r.validateImages(k6)
+		if err == nil {
+			err = validateCloudHost(k6.GetSpec().CloudHost)
+		}
+		if err == nil {
+			err = k6.ValidateRunnerService()
+		}
+		if err != nil {
+			log.Error(err, "TestRun is invalid")
+			log.Info("Changing stage of TestRun status to error")
+			k6.GetStatus().Stage = "error"
+			k6.GetStatus().Error = err.Error()
+			_, err := r.UpdateStatus(ctx, k6, log)
+			return ctrl.Result{}, err
+		}
+
 		v1alpha1.Initialize(k6)
+		k6.GetStatus().Rerun = k6.GetAnnotations()[v1alpha1.RerunAnnotation]
+		if initTraceID(k6) {
+			log.Info(fmt.Sprintf("Trace ID of the test run is %s", k6.GetStatus().TraceID))
+		}
 
 		if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
 			return ctrl.Result{}, err
@@ -159,7 +337,7 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 				return res, errors.New("cannot find condition TestRunRunning")
 			} else {
 				// let's try this approach
-				if time.Since(t).Minutes() > 5 {
+				if time.Since(t) > creationTimeout {
 					msg := fmt.Sprintf(errMessageTooLong, "initializer pod", "initializer job and pod")
 					log.Info(msg)
 
@@ -167,7 +345,7 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 						events := cloud.ErrorEvent(cloud.K6OperatorStartError).
 							WithDetail(msg).
 							WithAbort()
-						cloud.SendTestRunEvents(r.k6CloudClient, k6.TestRunID(), log, events)
+						r.sendCloudEvents(ctx, log, k6, events)
 					}
 				}
 			}
@@ -209,12 +387,72 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 		return ctrl.Result{}, nil
 
 	case "initialized":
+		if probe := k6.GetSpec().PreconditionProbe; probe != nil {
+			if reason := r.checkPrecondition(ctx, k6); len(reason) > 0 {
+				log.Info(fmt.Sprintf("Waiting for precondition: %s", reason))
+				k6.GetStatus().Waiting = reason
+				if _, err := r.UpdateStatus(ctx, k6, log); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: probe.Period()}, nil
+			}
+			// cleared together with the change of stage
+			k6.GetStatus().Waiting = ""
+		}
+
+		return AdmitJobs(ctx, log, k6, r)
+
+	case "pending":
+		return AdmitJobs(ctx, log, k6, r)
+
+	case "creating":
+		// already admitted
 		return CreateJobs(ctx, log, k6, r)
 
 	case "created":
+		if failed, err := FailedJobs(ctx, log, k6, r); err != nil || failed {
+			return ctrl.Result{}, err
+		}
+		if canScaleOut(k6) {
+			return ScaleOutRunners(ctx, log, k6, r)
+		}
+		if changed, err := ChangedParallelism(ctx, log, k6, r); err != nil || changed {
+			return ctrl.Result{}, err
+		}
+
 		return StartJobs(ctx, log, k6, r)
 
 	case "started":
+		if failed, err := FailedJobs(ctx, log, k6, r); err != nil || failed {
+			return ctrl.Result{}, err
+		}
+		if changed, err := ChangedParallelism(ctx, log, k6, r); err != nil || changed {
+			return ctrl.Result{}, err
+		}
+
+		if k6.GetSpec().Stopped && v1alpha1.IsTrue(k6, v1alpha1.TestRunRunning) {
+			return StopRunners(ctx, log, k6, r)
+		}
+
+		if maxDurationExceeded(k6) && v1alpha1.IsTrue(k6, v1alpha1.TestRunRunning) {
+			return StopAfterMaxDuration(ctx, log, k6, r)
+		}
+
+		if k6.GetSpec().NodeLossPolicy == v1alpha1.FailOnNodeLoss && v1alpha1.IsTrue(k6, v1alpha1.TestRunRunning) {
+			if failed, err := FailedNodes(ctx, log, k6, r); err != nil || failed {
+				return ctrl.Result{}, err
+			}
+		}
+
+		replaceRunners := k6.GetSpec().TolerateRunnerEviction || k6.GetSpec().NodeLossPolicy == v1alpha1.RejoinOnNodeLoss
+		if replaceRunners && v1alpha1.IsTrue(k6, v1alpha1.TestRunRunning) {
+			if replacing, err := ReplaceEvictedRunners(ctx, log, k6, r); err != nil {
+				return ctrl.Result{}, err
+			} else if replacing {
+				return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
+			}
+		}
+
 		if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) && v1alpha1.IsTrue(k6, v1alpha1.CloudTestRunFinalized) {
 			// a fluke - nothing to do
 			return ctrl.Result{}, nil
@@ -237,11 +475,11 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 
 				// The test run reached a regular stop in execution so execute teardown
 				if v1alpha1.IsFalse(k6, v1alpha1.CloudTestRunAborted) && allJobsStopped {
-					hostnames, err := r.hostnames(ctx, log, false, k6.ListOptions())
+					hostnames, err := r.hostnames(ctx, log, k6)
 					if err != nil {
 						return ctrl.Result{}, nil
 					}
-					runTeardown(ctx, hostnames, log)
+					runTeardown(ctx, k6, hostnames, log)
 					v1alpha1.UpdateCondition(k6, v1alpha1.TeardownExecuted, metav1.ConditionTrue)
 
 					_, err = r.UpdateStatus(ctx, k6, log)
@@ -250,11 +488,12 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 				} else {
 					// Test runs can take a long time and usually they aren't supposed
 					// to be too quick. So check in only periodically.
-					return ctrl.Result{RequeueAfter: time.Second * 15}, nil
+					return ctrl.Result{RequeueAfter: r.requeue().Long}, nil
 				}
 			}
 		} else if !FinishJobs(ctx, log, k6, r) {
 			// wait for the test to finish
+			KeepRunners(ctx, log, k6, r)
 
 			// TODO: confirm if this check is needed given the check in the beginning of reconcile
 			if v1alpha1.IsTrue(k6, v1alpha1.CloudTestRun) && v1alpha1.IsFalse(k6, v1alpha1.CloudTestRunAborted) {
@@ -269,7 +508,7 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 
 			// Test runs can take a long time and usually they aren't supposed
 			// to be too quick. So check in only periodically.
-			return ctrl.Result{RequeueAfter: time.Second * 15}, nil
+			return ctrl.Result{RequeueAfter: r.requeue().Long}, nil
 		}
 
 		log.Info("All runner pods are finished")
@@ -277,6 +516,7 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 		// now mark it as stopped
 
 		if v1alpha1.IsTrue(k6, v1alpha1.TestRunRunning) {
+			SetRunnersResult(ctx, log, k6, r)
 			v1alpha1.UpdateCondition(k6, v1alpha1.TestRunRunning, metav1.ConditionFalse)
 
 			log.Info("Changing stage of TestRun status to stopped")
@@ -297,7 +537,7 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 			// Wait until all the test runs are stopped, kill jobs and proceed.
 			if StoppedJobs(ctx, log, k6, r) {
 				if allDeleted, err := KillJobs(ctx, log, k6, r); err != nil {
-					return ctrl.Result{RequeueAfter: time.Second}, err
+					return ctrl.Result{RequeueAfter: r.requeue().Short}, err
 				} else {
 					// if we just have deleted all jobs, update status and go for reconcile
 					if allDeleted {
@@ -318,17 +558,18 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 			// If TestRunRunning has just been updated, wait for a bit before
 			// acting, to avoid race condition between different reconcile loops.
 			t, _ := v1alpha1.LastUpdate(k6, v1alpha1.TestRunRunning)
-			if time.Since(t) < 5*time.Second {
-				return ctrl.Result{RequeueAfter: time.Second * 2}, nil
+			if time.Since(t) < conditionSettleDelay {
+				return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
 			}
 
-			if err = cloud.FinishTestRun(r.k6CloudClient, k6.GetStatus().TestRunID); err != nil {
-				log.Error(err, "Failed to finalize the test run with cloud output")
-				return ctrl.Result{}, nil
+			// The test run is finished locally only once the cloud knows
+			// about it: the cleanup would leave it running there forever.
+			if err = FinalizeCloudTestRun(log, k6, r); err != nil {
+				if delay := retryCloudFinalize(ctx, log, k6, r, err, time.Since(t)); delay > 0 {
+					return ctrl.Result{RequeueAfter: delay}, nil
+				}
 			} else {
-				log.Info(fmt.Sprintf("Cloud test run %s was finalized successfully", k6.GetStatus().TestRunID))
-
-				v1alpha1.UpdateCondition(k6, v1alpha1.CloudTestRunFinalized, metav1.ConditionTrue)
+				k6.GetStatus().Waiting = ""
 			}
 		}
 
@@ -340,12 +581,24 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 			return ctrl.Result{}, err
 		}
 
-		return ctrl.Result{RequeueAfter: time.Second}, nil
+		return ctrl.Result{RequeueAfter: r.requeue().Short}, nil
 
 	case "error", "finished":
+		if rerunRequested(k6) {
+			return RerunTestRun(ctx, log, k6, r)
+		}
+
+		// runners are done so there is nothing to protect anymore
+		if k6.GetSpec().DisruptionBudget {
+			r.deleteDisruptionBudget(ctx, log, k6)
+		}
+
 		// delete if configured
 		if k6.GetSpec().Cleanup == "post" {
 			log.Info("Cleaning up all resources")
+			if output := k6.GetSpec().OutputVolume; output != nil && output.DeleteOnCleanup {
+				r.deleteOutputVolumes(ctx, log, k6)
+			}
 			_ = r.Delete(ctx, k6)
 		}
 		// notify if configured
@@ -357,8 +610,54 @@ func (r *TestRunReconciler) reconcile(ctx context.Context, req ctrl.Request, log
 	return ctrl.Result{}, err
 }
 
+// requeue returns the requeue intervals, with defaults for those not set.
+func (r *TestRunReconciler) requeue() RequeueIntervals {
+	intervals := r.RequeueIntervals
+	if intervals.Short <= 0 {
+		intervals.Short = DefaultRequeueIntervals.Short
+	}
+	if intervals.Medium <= 0 {
+		intervals.Medium = DefaultRequeueIntervals.Medium
+	}
+	if intervals.Long <= 0 {
+		intervals.Long = DefaultRequeueIntervals.Long
+	}
+	if intervals.PreviousRun <= 0 {
+		intervals.PreviousRun = DefaultRequeueIntervals.PreviousRun
+	}
+	return intervals
+}
+
+// previousRunGracePeriod returns PreviousRunGracePeriod or its default.
+func (r *TestRunReconciler) previousRunGracePeriod() time.Duration {
+	if r.PreviousRunGracePeriod <= 0 {
+		return DefaultPreviousRunGracePeriod
+	}
+	return r.PreviousRunGracePeriod
+}
+
 // SetupWithManager sets up a managed controller that will reconcile all events for the K6 CRD
 func (r *TestRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := metrics.Registry.Register(&testRunsCollector{
+		client: mgr.GetClient(),
+		log:    r.Log,
+	}); err != nil {
+		return err
+	}
+
+	if !r.UseLegacyStarter {
+		r.httpWorkers = newHTTPWorkers(r.HTTPWorkers, r.HTTPQueueSize, r.Log.WithName("http-workers"))
+		if len(r.StatusID) > 0 {
+			r.httpWorkers.statusID = r.StatusID
+		}
+		if len(r.StatusType) > 0 {
+			r.httpWorkers.statusType = r.StatusType
+		}
+		if err := mgr.Add(r.httpWorkers); err != nil {
+			return err
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.TestRun{}).
 		Owns(&batchv1.Job{}).
@@ -404,6 +703,7 @@ func (r *TestRunReconciler) UpdateStatus(ctx context.Context, k6 *v1alpha1.TestR
 	}
 
 	cleanObj := k6.DeepCopyObject().(client.Object)
+	previousStage := k6.GetStatus().Stage
 
 	// Update only if it's truly a newer version of the resource
 	// in comparison to the recently fetched resource.
@@ -426,6 +726,10 @@ func (r *TestRunReconciler) UpdateStatus(ctx context.Context, k6 *v1alpha1.TestR
 		return false, err
 	}
 
+	if k6.GetStatus().Stage != previousStage {
+		observeStage(k6)
+	}
+
 	return true, nil
 }
 
@@ -438,7 +742,7 @@ func (r *TestRunReconciler) ShouldAbort(ctx context.Context, k6 *v1alpha1.TestRu
 		return false
 	}
 
-	status, err := cloud.GetTestRunState(r.k6CloudClient, k6.TestRunID(), log)
+	status, err := cloud.GetTestRunState(r.cloudClient(k6), k6.TestRunID(), log)
 	if err != nil {
 		return false
 	}
@@ -446,23 +750,40 @@ func (r *TestRunReconciler) ShouldAbort(ctx context.Context, k6 *v1alpha1.TestRu
 	return status.Aborted()
 }
 
+// createClient loads the token of the test run and creates its cloud client,
+// unless the client with the same host and token already exists.
 func (r *TestRunReconciler) createClient(ctx context.Context, k6 *v1alpha1.TestRun, log logr.Logger) (bool, error) {
-	if r.k6CloudClient == nil {
-		tokenInfo := cloud.NewTokenInfo(k6.GetSpec().Token, k6.NamespacedName().Namespace)
-		err := tokenInfo.Load(ctx, log, r.Client)
+	host := cloudHost(k6)
 
-		if err != nil {
-			log.Error(err, "A problem while getting token.")
-			return false, err
-		}
-		if !tokenInfo.Ready {
-			return false, nil
-		}
+	tokenInfo := newTokenInfo(k6)
+	err := tokenInfo.Load(ctx, log, r.Client)
 
-		host := getEnvVar(k6.GetSpec().Runner.Env, "K6_CLOUD_HOST")
+	if err != nil {
+		return false, err
+	}
+	if !tokenInfo.Ready {
+		return false, nil
+	}
 
-		r.k6CloudClient = cloud.NewClient(log, tokenInfo.Value(), host)
+	if c, ok := r.cloudClients.Load(k6.NamespacedName()); ok {
+		if c := c.(*testRunCloudClient); c.host == host && c.token == tokenInfo.Value() {
+			return true, nil
+		}
 	}
+	r.cloudClients.Store(k6.NamespacedName(), &testRunCloudClient{
+		host:   host,
+		token:  tokenInfo.Value(),
+		client: cloud.NewClient(log, tokenInfo.Value(), host),
+	})
 
 	return true, nil
 }
+
+// cloudClient returns the cloud client of the test run created by createClient,
+// or nil if there is none.
+func (r *TestRunReconciler) cloudClient(k6 *v1alpha1.TestRun) *cloudapi.Client {
+	if c, ok := r.cloudClients.Load(k6.NamespacedName()); ok {
+		return c.(*testRunCloudClient).client
+	}
+	return nil
+}
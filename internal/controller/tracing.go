@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/k6-operator/api/v1alpha1"
+)
+
+// traceKey is the context key of the trace context of requests to runners.
+type traceKey struct{}
+
+// traceContext is the W3C trace context of a test run.
+type traceContext struct {
+	header  string
+	traceID string
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// initTraceID chooses the trace ID of the test run, with Tracing: either the
+// one from the spec or a random one. It returns true if the status has changed.
+func initTraceID(k6 *v1alpha1.TestRun) bool {
+	tracing := k6.GetSpec().Tracing
+	if tracing == nil || len(k6.GetStatus().TraceID) > 0 {
+		return false
+	}
+
+	k6.GetStatus().TraceID = tracing.TraceID
+	if len(k6.GetStatus().TraceID) == 0 {
+		k6.GetStatus().TraceID = randomHex(16)
+	}
+	return true
+}
+
+// withTrace returns a context carrying the trace context of the test run,
+// for the requests to its runners. Without Tracing, ctx is returned as is.
+func withTrace(ctx context.Context, k6 *v1alpha1.TestRun) context.Context {
+	tracing := k6.GetSpec().Tracing
+	if tracing == nil || len(k6.GetStatus().TraceID) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, traceKey{}, traceContext{
+		header:  tracing.HeaderName(),
+		traceID: k6.GetStatus().TraceID,
+	})
+}
+
+// setTraceHeader adds the trace context of the request's context to its headers.
+// Every request gets its own span ID.
+func setTraceHeader(req *http.Request) {
+	tc, ok := req.Context().Value(traceKey{}).(traceContext)
+	if !ok {
+		return
+	}
+	req.Header.Set(tc.header, fmt.Sprintf("00-%s-%s-01", tc.traceID, randomHex(8)))
+}
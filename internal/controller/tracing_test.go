@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/grafana/k6-operator/api/v1alpha1"
+)
+
+func Test_setTraceHeader(t *testing.T) {
+	t.Parallel()
+
+	k6 := &v1alpha1.TestRun{
+		Spec: v1alpha1.TestRunSpec{
+			Tracing: &v1alpha1.K6Tracing{},
+		},
+	}
+
+	if !initTraceID(k6) || !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(k6.Status.TraceID) {
+		t.Fatalf("expected a random trace ID, got %q", k6.Status.TraceID)
+	}
+	traceID := k6.Status.TraceID
+	if initTraceID(k6) || k6.Status.TraceID != traceID {
+		t.Fatalf("expected the trace ID to be kept, got %q", k6.Status.TraceID)
+	}
+
+	req, err := http.NewRequestWithContext(withTrace(context.Background(), k6), http.MethodGet, "http://runner:6565/v1/status", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	setTraceHeader(req)
+
+	expected := regexp.MustCompile(`^00-` + traceID + `-[0-9a-f]{16}-01$`)
+	if header := req.Header.Get("traceparent"); !expected.MatchString(header) {
+		t.Errorf("unexpected traceparent header: %q", header)
+	}
+
+	// there is no header without Tracing
+	req, _ = http.NewRequestWithContext(withTrace(context.Background(), &v1alpha1.TestRun{}), http.MethodGet, "http://runner:6565/v1/status", nil)
+	setTraceHeader(req)
+	if len(req.Header) > 0 {
+		t.Errorf("expected no headers, got %v", req.Header)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	k6v1alpha1 "github.com/grafana/k6-operator/api/v1alpha1"
+)
+
+// SetupTestRunWebhookWithManager registers the webhook for TestRun in the manager.
+func SetupTestRunWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&k6v1alpha1.TestRun{}).
+		WithDefaulter(&TestRunCustomDefaulter{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-k6-io-v1alpha1-testrun,mutating=true,failurePolicy=fail,sideEffects=None,groups=k6.io,resources=testruns,verbs=create;update,versions=v1alpha1,name=mtestrun-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TestRunCustomDefaulter sets default values of the commonly omitted fields of TestRun,
+// so that the effective configuration is visible on the stored object.
+// The operator falls back to the same defaults when the webhook is not deployed.
+type TestRunCustomDefaulter struct{}
+
+// Default implements admission.CustomDefaulter.
+func (d *TestRunCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	k6, ok := obj.(*k6v1alpha1.TestRun)
+	if !ok {
+		return fmt.Errorf("expected a TestRun object but got %T", obj)
+	}
+
+	spec := k6.GetSpec()
+
+	if spec.Parallelism == 0 {
+		spec.Parallelism = 1
+	}
+
+	if len(spec.Runner.Image) == 0 {
+		spec.Runner.Image = k6v1alpha1.DefaultRunnerImage
+	}
+
+	// boolean flags are strings in the spec: "1", "True", etc. are normalized
+	flags := []*string{
+		&spec.Quiet,
+		&spec.Paused,
+		&spec.Scuttle.Enabled,
+		&spec.Runner.AutomountServiceAccountToken,
+		&spec.Starter.AutomountServiceAccountToken,
+	}
+	if spec.Initializer != nil {
+		flags = append(flags, &spec.Initializer.AutomountServiceAccountToken)
+	}
+	for _, flag := range flags {
+		normalizeBool(flag)
+	}
+
+	return nil
+}
+
+// normalizeBool rewrites a parsable boolean string as "true" or "false".
+// Other values are left for the operator to report.
+func normalizeBool(s *string) {
+	if b, err := strconv.ParseBool(*s); err == nil {
+		*s = strconv.FormatBool(b)
+	}
+}
@@ -0,0 +1,89 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	deep "github.com/go-test/deep"
+	k6v1alpha1 "github.com/grafana/k6-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTestRunCustomDefaulter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		spec     k6v1alpha1.TestRunSpec
+		expected k6v1alpha1.TestRunSpec
+	}{
+		{
+			name: "omitted fields",
+			spec: k6v1alpha1.TestRunSpec{},
+			expected: k6v1alpha1.TestRunSpec{
+				Parallelism: 1,
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: k6v1alpha1.DefaultRunnerImage}},
+			},
+		},
+		{
+			name: "set fields are kept",
+			spec: k6v1alpha1.TestRunSpec{
+				Parallelism: 4,
+				Paused:      "false",
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: "grafana/k6:1.5.0"}},
+			},
+			expected: k6v1alpha1.TestRunSpec{
+				Parallelism: 4,
+				Paused:      "false",
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: "grafana/k6:1.5.0"}},
+			},
+		},
+		{
+			name: "boolean flags are normalized",
+			spec: k6v1alpha1.TestRunSpec{
+				Parallelism: 1,
+				Quiet:       "0",
+				Paused:      "True",
+				Scuttle:     k6v1alpha1.K6Scuttle{Enabled: "1"},
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: "grafana/k6", AutomountServiceAccountToken: "F"}},
+				Initializer: &k6v1alpha1.Pod{AutomountServiceAccountToken: "TRUE"},
+			},
+			expected: k6v1alpha1.TestRunSpec{
+				Parallelism: 1,
+				Quiet:       "false",
+				Paused:      "true",
+				Scuttle:     k6v1alpha1.K6Scuttle{Enabled: "true"},
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: "grafana/k6", AutomountServiceAccountToken: "false"}},
+				Initializer: &k6v1alpha1.Pod{AutomountServiceAccountToken: "true"},
+			},
+		},
+		{
+			name: "invalid boolean flags are kept",
+			spec: k6v1alpha1.TestRunSpec{
+				Parallelism: 1,
+				Paused:      "yes",
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: "grafana/k6"}},
+			},
+			expected: k6v1alpha1.TestRunSpec{
+				Parallelism: 1,
+				Paused:      "yes",
+				Runner:      k6v1alpha1.RunnerPod{Pod: k6v1alpha1.Pod{Image: "grafana/k6"}},
+			},
+		},
+	}
+
+	d := &TestRunCustomDefaulter{}
+	for _, tc := range testCases {
+		k6 := &k6v1alpha1.TestRun{Spec: tc.spec}
+		if err := d.Default(context.Background(), k6); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if diff := deep.Equal(k6.Spec, tc.expected); diff != nil {
+			t.Errorf("%s: unexpected spec, diff: %s", tc.name, diff)
+		}
+	}
+
+	if err := d.Default(context.Background(), &corev1.Pod{}); err == nil {
+		t.Error("expected an error for an object of another kind")
+	}
+}
@@ -11,9 +11,6 @@ import (
 	null "gopkg.in/guregu/null.v3"
 )
 
-// TODO: refactor this!
-var client *cloudapi.Client
-
 type TestRun struct {
 	Name              string              `json:"name"`
 	ProjectID         int64               `json:"project_id,omitempty"`
@@ -64,9 +61,8 @@ func CreateTestRun(opts InspectOutput, instances int32, host, token string, log
 		host = cloudConfig.Host.String
 	}
 
-	if client == nil {
-		client = NewClient(log, token, host)
-	}
+	// test runs might have different hosts and tokens: the client isn't shared
+	client := NewClient(log, token, host)
 
 	tr := TestRun{
 		Name:              opts.TestName(),
@@ -102,14 +98,10 @@ func createTestRun(client *cloudapi.Client, host string, testRun *TestRun) (*clo
 	return &ctrr, nil
 }
 
-func FinishTestRun(c *cloudapi.Client, refID string) error {
-	if c != nil {
-		return c.TestFinished(refID, cloudapi.ThresholdResult(
-			map[string]map[string]bool{},
-		), false, cloudapi.RunStatusFinished)
-	}
-
-	return client.TestFinished(refID, cloudapi.ThresholdResult(
+// FinishTestRun marks the cloud test run as finished, with a failed result
+// unless passed is true.
+func FinishTestRun(c *cloudapi.Client, refID string, passed bool) error {
+	return c.TestFinished(refID, cloudapi.ThresholdResult(
 		map[string]map[string]bool{},
-	), false, cloudapi.RunStatusFinished)
+	), !passed, cloudapi.RunStatusFinished)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -18,6 +19,11 @@ type TokenInfo struct {
 
 	secretName      string
 	secretNamespace string
+	secretKey       string
+
+	// explicit shows that the Secret was referenced by the user,
+	// so there is no point to wait for it to appear.
+	explicit bool
 
 	// Ready shows whether token was loaded yet or there should be a retry.
 	// If it's false, either there was no attempt to load the token or there was
@@ -39,13 +45,26 @@ func NewTokenInfo(name, namespace string) *TokenInfo {
 	return &TokenInfo{
 		secretName:      name,
 		secretNamespace: namespace,
+		secretKey:       tokenSecretKey,
 	}
 }
 
+// WithSecretKey makes the token to be loaded from the key of the named Secret.
+func (ti *TokenInfo) WithSecretKey(name, key string) *TokenInfo {
+	ti.secretName = name
+	ti.secretKey = key
+	ti.explicit = true
+	return ti
+}
+
 func (ti TokenInfo) SecretName() string {
 	return ti.secretName
 }
 
+func (ti TokenInfo) SecretKey() string {
+	return ti.secretKey
+}
+
 func (ti TokenInfo) Value() string {
 	return ti.value
 }
@@ -70,6 +89,12 @@ func (ti *TokenInfo) Load(ctx context.Context, log logr.Logger, c k8sClient.Clie
 		log.Info("Loading token by name.", "name", ti.secretName, "secretNamespace", ti.secretNamespace)
 
 		if err := c.Get(ctx, types.NamespacedName{Namespace: ti.secretNamespace, Name: ti.secretName}, &secret); err != nil {
+			if ti.explicit && k8sErrors.IsNotFound(err) {
+				// we should stop execution in case of this error
+				returnErr = fmt.Errorf("secret %s/%s with k6 Cloud token is not found", ti.secretNamespace, ti.secretName)
+				log.Error(returnErr, returnErr.Error())
+				return
+			}
 			log.Error(err, "Failed to load k6 Cloud token", "name", ti.secretName, "secretNamespace", ti.secretNamespace)
 			// This may be a networking issue, etc. so just retry.
 			return
@@ -100,9 +125,9 @@ func (ti *TokenInfo) Load(ctx context.Context, log logr.Logger, c k8sClient.Clie
 		secret = secrets.Items[0]
 	}
 
-	if t, ok := secret.Data[tokenSecretKey]; !ok {
+	if t, ok := secret.Data[ti.secretKey]; !ok {
 		// we should stop execution in case of this error
-		returnErr = fmt.Errorf("the secret doesn't have a field `%s` for k6 Cloud token", tokenSecretKey)
+		returnErr = fmt.Errorf("the secret %s/%s doesn't have a field `%s` for k6 Cloud token", secret.Namespace, secret.Name, ti.secretKey)
 		log.Error(returnErr, returnErr.Error())
 		return
 	} else {
@@ -0,0 +1,52 @@
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTokenInfoLoad(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "test"},
+		Data: map[string][]byte{
+			"token":          []byte("default-token"),
+			"k6-cloud-token": []byte("key-token"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	testCases := []struct {
+		name      string
+		tokenInfo *TokenInfo
+		value     string
+		isError   bool
+	}{
+		{"secret name", NewTokenInfo("credentials", "test"), "default-token", false},
+		{"secret key", NewTokenInfo("", "test").WithSecretKey("credentials", "k6-cloud-token"), "key-token", false},
+		{"missing key", NewTokenInfo("", "test").WithSecretKey("credentials", "missing"), "", true},
+		{"missing secret", NewTokenInfo("", "test").WithSecretKey("missing", "token"), "", true},
+	}
+
+	for _, tc := range testCases {
+		err := tc.tokenInfo.Load(context.Background(), logr.Discard(), c)
+		if tc.isError {
+			assert.Error(t, err, tc.name)
+			assert.False(t, tc.tokenInfo.Ready, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+		assert.True(t, tc.tokenInfo.Ready, tc.name)
+		assert.Equal(t, tc.value, tc.tokenInfo.Value(), tc.name)
+	}
+}
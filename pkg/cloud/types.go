@@ -22,9 +22,11 @@ type InspectOutput struct {
 		Name      string `json:"name"`
 		ProjectID int64  `json:"projectID"`
 	} `json:"cloud"`
-	TotalDuration types.NullDuration             `json:"totalDuration"`
-	MaxVUs        uint64                         `json:"maxVUs"`
-	Thresholds    map[string]*metrics.Thresholds `json:"thresholds,omitempty"`
+	TotalDuration   types.NullDuration             `json:"totalDuration"`
+	MaxVUs          uint64                         `json:"maxVUs"`
+	Thresholds      map[string]*metrics.Thresholds `json:"thresholds,omitempty"`
+	SetupTimeout    types.NullDuration             `json:"setupTimeout"`
+	TeardownTimeout types.NullDuration             `json:"teardownTimeout"`
 }
 
 // ProjectID returns the project ID from the inspect output.
@@ -201,6 +203,25 @@ func (e *Events) WithAbort() *Events {
 	return e
 }
 
+// IsTerminal returns true for the events which end the test run in k6 Cloud:
+// they must be sent at most once per test run.
+func (e *EventPayload) IsTerminal() bool {
+	return e.EventType == abortEvent || e.EventType == errorEvent
+}
+
+// Key identifies the event among the terminal events of the test run:
+// the type, with the reason of abort events or the detail of other events.
+func (e *EventPayload) Key() string {
+	detail := e.Detail
+	if e.EventType == abortEvent {
+		detail = e.Reason
+	}
+	if len(detail) == 0 {
+		return string(e.EventType)
+	}
+	return fmt.Sprintf("%s: %s", e.EventType, detail)
+}
+
 func AbortEvent(o Origin) *EventPayload {
 	e := &EventPayload{
 		EventType: abortEvent,
@@ -109,18 +109,20 @@ func (w *PLZWorker) createTemplate(plz *v1alpha1.PrivateLoadZone) {
 			Namespace: plz.Namespace,
 		},
 		Spec: v1alpha1.TestRunSpec{
-			Runner: v1alpha1.Pod{
-				ImagePullSecrets:   plz.Spec.ImagePullSecrets,
-				ServiceAccountName: plz.Spec.ServiceAccountName,
-				NodeSelector:       plz.Spec.NodeSelector,
-				Resources:          plz.Spec.Resources,
-				Volumes: []corev1.Volume{
-					volume,
-				},
-				VolumeMounts: []corev1.VolumeMount{
-					volumeMount,
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					ImagePullSecrets:   plz.Spec.ImagePullSecrets,
+					ServiceAccountName: plz.Spec.ServiceAccountName,
+					NodeSelector:       plz.Spec.NodeSelector,
+					Resources:          plz.Spec.Resources,
+					Volumes: []corev1.Volume{
+						volume,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						volumeMount,
+					},
+					EnvFrom: plz.Spec.Config.ToEnvFromSource(),
 				},
-				EnvFrom: plz.Spec.Config.ToEnvFromSource(),
 			},
 			Starter: v1alpha1.Pod{
 				ServiceAccountName: plz.Spec.ServiceAccountName,
@@ -82,27 +82,29 @@ func Test_complete_correctDefinitionOfTestRun(t *testing.T) {
 				Name: testrun.PLZTestName("0"),
 			},
 			Spec: v1alpha1.TestRunSpec{
-				Runner: v1alpha1.Pod{
-					Volumes: []corev1.Volume{{
-						Name: "archive-volume",
-						VolumeSource: corev1.VolumeSource{
-							EmptyDir: &corev1.EmptyDirVolumeSource{},
+				Runner: v1alpha1.RunnerPod{
+					Pod: v1alpha1.Pod{
+						Volumes: []corev1.Volume{{
+							Name: "archive-volume",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
 						},
+						},
+						VolumeMounts: []corev1.VolumeMount{volumeMount},
+						InitContainers: []v1alpha1.InitContainer{
+							containers.NewS3InitContainer(
+								"",
+								"ghcr.io/grafana/k6-operator:latest-starter",
+								volumeMount,
+							),
+						},
+						Env: append([]corev1.EnvVar{{
+							Name:  "K6_CLOUD_HOST",
+							Value: mainIngest,
+						}}, cloud.AggregationEnvVars(&cloudapi.Config{})...),
+						EnvFrom: []corev1.EnvFromSource{},
 					},
-					},
-					VolumeMounts: []corev1.VolumeMount{volumeMount},
-					InitContainers: []v1alpha1.InitContainer{
-						containers.NewS3InitContainer(
-							"",
-							"ghcr.io/grafana/k6-operator:latest-starter",
-							volumeMount,
-						),
-					},
-					Env: append([]corev1.EnvVar{{
-						Name:  "K6_CLOUD_HOST",
-						Value: mainIngest,
-					}}, cloud.AggregationEnvVars(&cloudapi.Config{})...),
-					EnvFrom: []corev1.EnvFromSource{},
 				},
 				Script: v1alpha1.K6Script{
 					LocalFile: "/test/archive.tar",
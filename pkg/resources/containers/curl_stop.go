@@ -13,15 +13,9 @@ import (
 // NewStopContainer is used to get a template for a new k6 stop curl container.
 func NewStopContainer(hostnames []string, image string, imagePullPolicy corev1.PullPolicy, command []string, env []corev1.EnvVar, securityContext corev1.SecurityContext, resources corev1.ResourceRequirements) corev1.Container {
 	req, _ := json.Marshal(
-		types.StatusAPIRequest{
-			Data: types.StatusAPIRequestData{
-				Attributes: types.StatusAPIRequestDataAttributes{
-					Stopped: true,
-				},
-				ID:   "default",
-				Type: "status",
-			},
-		})
+		types.NewStatusAPIRequest("", "", types.StatusAPIRequestDataAttributes{
+			Stopped: true,
+		}))
 
 	var parts []string
 	for _, hostname := range hostnames {
@@ -2,7 +2,12 @@ package jobs
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/grafana/k6-operator/pkg/types"
 
@@ -17,6 +22,30 @@ func newLabels(name string) map[string]string {
 	}
 }
 
+// inheritMetadata returns copies of labels and annotations with the metadata
+// inherited from the TestRun added. Existing keys take precedence.
+func inheritMetadata(k6 *v1alpha1.TestRun, labels, annotations map[string]string) (map[string]string, map[string]string) {
+	labels, annotations = maps.Clone(labels), maps.Clone(annotations)
+	inheritedLabels, inheritedAnnotations := k6.InheritedMetadata()
+	if len(inheritedLabels) > 0 && labels == nil {
+		labels = make(map[string]string)
+	}
+	for k, v := range inheritedLabels {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	if len(inheritedAnnotations) > 0 && annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for k, v := range inheritedAnnotations {
+		if _, ok := annotations[k]; !ok {
+			annotations[k] = v
+		}
+	}
+	return labels, annotations
+}
+
 func newIstioCommand(istioEnabled string, inheritedCommands []string) ([]string, bool) {
 	istio := false
 	if istioEnabled != "" {
@@ -116,10 +145,250 @@ func newIstioEnvVar(istio v1alpha1.K6Scuttle, istioEnabled bool) []corev1.EnvVar
 	return env
 }
 
+// newOutputVolume returns the PersistentVolumeClaim volume for output files
+// of the runner with the given index, together with its mount.
+func newOutputVolume(output *v1alpha1.K6OutputVolume, index int) (corev1.Volume, corev1.VolumeMount) {
+	mountPath := "/output"
+	if output.MountPath != "" {
+		mountPath = output.MountPath
+	}
+
+	volume := corev1.Volume{
+		Name: "k6-output-volume",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: output.ClaimNameFor(index),
+			},
+		},
+	}
+
+	volumeMount := corev1.VolumeMount{
+		Name:      "k6-output-volume",
+		MountPath: mountPath,
+	}
+
+	return volume, volumeMount
+}
+
+// newOutputs returns the `--out` arguments and the environment variables
+// needed by the configured outputs.
+func newOutputs(outputs *v1alpha1.K6Outputs) ([]string, []corev1.EnvVar) {
+	var (
+		args []string
+		env  []corev1.EnvVar
+	)
+
+	if outputs == nil {
+		return args, env
+	}
+
+	if rw := outputs.PrometheusRemoteWrite; rw != nil {
+		args = append(args, "--out", "experimental-prometheus-rw")
+		env = append(env, corev1.EnvVar{
+			Name:  "K6_PROMETHEUS_RW_SERVER_URL",
+			Value: rw.ServerURL,
+		})
+		if len(rw.TrendStats) > 0 {
+			env = append(env, corev1.EnvVar{
+				Name:  "K6_PROMETHEUS_RW_TREND_STATS",
+				Value: rw.TrendStats,
+			})
+		}
+		if len(rw.CredentialsSecret) > 0 {
+			env = append(env,
+				newSecretEnvVar("K6_PROMETHEUS_RW_USERNAME", rw.CredentialsSecret, "username"),
+				newSecretEnvVar("K6_PROMETHEUS_RW_PASSWORD", rw.CredentialsSecret, "password"),
+			)
+		}
+	}
+
+	if influx := outputs.InfluxDB; influx != nil {
+		args = append(args, "--out", fmt.Sprintf("influxdb=%s", influx.URL))
+		if len(influx.CredentialsSecret) > 0 {
+			env = append(env,
+				newSecretEnvVar("K6_INFLUXDB_USERNAME", influx.CredentialsSecret, "username"),
+				newSecretEnvVar("K6_INFLUXDB_PASSWORD", influx.CredentialsSecret, "password"),
+			)
+		}
+	}
+
+	return args, env
+}
+
+func newSecretEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// newLogArgs returns the arguments of k6 for the configured log level and format,
+// unless they are already present in the arguments set by the user.
+func newLogArgs(logLevel, logFormat, arguments string) []string {
+	var (
+		args      []string
+		verbose   bool
+		hasFormat bool
+	)
+
+	for _, arg := range strings.Fields(arguments) {
+		switch {
+		case arg == "-v" || arg == "--verbose":
+			verbose = true
+		case arg == "--log-format" || strings.HasPrefix(arg, "--log-format="):
+			hasFormat = true
+		}
+	}
+
+	if logLevel == "debug" && !verbose {
+		args = append(args, "--verbose")
+	}
+	if len(logFormat) > 0 && !hasFormat {
+		args = append(args, "--log-format", logFormat)
+	}
+
+	return args
+}
+
+// newRunnerPodSecurityContext returns the security context of a runner pod.
+// If it's not set, it defaults to the RuntimeDefault seccomp profile. The user
+// isn't restricted on the pod level, since sidecars and init containers, e.g.
+// injected by a service mesh or the debug one, might have to run as root.
+func newRunnerPodSecurityContext(sc corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	if !reflect.DeepEqual(sc, corev1.PodSecurityContext{}) {
+		return &sc
+	}
+
+	return &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// newRunnerContainerSecurityContext returns the security context of the k6 container
+// of a runner. If it's not set, it defaults to the restricted Pod Security Standard
+// with a read-only root filesystem, except in browser mode: Chromium writes its
+// profile and temporary files.
+func newRunnerContainerSecurityContext(sc corev1.SecurityContext, browserMode bool) *corev1.SecurityContext {
+	if !reflect.DeepEqual(sc, corev1.SecurityContext{}) {
+		return &sc
+	}
+
+	allowPrivilegeEscalation, readOnlyRootFilesystem, runAsNonRoot := false, !browserMode, true
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+		RunAsNonRoot:             &runAsNonRoot,
+	}
+}
+
+// proxyEnvVarNames are the variables configuring an HTTP proxy in Go programs, such as k6.
+var proxyEnvVarNames = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+// newProxyEnvVars returns the proxy configuration from the env of the operator.
+func newProxyEnvVars() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for _, name := range proxyEnvVarNames {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	return env
+}
+
+// newPodInfoEnvVars returns the env vars of PodInfoEnv, with the fields
+// of the runner pod from the downward API.
+func newPodInfoEnvVars() []corev1.EnvVar {
+	fields := []struct{ name, path string }{
+		{"K6_POD_NAME", "metadata.name"},
+		{"K6_POD_NAMESPACE", "metadata.namespace"},
+		{"K6_POD_IP", "status.podIP"},
+		{"K6_NODE_NAME", "spec.nodeName"},
+	}
+
+	env := make([]corev1.EnvVar, 0, len(fields))
+	for _, field := range fields {
+		env = append(env, corev1.EnvVar{
+			Name: field.name,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: field.path},
+			},
+		})
+	}
+	return env
+}
+
+// newBrowserVolume returns the in-memory /dev/shm of a runner in browser mode:
+// the default 64Mi of the container runtime is too small for Chromium.
+func newBrowserVolume(runner *v1alpha1.RunnerPod) (corev1.Volume, corev1.VolumeMount) {
+	size := v1alpha1.DefaultBrowserShmSize
+	if runner.BrowserShmSize != nil {
+		size = *runner.BrowserShmSize
+	}
+
+	volume := corev1.Volume{
+		Name: "k6-browser-shm",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium:    corev1.StorageMediumMemory,
+				SizeLimit: &size,
+			},
+		},
+	}
+	return volume, corev1.VolumeMount{Name: volume.Name, MountPath: "/dev/shm"}
+}
+
+// newBrowserEnvVars returns the env vars starting Chromium headless and
+// without its sandbox, unless they're set in the env of the runner.
+func newBrowserEnvVars(runnerEnv []corev1.EnvVar) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for _, v := range []corev1.EnvVar{
+		{Name: "K6_BROWSER_HEADLESS", Value: "true"},
+		{Name: "K6_BROWSER_ARGS", Value: "no-sandbox"},
+	} {
+		if !slices.ContainsFunc(runnerEnv, func(e corev1.EnvVar) bool { return e.Name == v.Name }) {
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+// newImagePullSecrets merges the image pull secrets common to all Pods
+// of the test run with the ones of the specific Pod, without duplicates.
+func newImagePullSecrets(common []corev1.LocalObjectReference, pod []corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	if len(common) == 0 {
+		return pod
+	}
+
+	var (
+		secrets []corev1.LocalObjectReference
+		seen    = map[string]bool{}
+	)
+	for _, secret := range append(append([]corev1.LocalObjectReference{}, common...), pod...) {
+		if !seen[secret.Name] {
+			seen[secret.Name] = true
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
 // TODO: Envoy variables are not passed to init containers
 func getInitContainers(pod *v1alpha1.Pod, script *types.Script) []corev1.Container {
 	var initContainers []corev1.Container
 
+	// the script must be in place before any other init container
+	for _, fetchContainer := range script.FetchContainers() {
+		fetchContainer.ImagePullPolicy = pod.ImagePullPolicy
+		fetchContainer.SecurityContext = &pod.ContainerSecurityContext
+		initContainers = append(initContainers, fetchContainer)
+	}
+
 	for i, k6InitContainer := range pod.InitContainers {
 
 		name := fmt.Sprintf("k6-init-%d", i)
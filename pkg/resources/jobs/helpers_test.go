@@ -1,12 +1,14 @@
 package jobs
 
 import (
+	"os"
 	"reflect"
 	"testing"
 
 	"github.com/go-test/deep"
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestNewLabels(t *testing.T) {
@@ -153,3 +155,178 @@ func TestNewIstioEnvVarFalseValues(t *testing.T) {
 		t.Errorf("new envVars were incorrect, got: %v, want: %v.", envVars, expectedOutcome)
 	}
 }
+
+func TestNewImagePullSecrets(t *testing.T) {
+	expectedOutcome := []corev1.LocalObjectReference{
+		{Name: "registry"},
+		{Name: "runner-registry"},
+	}
+
+	secrets := newImagePullSecrets(
+		[]corev1.LocalObjectReference{{Name: "registry"}},
+		[]corev1.LocalObjectReference{{Name: "runner-registry"}, {Name: "registry"}},
+	)
+
+	if diff := deep.Equal(expectedOutcome, secrets); diff != nil {
+		t.Errorf("newImagePullSecrets returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerSecurityContexts(t *testing.T) {
+	if diff := deep.Equal(restrictedPodSecurityContext(), newRunnerPodSecurityContext(corev1.PodSecurityContext{})); diff != nil {
+		t.Errorf("newRunnerPodSecurityContext returned unexpected defaults, diff: %s", diff)
+	}
+	if diff := deep.Equal(restrictedContainerSecurityContext(), newRunnerContainerSecurityContext(corev1.SecurityContext{}, false)); diff != nil {
+		t.Errorf("newRunnerContainerSecurityContext returned unexpected defaults, diff: %s", diff)
+	}
+
+	// once set, security contexts are not combined with the defaults
+	var runAsUser int64 = 1000
+	podSecurityContext := corev1.PodSecurityContext{RunAsUser: &runAsUser}
+	if diff := deep.Equal(&podSecurityContext, newRunnerPodSecurityContext(podSecurityContext)); diff != nil {
+		t.Errorf("newRunnerPodSecurityContext returned unexpected data, diff: %s", diff)
+	}
+
+	privileged := true
+	containerSecurityContext := corev1.SecurityContext{Privileged: &privileged}
+	if diff := deep.Equal(&containerSecurityContext, newRunnerContainerSecurityContext(containerSecurityContext, false)); diff != nil {
+		t.Errorf("newRunnerContainerSecurityContext returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewProxyEnvVars(t *testing.T) {
+	for _, name := range proxyEnvVarNames {
+		t.Setenv(name, "")
+		_ = os.Unsetenv(name)
+	}
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("NO_PROXY", ".svc,.cluster.local")
+
+	expectedOutcome := []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+		{Name: "NO_PROXY", Value: ".svc,.cluster.local"},
+	}
+
+	if diff := deep.Equal(expectedOutcome, newProxyEnvVars()); diff != nil {
+		t.Errorf("newProxyEnvVars returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewLogArgs(t *testing.T) {
+	testCases := []struct {
+		name      string
+		logLevel  string
+		logFormat string
+		arguments string
+		expected  []string
+	}{
+		{"defaults", "", "", "", nil},
+		{"debug and json", "debug", "json", "", []string{"--verbose", "--log-format", "json"}},
+		{"info level", "info", "", "", nil},
+		{"verbose in arguments", "debug", "json", "--tag foo=bar -v", []string{"--log-format", "json"}},
+		{"log format in arguments", "debug", "json", "--log-format=raw", []string{"--verbose"}},
+	}
+
+	for _, tc := range testCases {
+		args := newLogArgs(tc.logLevel, tc.logFormat, tc.arguments)
+		if diff := deep.Equal(tc.expected, args); diff != nil {
+			t.Errorf("%s: newLogArgs returned unexpected data, diff: %s", tc.name, diff)
+		}
+	}
+}
+
+func TestNewOutputs(t *testing.T) {
+	expectedArgs := []string{"--out", "experimental-prometheus-rw", "--out", "influxdb=http://influxdb:8086/k6"}
+	expectedEnv := []corev1.EnvVar{
+		{
+			Name:  "K6_PROMETHEUS_RW_SERVER_URL",
+			Value: "http://prometheus:9090/api/v1/write",
+		},
+		{
+			Name:  "K6_PROMETHEUS_RW_TREND_STATS",
+			Value: "p(95),p(99)",
+		},
+		{
+			Name: "K6_PROMETHEUS_RW_USERNAME",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "prometheus-credentials"},
+					Key:                  "username",
+				},
+			},
+		},
+		{
+			Name: "K6_PROMETHEUS_RW_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "prometheus-credentials"},
+					Key:                  "password",
+				},
+			},
+		},
+	}
+
+	args, env := newOutputs(&v1alpha1.K6Outputs{
+		PrometheusRemoteWrite: &v1alpha1.K6PrometheusRemoteWrite{
+			ServerURL:         "http://prometheus:9090/api/v1/write",
+			TrendStats:        "p(95),p(99)",
+			CredentialsSecret: "prometheus-credentials",
+		},
+		InfluxDB: &v1alpha1.K6InfluxDB{
+			URL: "http://influxdb:8086/k6",
+		},
+	})
+
+	if diff := deep.Equal(expectedArgs, args); diff != nil {
+		t.Errorf("newOutputs returned unexpected args, diff: %s", diff)
+	}
+	if diff := deep.Equal(expectedEnv, env); diff != nil {
+		t.Errorf("newOutputs returned unexpected env, diff: %s", diff)
+	}
+}
+
+func TestInheritMetadata(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			Labels: map[string]string{
+				"team":                  "perf",
+				"cost.example.com/unit": "42",
+				"cost.example.com/env":  "staging",
+				"noisy":                 "true",
+				"app":                   "checkout",
+			},
+			Annotations: map[string]string{
+				"owner":                            "perf@example.com",
+				corev1.LastAppliedConfigAnnotation: "{}",
+			},
+		},
+		Spec: v1alpha1.TestRunSpec{
+			InheritLabels:      []string{"team", "cost.example.com/*", "app", "missing"},
+			InheritAnnotations: []string{"*"},
+		},
+	}
+
+	runnerAnnotations := map[string]string{"owner": "runner@example.com"}
+	labels, annotations := inheritMetadata(k6, newLabels("test"), runnerAnnotations)
+
+	expectedLabels := map[string]string{
+		"app":                   "k6",
+		"k6_cr":                 "test",
+		"team":                  "perf",
+		"cost.example.com/unit": "42",
+		"cost.example.com/env":  "staging",
+	}
+	if diff := deep.Equal(labels, expectedLabels); diff != nil {
+		t.Errorf("unexpected labels, diff: %s", diff)
+	}
+	if diff := deep.Equal(annotations, runnerAnnotations); diff != nil {
+		t.Errorf("unexpected annotations, diff: %s", diff)
+	}
+
+	// the annotations of the runner are copied, not modified
+	k6.Annotations["extra"] = "true"
+	if _, annotations = inheritMetadata(k6, nil, runnerAnnotations); annotations["extra"] != "true" || len(runnerAnnotations) != 1 {
+		t.Errorf("expected the annotations to be copied, got %v and %v", annotations, runnerAnnotations)
+	}
+}
@@ -10,6 +10,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// VersionContainerName is the name of the initializer container
+// which lists the extensions of the runner image.
+const VersionContainerName = "k6-version"
+
 // NewInitializerJob builds a template used to initializefor creating a starter job
 func NewInitializerJob(k6 *v1alpha1.TestRun, argLine string) (*batchv1.Job, error) {
 	script, err := k6.GetSpec().ParseScript()
@@ -18,7 +22,7 @@ func NewInitializerJob(k6 *v1alpha1.TestRun, argLine string) (*batchv1.Job, erro
 	}
 
 	var (
-		image                        = "grafana/k6:latest"
+		image                        = v1alpha1.DefaultRunnerImage
 		annotations                  = make(map[string]string)
 		labels                       = newLabels(k6.NamespacedName().Name)
 		serviceAccountName           = "default"
@@ -27,7 +31,7 @@ func NewInitializerJob(k6 *v1alpha1.TestRun, argLine string) (*batchv1.Job, erro
 	)
 
 	if k6.GetSpec().Initializer == nil {
-		k6.GetSpec().Initializer = k6.GetSpec().Runner.DeepCopy()
+		k6.GetSpec().Initializer = k6.GetSpec().Runner.Pod.DeepCopy()
 	}
 
 	if k6.GetSpec().Initializer.Image != "" {
@@ -45,6 +49,7 @@ func NewInitializerJob(k6 *v1alpha1.TestRun, argLine string) (*batchv1.Job, erro
 			}
 		}
 	}
+	labels, annotations = inheritMetadata(k6, labels, annotations)
 
 	if k6.GetSpec().Initializer.ServiceAccountName != "" {
 		serviceAccountName = k6.GetSpec().Initializer.ServiceAccountName
@@ -88,6 +93,38 @@ func NewInitializerJob(k6 *v1alpha1.TestRun, argLine string) (*batchv1.Job, erro
 	volumeMounts := script.VolumeMount()
 	volumeMounts = append(volumeMounts, k6.GetSpec().Initializer.VolumeMounts...)
 
+	containers := []corev1.Container{
+		{
+			Image:           image,
+			ImagePullPolicy: k6.GetSpec().Initializer.ImagePullPolicy,
+			Name:            "k6",
+			Command:         command,
+			Env:             env,
+			Resources:       k6.GetSpec().Initializer.Resources,
+			VolumeMounts:    volumeMounts,
+			EnvFrom:         k6.GetSpec().Initializer.EnvFrom,
+			Ports:           ports,
+			SecurityContext: &k6.GetSpec().Initializer.ContainerSecurityContext,
+		},
+	}
+
+	if len(k6.GetSpec().Extensions) > 0 {
+		// Extensions must be present in the image of runners:
+		// list the extensions it was built with.
+		runnerImage := v1alpha1.DefaultRunnerImage
+		if k6.GetSpec().Runner.Image != "" {
+			runnerImage = k6.GetSpec().Runner.Image
+		}
+
+		containers = append(containers, corev1.Container{
+			Image:           runnerImage,
+			ImagePullPolicy: k6.GetSpec().Runner.ImagePullPolicy,
+			Name:            VersionContainerName,
+			Command:         []string{"k6", "version", "--json"},
+			SecurityContext: &k6.GetSpec().Initializer.ContainerSecurityContext,
+		})
+	}
+
 	var zero32 int32
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -112,24 +149,11 @@ func NewInitializerJob(k6 *v1alpha1.TestRun, argLine string) (*batchv1.Job, erro
 					TopologySpreadConstraints:    k6.GetSpec().Initializer.TopologySpreadConstraints,
 					SecurityContext:              &k6.GetSpec().Initializer.SecurityContext,
 					RestartPolicy:                corev1.RestartPolicyNever,
-					ImagePullSecrets:             k6.GetSpec().Initializer.ImagePullSecrets,
+					ImagePullSecrets:             newImagePullSecrets(k6.GetSpec().ImagePullSecrets, k6.GetSpec().Initializer.ImagePullSecrets),
 					InitContainers:               getInitContainers(k6.GetSpec().Initializer, script),
-					Containers: []corev1.Container{
-						{
-							Image:           image,
-							ImagePullPolicy: k6.GetSpec().Initializer.ImagePullPolicy,
-							Name:            "k6",
-							Command:         command,
-							Env:             env,
-							Resources:       k6.GetSpec().Initializer.Resources,
-							VolumeMounts:    volumeMounts,
-							EnvFrom:         k6.GetSpec().Initializer.EnvFrom,
-							Ports:           ports,
-							SecurityContext: &k6.GetSpec().Initializer.ContainerSecurityContext,
-						},
-					},
-					Volumes:           volumes,
-					PriorityClassName: k6.GetSpec().Initializer.PriorityClassName,
+					Containers:                   containers,
+					Volumes:                      volumes,
+					PriorityClassName:            k6.GetSpec().Initializer.PriorityClassName,
 				},
 			},
 		},
@@ -2,23 +2,49 @@ package jobs
 
 import (
 	"fmt"
+	"maps"
 	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/cloud"
 	"github.com/grafana/k6-operator/pkg/segmentation"
+	"github.com/grafana/k6-operator/pkg/types"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // NewRunnerJob creates a new k6 job from a CRD
 // secretName is the name of the Secret with Cloud token, which must be in the same namespace.
 func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (*batchv1.Job, error) {
+	return newRunnerJob(k6, index, tokenInfo)
+}
+
+// IndexedRunnerJobName returns the name of the Indexed Job of the runners.
+func IndexedRunnerJobName(k6 *v1alpha1.TestRun) string {
+	return fmt.Sprintf("%s-runners", k6.NamespacedName().Name)
+}
+
+// NewIndexedRunnerJob creates the Indexed Job of all runners, with a completion
+// per runner, for the Indexed completion mode.
+func NewIndexedRunnerJob(k6 *v1alpha1.TestRun, tokenInfo *cloud.TokenInfo) (*batchv1.Job, error) {
+	return newRunnerJob(k6, 0, tokenInfo)
+}
+
+// newRunnerJob creates the job of the runner with the given index or,
+// if the index is 0, the Indexed Job of all runners.
+func newRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (*batchv1.Job, error) {
+	indexed := index == 0
+
 	name := fmt.Sprintf("%s-%d", k6.NamespacedName().Name, index)
+	if indexed {
+		name = IndexedRunnerJobName(k6)
+	}
 	postCommand := []string{"k6", "run"}
 
 	command, istioEnabled := newIstioCommand(k6.GetSpec().Scuttle.Enabled, postCommand)
@@ -32,59 +58,130 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 		command = append(command, "--quiet")
 	}
 
-	if k6.GetSpec().Parallelism > 1 {
-		var args []string
-		var err error
-
-		if args, err = segmentation.NewCommandFragments(index, int(k6.GetSpec().Parallelism)); err != nil {
-			return nil, err
-
+	var (
+		segmentEnv  []corev1.EnvVar
+		segmentArgs []string
+	)
+	// the Indexed Job gets the execution segment from newIndexedCommand;
+	// with PerRunnerScripts, each runner executes the whole of its script
+	if !indexed && len(k6.GetSpec().PerRunnerScripts) == 0 && k6.GetSpec().Parallelism > 1 {
+		if k6.GetSpec().ExecutionSegmentEnv {
+			segment, sequence, err := segmentation.NewSegment(index, int(k6.GetSpec().Parallelism))
+			if err != nil {
+				return nil, err
+			}
+			segmentEnv = []corev1.EnvVar{
+				{Name: "K6_EXECUTION_SEGMENT", Value: segment},
+				{Name: "K6_EXECUTION_SEGMENT_SEQUENCE", Value: sequence},
+			}
+		} else {
+			args, err := segmentation.NewCommandFragments(index, int(k6.GetSpec().Parallelism))
+			if err != nil {
+				return nil, err
+			}
+			segmentArgs = args
+			command = append(command, segmentArgs...)
 		}
-		command = append(command, args...)
 	}
 
-	script, err := k6.GetSpec().ParseScript()
+	script, err := k6.GetSpec().ParseRunnerScript(index)
 	if err != nil {
 		return nil, err
 	}
 
+	command = append(command, newLogArgs(k6.GetSpec().LogLevel, k6.GetSpec().LogFormat, k6.GetSpec().Arguments)...)
+
 	if k6.GetSpec().Arguments != "" {
 		args := strings.Split(k6.GetSpec().Arguments, " ")
 		command = append(command, args...)
 	}
 
+	outputArgs, outputEnv := newOutputs(k6.GetSpec().Outputs)
+	command = append(command, outputArgs...)
+
+	scriptPath := script.FullName()
+	if k6.GetSpec().ThresholdsOverride != nil {
+		scriptPath = thresholdsWrapperPath
+	}
+
 	command = append(
 		command,
-		script.FullName(),
+		scriptPath,
 		"--address=0.0.0.0:6565")
 
-	paused := true
-	if k6.GetSpec().Paused != "" {
-		paused, _ = strconv.ParseBool(k6.GetSpec().Paused)
-	}
-
-	if paused {
+	if k6.RunnersPaused() {
 		command = append(command, "--paused")
 	}
 
-	// Add an instance tag: in case metrics are stored, they need to be distinguished by instance
-	command = append(command, "--tag", fmt.Sprintf("instance_id=%d", index))
+	if !indexed {
+		// Add an instance tag: in case metrics are stored, they need to be distinguished by instance
+		command = append(command, "--tag", fmt.Sprintf("instance_id=%d", index))
 
-	// Add an job tag: in case metrics are stored, they need to be distinguished by job
-	command = append(command, "--tag", fmt.Sprintf("job_name=%s", name))
+		// Add an job tag: in case metrics are stored, they need to be distinguished by job
+		command = append(command, "--tag", fmt.Sprintf("job_name=%s", name))
+	}
 
 	if v1alpha1.IsTrue(k6, v1alpha1.CloudPLZTestRun) {
 		command = append(command, "--no-setup", "--no-teardown", "--linger")
+	} else if k6.GetSpec().Setup != nil {
+		// setup() is executed by the operator
+		command = append(command, "--no-setup")
 	}
 
-	command = script.UpdateCommand(command)
+	if indexed {
+		if command, err = newIndexedCommand(k6, script, command); err != nil {
+			return nil, err
+		}
+	} else {
+		command = script.UpdateCommand(command)
+	}
+
+	if keep := k6.GetSpec().KeepRunnersAfterFinish; keep != nil {
+		command = newKeepCommand(keep.Duration, command)
+	}
+
+	var args []string
+	if k6.GetSpec().Runner.HasCommandOverride() {
+		if err := k6.ValidateRunnerCommand(); err != nil {
+			return nil, err
+		}
+		// the wrapper is in charge of the k6 command: only the segment is passed along
+		command = k6.GetSpec().Runner.Command
+		args = append(append([]string{}, k6.GetSpec().Runner.Args...), segmentArgs...)
+	}
 
 	var (
 		zero   int64 = 0
 		zero32 int32 = 0
 	)
 
-	image := "grafana/k6:latest"
+	var hostname, subdomain string
+	if !indexed {
+		hostname = name
+	}
+	if k6.GetSpec().HeadlessService {
+		subdomain = HeadlessServiceName(k6)
+	}
+
+	terminationGracePeriodSeconds := &zero
+	if k6.GetSpec().Runner.TerminationGracePeriodSeconds != nil {
+		terminationGracePeriodSeconds = k6.GetSpec().Runner.TerminationGracePeriodSeconds
+	}
+
+	restartPolicy := corev1.RestartPolicyNever
+	if len(k6.GetSpec().Runner.RestartPolicy) > 0 {
+		restartPolicy = k6.GetSpec().Runner.RestartPolicy
+	}
+
+	backoffLimit := &zero32
+	if k6.GetSpec().Runner.BackoffLimit != nil {
+		backoffLimit = k6.GetSpec().Runner.BackoffLimit
+	}
+
+	image := v1alpha1.DefaultRunnerImage
+	if k6.GetSpec().Runner.BrowserMode {
+		image = v1alpha1.DefaultBrowserRunnerImage
+	}
 	if k6.GetSpec().Runner.Image != "" {
 		image = k6.GetSpec().Runner.Image
 	}
@@ -94,8 +191,7 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 		runnerAnnotations = k6.GetSpec().Runner.Metadata.Annotations
 	}
 
-	runnerLabels := newLabels(k6.NamespacedName().Name)
-	runnerLabels["runner"] = "true"
+	runnerLabels := k6.RunnerLabels()
 	if k6.GetSpec().Runner.Metadata.Labels != nil {
 		for k, v := range k6.GetSpec().Runner.Metadata.Labels { // Order not specified
 			if _, ok := runnerLabels[k]; !ok {
@@ -103,6 +199,13 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 			}
 		}
 	}
+	runnerLabels, runnerAnnotations = inheritMetadata(k6, runnerLabels, runnerAnnotations)
+
+	podAnnotations := runnerAnnotations
+	if k6.GetSpec().ThresholdsOverride != nil {
+		podAnnotations = maps.Clone(runnerAnnotations)
+		podAnnotations[v1alpha1.ThresholdsWrapperAnnotation] = newThresholdsWrapper(script)
+	}
 
 	serviceAccountName := "default"
 	if k6.GetSpec().Runner.ServiceAccountName != "" {
@@ -133,7 +236,7 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 				ValueFrom: &corev1.EnvVarSource{
 					SecretKeyRef: &corev1.SecretKeySelector{
 						LocalObjectReference: corev1.LocalObjectReference{Name: tokenInfo.SecretName()},
-						Key:                  "token",
+						Key:                  tokenInfo.SecretKey(),
 					},
 				},
 			}
@@ -150,8 +253,57 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 			Name:  "K6_CLOUD_PUSH_REF_ID",
 			Value: k6.TestRunID(),
 		}, tokenVar)
+
+		if len(k6.GetSpec().CloudHost) > 0 {
+			env = append(env, corev1.EnvVar{
+				Name:  "K6_CLOUD_HOST",
+				Value: k6.GetSpec().CloudHost,
+			})
+		}
+	} else if ref := k6.GetSpec().CloudOutputToken; ref != nil {
+		// cloud output without a cloud test run of the operator
+		env = append(env, corev1.EnvVar{
+			Name:      "K6_CLOUD_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: ref.DeepCopy()},
+		})
+
+		if len(k6.GetSpec().CloudHost) > 0 {
+			env = append(env, corev1.EnvVar{
+				Name:  "K6_CLOUD_HOST",
+				Value: k6.GetSpec().CloudHost,
+			})
+		}
 	}
 
+	env = append(env, outputEnv...)
+	env = append(env, segmentEnv...)
+	if !indexed {
+		env = append(env, corev1.EnvVar{
+			Name:  v1alpha1.InstanceIDEnv,
+			Value: strconv.Itoa(index),
+		})
+	}
+	if tracing := k6.GetSpec().Tracing; tracing != nil && len(k6.GetStatus().TraceID) > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  tracing.EnvName(),
+			Value: k6.GetStatus().TraceID,
+		})
+	}
+	if seed := k6.GetSpec().Seed; seed != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  v1alpha1.SeedEnv,
+			Value: strconv.FormatInt(*seed, 10),
+		})
+	}
+	if k6.GetSpec().InheritProxyEnv {
+		env = append(env, newProxyEnvVars()...)
+	}
+	if k6.GetSpec().PodInfoEnv {
+		env = append(env, newPodInfoEnvVars()...)
+	}
+	if k6.GetSpec().Runner.BrowserMode {
+		env = append(env, newBrowserEnvVars(k6.GetSpec().Runner.Env)...)
+	}
 	env = append(env, k6.GetSpec().Runner.Env...)
 
 	volumes := script.Volume()
@@ -160,6 +312,24 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 	volumeMounts := script.VolumeMount()
 	volumeMounts = append(volumeMounts, k6.GetSpec().Runner.VolumeMounts...)
 
+	if k6.GetSpec().OutputVolume != nil {
+		outputVolume, outputVolumeMount := newOutputVolume(k6.GetSpec().OutputVolume, index)
+		volumes = append(volumes, outputVolume)
+		volumeMounts = append(volumeMounts, outputVolumeMount)
+	}
+
+	if k6.GetSpec().Runner.BrowserMode {
+		browserVolume, browserVolumeMount := newBrowserVolume(&k6.GetSpec().Runner)
+		volumes = append(volumes, browserVolume)
+		volumeMounts = append(volumeMounts, browserVolumeMount)
+	}
+
+	if override := k6.GetSpec().ThresholdsOverride; override != nil {
+		thresholdsVolume, thresholdsVolumeMount := newThresholdsVolume(override)
+		volumes = append(volumes, thresholdsVolume)
+		volumeMounts = append(volumeMounts, thresholdsVolumeMount)
+	}
+
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
@@ -168,41 +338,48 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 			Annotations: runnerAnnotations,
 		},
 		Spec: batchv1.JobSpec{
-			BackoffLimit: &zero32,
+			BackoffLimit:          backoffLimit,
+			ActiveDeadlineSeconds: k6.GetSpec().Runner.ActiveDeadlineSeconds,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      runnerLabels,
-					Annotations: runnerAnnotations,
+					Annotations: podAnnotations,
 				},
 				Spec: corev1.PodSpec{
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					ServiceAccountName:           serviceAccountName,
-					Hostname:                     name,
-					RestartPolicy:                corev1.RestartPolicyNever,
+					Hostname:                     hostname,
+					Subdomain:                    subdomain,
+					RestartPolicy:                restartPolicy,
 					Affinity:                     k6.GetSpec().Runner.Affinity,
 					NodeSelector:                 k6.GetSpec().Runner.NodeSelector,
 					Tolerations:                  k6.GetSpec().Runner.Tolerations,
 					TopologySpreadConstraints:    k6.GetSpec().Runner.TopologySpreadConstraints,
-					SecurityContext:              &k6.GetSpec().Runner.SecurityContext,
-					ImagePullSecrets:             k6.GetSpec().Runner.ImagePullSecrets,
-					InitContainers:               getInitContainers(&k6.GetSpec().Runner, script),
+					HostAliases:                  k6.GetSpec().Runner.HostAliases,
+					DNSPolicy:                    k6.GetSpec().Runner.DNSPolicy,
+					DNSConfig:                    k6.GetSpec().Runner.DNSConfig,
+					SecurityContext:              newRunnerPodSecurityContext(k6.GetSpec().Runner.SecurityContext),
+					ImagePullSecrets:             newImagePullSecrets(k6.GetSpec().ImagePullSecrets, k6.GetSpec().Runner.ImagePullSecrets),
+					InitContainers:               getInitContainers(&k6.GetSpec().Runner.Pod, script),
 					Containers: []corev1.Container{{
 						Image:           image,
 						ImagePullPolicy: k6.GetSpec().Runner.ImagePullPolicy,
 						Name:            "k6",
 						Command:         command,
+						Args:            args,
 						Env:             env,
-						Resources:       k6.GetSpec().Runner.Resources,
+						Resources:       k6.RunnerResources(),
 						VolumeMounts:    volumeMounts,
 						Ports:           ports,
 						EnvFrom:         k6.GetSpec().Runner.EnvFrom,
 						LivenessProbe:   generateProbe(k6.GetSpec().Runner.LivenessProbe),
 						ReadinessProbe:  generateProbe(k6.GetSpec().Runner.ReadinessProbe),
-						SecurityContext: &k6.GetSpec().Runner.ContainerSecurityContext,
+						SecurityContext: newRunnerContainerSecurityContext(k6.GetSpec().Runner.ContainerSecurityContext, k6.GetSpec().Runner.BrowserMode),
 					}},
-					TerminationGracePeriodSeconds: &zero,
+					TerminationGracePeriodSeconds: terminationGracePeriodSeconds,
 					Volumes:                       volumes,
 					PriorityClassName:             k6.GetSpec().Runner.PriorityClassName,
+					RuntimeClassName:              k6.GetSpec().Runner.RuntimeClassName,
 				},
 			},
 		},
@@ -212,20 +389,89 @@ func NewRunnerJob(k6 *v1alpha1.TestRun, index int, tokenInfo *cloud.TokenInfo) (
 		job.Spec.Template.Spec.Affinity = newAntiAffinity()
 	}
 
+	if indexed {
+		completionMode := batchv1.IndexedCompletion
+		parallelism := k6.GetSpec().Parallelism
+		job.Spec.CompletionMode = &completionMode
+		job.Spec.Completions = &parallelism
+		job.Spec.Parallelism = &parallelism
+	}
+
 	return job, nil
 }
 
+// newIndexedCommand wraps the command of the Indexed Job of runners with
+// a shell script which sets the instance ID and the execution segment of
+// each runner from its completion index, in JOB_COMPLETION_INDEX.
+func newIndexedCommand(k6 *v1alpha1.TestRun, script *types.Script, command []string) ([]string, error) {
+	var b strings.Builder
+	if script.Type == "LocalFile" {
+		fmt.Fprintf(&b, "if [ ! -f %v ]; then echo \"LocalFile not found exiting...\"; exit 1; fi\n", script.FullName())
+	}
+	fmt.Fprintf(&b, "export %s=$((JOB_COMPLETION_INDEX + 1))\n", v1alpha1.InstanceIDEnv)
+
+	parallelism := int(k6.GetSpec().Parallelism)
+	if parallelism > 1 {
+		fmt.Fprintf(&b, "case \"$%s\" in\n", v1alpha1.InstanceIDEnv)
+		for index := 1; index <= parallelism; index++ {
+			segment, sequence, err := segmentation.NewSegment(index, parallelism)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, "%d) export K6_EXECUTION_SEGMENT='%s' K6_EXECUTION_SEGMENT_SEQUENCE='%s' ;;\n", index, segment, sequence)
+		}
+		b.WriteString("*) echo \"unexpected completion index $JOB_COMPLETION_INDEX\"; exit 1 ;;\nesac\n")
+	}
+
+	// the runner of completion index i is known as <name>-<i+1>, as with a Job per runner
+	fmt.Fprintf(&b, "exec \"$@\" --tag \"instance_id=$%[1]s\" --tag \"job_name=%[2]s-$%[1]s\"",
+		v1alpha1.InstanceIDEnv, k6.NamespacedName().Name)
+
+	return append([]string{"sh", "-c", b.String(), "k6"}, command...), nil
+}
+
+// newKeepCommand wraps the command of a runner with a shell script which
+// keeps the container running for keep after k6 has exited and then exits
+// with the exit code of k6. Termination signals are passed to k6 and end
+// the wait right away.
+func newKeepCommand(keep time.Duration, command []string) []string {
+	script := fmt.Sprintf(`"$@" &
+pid=$!
+trap 'stopping=1; kill -TERM $pid 2>/dev/null' TERM INT
+wait $pid
+code=$?
+while kill -0 $pid 2>/dev/null; do wait $pid; code=$?; done
+if [ -z "$stopping" ]; then
+  echo "k6 exited with code $code, keeping the runner for %[1]ds"
+  sleep %[1]d & wait $!
+fi
+exit $code`, int64(keep.Seconds()))
+
+	return append([]string{"sh", "-c", script, "k6"}, command...)
+}
+
 func NewRunnerService(k6 *v1alpha1.TestRun, index int) (*corev1.Service, error) {
-	serviceName := fmt.Sprintf("%s-%s-%d", k6.NamespacedName().Name, "service", index)
-	runnerName := fmt.Sprintf("%s-%d", k6.NamespacedName().Name, index)
+	serviceName, err := k6.RunnerServiceName(index)
+	if err != nil {
+		return nil, err
+	}
+	selector := map[string]string{
+		"job-name": fmt.Sprintf("%s-%d", k6.NamespacedName().Name, index),
+	}
+	if k6.GetSpec().IndexedRunners() {
+		// pods of Indexed Jobs are labeled with their completion index
+		selector = map[string]string{
+			"job-name":                           IndexedRunnerJobName(k6),
+			batchv1.JobCompletionIndexAnnotation: strconv.Itoa(index - 1),
+		}
+	}
 
 	runnerAnnotations := make(map[string]string)
 	if k6.GetSpec().Runner.Metadata.Annotations != nil {
 		runnerAnnotations = k6.GetSpec().Runner.Metadata.Annotations
 	}
 
-	runnerLabels := newLabels(k6.NamespacedName().Name)
-	runnerLabels["runner"] = "true"
+	runnerLabels := k6.RunnerLabels()
 	if k6.GetSpec().Runner.Metadata.Labels != nil {
 		for k, v := range k6.GetSpec().Runner.Metadata.Labels { // Order not specified
 			if _, ok := runnerLabels[k]; !ok {
@@ -233,12 +479,16 @@ func NewRunnerService(k6 *v1alpha1.TestRun, index int) (*corev1.Service, error)
 			}
 		}
 	}
+	runnerLabels, runnerAnnotations = inheritMetadata(k6, runnerLabels, runnerAnnotations)
 
 	port := []corev1.ServicePort{{
 		Name:     "http-api",
 		Port:     6565,
 		Protocol: "TCP",
 	}}
+	if k6.GetSpec().RunnerService != nil {
+		port = append(port, k6.GetSpec().RunnerService.ExtraPorts...)
+	}
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -248,16 +498,73 @@ func NewRunnerService(k6 *v1alpha1.TestRun, index int) (*corev1.Service, error)
 			Annotations: runnerAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: port,
-			Selector: map[string]string{
-				"job-name": runnerName,
-			},
+			Type:     k6.GetSpec().ServiceType,
+			Ports:    port,
+			Selector: selector,
 		},
 	}
 
 	return service, nil
 }
 
+// HeadlessServiceName returns the name of the headless Service of the runners.
+func HeadlessServiceName(k6 *v1alpha1.TestRun) string {
+	return fmt.Sprintf("%s-runners", k6.NamespacedName().Name)
+}
+
+// NewRunnerHeadlessService creates a headless Service selecting all runner pods,
+// so that each of them gets a stable DNS name.
+func NewRunnerHeadlessService(k6 *v1alpha1.TestRun) *corev1.Service {
+	runnerLabels := k6.RunnerLabels()
+	labels, annotations := inheritMetadata(k6, runnerLabels, nil)
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        HeadlessServiceName(k6),
+			Namespace:   k6.NamespacedName().Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{{
+				Name:     "http-api",
+				Port:     6565,
+				Protocol: "TCP",
+			}},
+			Selector: runnerLabels,
+			// Runners are checked by the operator directly so there is
+			// no need to wait for the readiness to resolve their names.
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
+
+// NewRunnerPodDisruptionBudget creates a PodDisruptionBudget which forbids
+// voluntary disruptions, like evictions during node drains, of the runner pods.
+func NewRunnerPodDisruptionBudget(k6 *v1alpha1.TestRun) *policyv1.PodDisruptionBudget {
+	runnerLabels := k6.RunnerLabels()
+
+	labels, annotations := inheritMetadata(k6, newLabels(k6.NamespacedName().Name), nil)
+
+	maxUnavailable := intstr.FromInt32(0)
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-runners", k6.NamespacedName().Name),
+			Namespace:   k6.NamespacedName().Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: runnerLabels,
+			},
+		},
+	}
+}
+
 func newAntiAffinity() *corev1.Affinity {
 	return &corev1.Affinity{
 		PodAntiAffinity: &corev1.PodAntiAffinity{
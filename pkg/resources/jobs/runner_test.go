@@ -1,8 +1,13 @@
 package jobs
 
 import (
+	"maps"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -13,6 +18,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // these are default values hard-coded in k6
@@ -35,6 +41,29 @@ var aggregationEnvVars = []corev1.EnvVar{
 	},
 }
 
+// instanceEnv is the env var with the index of the runner.
+func instanceEnv(index int) corev1.EnvVar {
+	return corev1.EnvVar{Name: "K6_INSTANCE_ID", Value: strconv.Itoa(index)}
+}
+
+// restrictedPodSecurityContext is the default security context of runner pods.
+func restrictedPodSecurityContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// restrictedContainerSecurityContext is the default security context of k6 containers of runners.
+func restrictedContainerSecurityContext() *corev1.SecurityContext {
+	allowPrivilegeEscalation, readOnlyRootFilesystem, runAsNonRoot := false, true, true
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+		RunAsNonRoot:             &runAsNonRoot,
+	}
+}
+
 func TestNewVolumeSpecVolumeClaim(t *testing.T) {
 	expectedOutcome := []corev1.Volume{
 		corev1.Volume{
@@ -165,13 +194,15 @@ func TestNewRunnerService(t *testing.T) {
 			Namespace: "test",
 		},
 		Spec: v1alpha1.TestRunSpec{
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -187,6 +218,101 @@ func TestNewRunnerService(t *testing.T) {
 	}
 }
 
+func TestNewRunnerServiceNodePort(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			ServiceType: corev1.ServiceTypeNodePort,
+		},
+	}
+
+	service, err := NewRunnerService(k6, 1)
+	if err != nil {
+		t.Fatalf("NewRunnerService errored: %v", err)
+	}
+	if service.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("NewRunnerService returned service of type %q, want %q", service.Spec.Type, corev1.ServiceTypeNodePort)
+	}
+}
+
+func TestNewRunnerServiceTemplate(t *testing.T) {
+	metricsPort := corev1.ServicePort{Name: "metrics", Port: 9090, Protocol: "TCP"}
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			RunnerService: &v1alpha1.K6RunnerService{
+				NameTemplate: "svc-{{.Name}}-{{.Index}}",
+				ExtraPorts:   []corev1.ServicePort{metricsPort},
+			},
+		},
+	}
+
+	service, err := NewRunnerService(k6, 2)
+	if err != nil {
+		t.Fatalf("NewRunnerService errored: %v", err)
+	}
+	if service.Name != "svc-test-2" {
+		t.Errorf("NewRunnerService returned service named %q, want %q", service.Name, "svc-test-2")
+	}
+	expectedPorts := []corev1.ServicePort{{Name: "http-api", Port: 6565, Protocol: "TCP"}, metricsPort}
+	if diff := deep.Equal(service.Spec.Ports, expectedPorts); diff != nil {
+		t.Errorf("NewRunnerService returned unexpected ports, diff: %s", diff)
+	}
+	if diff := deep.Equal(service.Spec.Selector, map[string]string{"job-name": "test-2"}); diff != nil {
+		t.Errorf("NewRunnerService returned unexpected selector, diff: %s", diff)
+	}
+	if service.Labels["runner"] != "true" || service.Labels["k6_cr"] != "test" {
+		t.Errorf("NewRunnerService returned service without runner labels: %v", service.Labels)
+	}
+}
+
+func TestNewRunnerHeadlessService(t *testing.T) {
+	expectedLabels := map[string]string{
+		"app":    "k6",
+		"k6_cr":  "test",
+		"runner": "true",
+	}
+
+	expectedOutcome := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-runners",
+			Namespace: "test",
+			Labels:    expectedLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{{
+				Name:     "http-api",
+				Port:     6565,
+				Protocol: "TCP",
+			}},
+			Selector:                 expectedLabels,
+			PublishNotReadyAddresses: true,
+		},
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			HeadlessService: true,
+		},
+	}
+
+	service := NewRunnerHeadlessService(k6)
+	if diff := deep.Equal(service, expectedOutcome); diff != nil {
+		t.Errorf("NewRunnerHeadlessService returned unexpected data, diff: %s", diff)
+	}
+}
+
 func TestNewRunnerJob(t *testing.T) {
 	script := &types.Script{
 		Name:     "test",
@@ -225,7 +351,7 @@ func TestNewRunnerJob(t *testing.T) {
 				Spec: corev1.PodSpec{
 					Hostname:                     "test-1",
 					RestartPolicy:                corev1.RestartPolicyNever,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Affinity:                     nil,
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
@@ -237,7 +363,7 @@ func TestNewRunnerJob(t *testing.T) {
 						ImagePullPolicy: corev1.PullNever,
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -268,7 +394,7 @@ func TestNewRunnerJob(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -288,25 +414,27 @@ func TestNewRunnerJob(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
-				},
-				EnvFrom: []corev1.EnvFromSource{
-					{
-						ConfigMapRef: &corev1.ConfigMapEnvSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: "env",
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "env",
+								},
 							},
 						},
 					},
+					ImagePullPolicy: corev1.PullNever,
 				},
-				ImagePullPolicy: corev1.PullNever,
 			},
 		},
 	}
@@ -364,13 +492,13 @@ func TestNewRunnerJobNoisy(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
 						Name:            "k6",
 						Command:         []string{"k6", "run", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -392,7 +520,7 @@ func TestNewRunnerJobNoisy(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -413,13 +541,15 @@ func TestNewRunnerJobNoisy(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -479,13 +609,13 @@ func TestNewRunnerJobUnpaused(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -507,7 +637,7 @@ func TestNewRunnerJobUnpaused(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -528,13 +658,15 @@ func TestNewRunnerJobUnpaused(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -594,13 +726,13 @@ func TestNewRunnerJobArguments(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "--cool-thing", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -622,7 +754,7 @@ func TestNewRunnerJobArguments(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -644,13 +776,15 @@ func TestNewRunnerJobArguments(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -710,13 +844,13 @@ func TestNewRunnerJobServiceAccount(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "test",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -738,7 +872,7 @@ func TestNewRunnerJobServiceAccount(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -760,14 +894,16 @@ func TestNewRunnerJobServiceAccount(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				ServiceAccountName: "test",
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					ServiceAccountName: "test",
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -827,7 +963,7 @@ func TestNewRunnerJobIstio(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
@@ -846,6 +982,7 @@ func TestNewRunnerJobIstio(t *testing.T) {
 								Name:  "WAIT_FOR_ENVOY_TIMEOUT",
 								Value: "15",
 							},
+							instanceEnv(1),
 						},
 						Resources:    corev1.ResourceRequirements{},
 						VolumeMounts: script.VolumeMount(),
@@ -868,7 +1005,7 @@ func TestNewRunnerJobIstio(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -891,13 +1028,15 @@ func TestNewRunnerJobIstio(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -955,7 +1094,7 @@ func TestNewRunnerJobCloud(t *testing.T) {
 					Tolerations:                  nil,
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
@@ -971,6 +1110,7 @@ func TestNewRunnerJobCloud(t *testing.T) {
 								Name:  "K6_CLOUD_TOKEN",
 								Value: "token",
 							},
+							instanceEnv(1),
 						),
 						Resources:    corev1.ResourceRequirements{},
 						VolumeMounts: script.VolumeMount(),
@@ -993,7 +1133,7 @@ func TestNewRunnerJobCloud(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -1014,10 +1154,12 @@ func TestNewRunnerJobCloud(t *testing.T) {
 				},
 			},
 			Arguments: "--out cloud",
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -1082,13 +1224,13 @@ func TestNewRunnerJobLocalFile(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
 						Name:            "k6",
 						Command:         []string{"sh", "-c", "if [ ! -f /test/test.js ]; then echo \"LocalFile not found exiting...\"; exit 1; fi;\nk6 run --quiet /test/test.js --address=0.0.0.0:6565 --paused --tag instance_id=1 --tag job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -1110,7 +1252,7 @@ func TestNewRunnerJobLocalFile(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -1130,13 +1272,15 @@ func TestNewRunnerJobLocalFile(t *testing.T) {
 			Script: v1alpha1.K6Script{
 				LocalFile: "/test/test.js",
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
 				},
 			},
@@ -1190,7 +1334,7 @@ func TestNewRunnerJobWithInitContainer(t *testing.T) {
 				Spec: corev1.PodSpec{
 					Hostname:                     "test-1",
 					RestartPolicy:                corev1.RestartPolicyNever,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Affinity:                     nil,
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
@@ -1221,7 +1365,7 @@ func TestNewRunnerJobWithInitContainer(t *testing.T) {
 						ImagePullPolicy: corev1.PullNever,
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -1252,7 +1396,7 @@ func TestNewRunnerJobWithInitContainer(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -1272,34 +1416,36 @@ func TestNewRunnerJobWithInitContainer(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
-				},
-				EnvFrom: []corev1.EnvFromSource{
-					{
-						ConfigMapRef: &corev1.ConfigMapEnvSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: "env",
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "env",
+								},
 							},
 						},
 					},
-				},
-				ImagePullPolicy: corev1.PullNever,
-				InitContainers: []v1alpha1.InitContainer{
-					{
-						Image:   "busybox:1.28",
-						Command: []string{"sh", "-c", "cat /test/test.js"},
-						EnvFrom: []corev1.EnvFromSource{
-							{
-								ConfigMapRef: &corev1.ConfigMapEnvSource{
-									LocalObjectReference: corev1.LocalObjectReference{
-										Name: "env",
+					ImagePullPolicy: corev1.PullNever,
+					InitContainers: []v1alpha1.InitContainer{
+						{
+							Image:   "busybox:1.28",
+							Command: []string{"sh", "-c", "cat /test/test.js"},
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "env",
+										},
 									},
 								},
 							},
@@ -1369,7 +1515,7 @@ func TestNewRunnerJobWithVolume(t *testing.T) {
 				Spec: corev1.PodSpec{
 					Hostname:                     "test-1",
 					RestartPolicy:                corev1.RestartPolicyNever,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Affinity:                     nil,
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
@@ -1400,7 +1546,7 @@ func TestNewRunnerJobWithVolume(t *testing.T) {
 						ImagePullPolicy: corev1.PullNever,
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    expectedVolumeMounts,
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -1431,7 +1577,7 @@ func TestNewRunnerJobWithVolume(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       expectedVolumes,
@@ -1451,58 +1597,60 @@ func TestNewRunnerJobWithVolume(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
-				},
-				EnvFrom: []corev1.EnvFromSource{
-					{
-						ConfigMapRef: &corev1.ConfigMapEnvSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: "env",
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "env",
+								},
 							},
 						},
 					},
-				},
-				ImagePullPolicy: corev1.PullNever,
-				InitContainers: []v1alpha1.InitContainer{
-					{
-						Image:   "busybox:1.28",
-						Command: []string{"sh", "-c", "cat /test/test.js"},
-						EnvFrom: []corev1.EnvFromSource{
-							{
-								ConfigMapRef: &corev1.ConfigMapEnvSource{
-									LocalObjectReference: corev1.LocalObjectReference{
-										Name: "env",
+					ImagePullPolicy: corev1.PullNever,
+					InitContainers: []v1alpha1.InitContainer{
+						{
+							Image:   "busybox:1.28",
+							Command: []string{"sh", "-c", "cat /test/test.js"},
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "env",
+										},
 									},
 								},
 							},
-						},
-						VolumeMounts: []corev1.VolumeMount{
-							corev1.VolumeMount{
-								Name:      "k6-test-volume",
-								MountPath: "/test/location",
+							VolumeMounts: []corev1.VolumeMount{
+								corev1.VolumeMount{
+									Name:      "k6-test-volume",
+									MountPath: "/test/location",
+								},
 							},
 						},
 					},
-				},
-				Volumes: []corev1.Volume{
-					corev1.Volume{
-						Name: "k6-test-volume",
-						VolumeSource: corev1.VolumeSource{
-							EmptyDir: &corev1.EmptyDirVolumeSource{},
+					Volumes: []corev1.Volume{
+						corev1.Volume{
+							Name: "k6-test-volume",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
 						},
 					},
-				},
-				VolumeMounts: []corev1.VolumeMount{
-					corev1.VolumeMount{
-						Name:      "k6-test-volume",
-						MountPath: "/test/location",
+					VolumeMounts: []corev1.VolumeMount{
+						corev1.VolumeMount{
+							Name:      "k6-test-volume",
+							MountPath: "/test/location",
+						},
 					},
 				},
 			},
@@ -1558,7 +1706,7 @@ func TestNewRunnerJobPLZTestRun(t *testing.T) {
 				Spec: corev1.PodSpec{
 					Hostname:                     "test-1",
 					RestartPolicy:                corev1.RestartPolicyNever,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Affinity:                     nil,
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
@@ -1570,7 +1718,7 @@ func TestNewRunnerJobPLZTestRun(t *testing.T) {
 						ImagePullPolicy: corev1.PullNever,
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1", "--no-setup", "--no-teardown", "--linger"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -1601,7 +1749,7 @@ func TestNewRunnerJobPLZTestRun(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -1621,25 +1769,27 @@ func TestNewRunnerJobPLZTestRun(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
-				},
-				EnvFrom: []corev1.EnvFromSource{
-					{
-						ConfigMapRef: &corev1.ConfigMapEnvSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: "env",
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: "env",
+								},
 							},
 						},
 					},
+					ImagePullPolicy: corev1.PullNever,
 				},
-				ImagePullPolicy: corev1.PullNever,
 			},
 		},
 		Status: v1alpha1.TestRunStatus{
@@ -1707,13 +1857,13 @@ func TestNewRunnerJobPriorityClassName(t *testing.T) {
 					TopologySpreadConstraints:    nil,
 					ServiceAccountName:           "default",
 					AutomountServiceAccountToken: &automountServiceAccountToken,
-					SecurityContext:              &corev1.PodSecurityContext{},
+					SecurityContext:              restrictedPodSecurityContext(),
 					Containers: []corev1.Container{{
 						Image:           "grafana/k6:latest",
 						ImagePullPolicy: "",
 						Name:            "k6",
 						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
-						Env:             []corev1.EnvVar{},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
 						Resources:       corev1.ResourceRequirements{},
 						VolumeMounts:    script.VolumeMount(),
 						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
@@ -1735,7 +1885,7 @@ func TestNewRunnerJobPriorityClassName(t *testing.T) {
 								},
 							},
 						},
-						SecurityContext: &corev1.SecurityContext{},
+						SecurityContext: restrictedContainerSecurityContext(),
 					}},
 					TerminationGracePeriodSeconds: &zero,
 					Volumes:                       script.Volume(),
@@ -1758,16 +1908,18 @@ func TestNewRunnerJobPriorityClassName(t *testing.T) {
 					File: "test.js",
 				},
 			},
-			Runner: v1alpha1.Pod{
-				Metadata: v1alpha1.PodMetadata{
-					Labels: map[string]string{
-						"label1": "awesome",
-					},
-					Annotations: map[string]string{
-						"awesomeAnnotation": "dope",
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
 					},
+					PriorityClassName: "high-priority",
 				},
-				PriorityClassName: "high-priority",
 			},
 		},
 	}
@@ -1781,3 +1933,1452 @@ func TestNewRunnerJobPriorityClassName(t *testing.T) {
 		t.Errorf("NewRunnerJob returned unexpected data, diff: %s", diff)
 	}
 }
+
+func TestNewRunnerJobOutputVolume(t *testing.T) {
+
+	script := &types.Script{
+		Name:     "test",
+		Filename: "thing.js",
+		Type:     "ConfigMap",
+	}
+
+	var zero int64 = 0
+	automountServiceAccountToken := true
+
+	expectedLabels := map[string]string{
+		"app":    "k6",
+		"k6_cr":  "test",
+		"runner": "true",
+		"label1": "awesome",
+	}
+
+	expectedOutcome := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-1",
+			Namespace: "test",
+			Labels:    expectedLabels,
+			Annotations: map[string]string{
+				"awesomeAnnotation": "dope",
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: new(int32),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: expectedLabels,
+					Annotations: map[string]string{
+						"awesomeAnnotation": "dope",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Hostname:                     "test-1",
+					RestartPolicy:                corev1.RestartPolicyNever,
+					Affinity:                     nil,
+					NodeSelector:                 nil,
+					Tolerations:                  nil,
+					TopologySpreadConstraints:    nil,
+					ServiceAccountName:           "default",
+					AutomountServiceAccountToken: &automountServiceAccountToken,
+					SecurityContext:              restrictedPodSecurityContext(),
+					Containers: []corev1.Container{{
+						Image:           "grafana/k6:latest",
+						ImagePullPolicy: "",
+						Name:            "k6",
+						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
+						Resources:       corev1.ResourceRequirements{},
+						VolumeMounts: append(script.VolumeMount(), corev1.VolumeMount{
+							Name:      "k6-output-volume",
+							MountPath: "/results",
+						}),
+						Ports: []corev1.ContainerPort{{ContainerPort: 6565}},
+						LivenessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						SecurityContext: restrictedContainerSecurityContext(),
+					}},
+					TerminationGracePeriodSeconds: &zero,
+					Volumes: append(script.Volume(), corev1.Volume{
+						Name: "k6-output-volume",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+								ClaimName: "results-1",
+							},
+						},
+					}),
+				},
+			},
+		},
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
+					},
+				},
+			},
+			OutputVolume: &v1alpha1.K6OutputVolume{
+				ClaimName: "results",
+				MountPath: "/results",
+				PerRunner: true,
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Errorf("NewRunnerJob errored, got: %v", err)
+	}
+
+	if diff := deep.Equal(job, expectedOutcome); diff != nil {
+		t.Errorf("NewRunnerJob returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobBackoffLimit(t *testing.T) {
+
+	script := &types.Script{
+		Name:     "test",
+		Filename: "thing.js",
+		Type:     "ConfigMap",
+	}
+
+	var (
+		zero         int64 = 0
+		deadline     int64 = 600
+		backoffLimit int32 = 2
+	)
+	automountServiceAccountToken := true
+
+	expectedLabels := map[string]string{
+		"app":    "k6",
+		"k6_cr":  "test",
+		"runner": "true",
+		"label1": "awesome",
+	}
+
+	expectedOutcome := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-1",
+			Namespace: "test",
+			Labels:    expectedLabels,
+			Annotations: map[string]string{
+				"awesomeAnnotation": "dope",
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &deadline,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: expectedLabels,
+					Annotations: map[string]string{
+						"awesomeAnnotation": "dope",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Hostname:                     "test-1",
+					RestartPolicy:                corev1.RestartPolicyNever,
+					Affinity:                     nil,
+					NodeSelector:                 nil,
+					Tolerations:                  nil,
+					TopologySpreadConstraints:    nil,
+					ServiceAccountName:           "default",
+					AutomountServiceAccountToken: &automountServiceAccountToken,
+					SecurityContext:              restrictedPodSecurityContext(),
+					Containers: []corev1.Container{{
+						Image:           "grafana/k6:latest",
+						ImagePullPolicy: "",
+						Name:            "k6",
+						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
+						Resources:       corev1.ResourceRequirements{},
+						VolumeMounts:    script.VolumeMount(),
+						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
+						LivenessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						SecurityContext: restrictedContainerSecurityContext(),
+					}},
+					TerminationGracePeriodSeconds: &zero,
+					Volumes:                       script.Volume(),
+				},
+			},
+		},
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
+					},
+					BackoffLimit:          &backoffLimit,
+					ActiveDeadlineSeconds: &deadline,
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Errorf("NewRunnerJob errored, got: %v", err)
+	}
+
+	if diff := deep.Equal(job, expectedOutcome); diff != nil {
+		t.Errorf("NewRunnerJob returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobDNS(t *testing.T) {
+
+	script := &types.Script{
+		Name:     "test",
+		Filename: "thing.js",
+		Type:     "ConfigMap",
+	}
+
+	var zero int64 = 0
+	hostAliases := []corev1.HostAlias{{IP: "10.0.0.10", Hostnames: []string{"target.internal"}}}
+	dnsConfig := &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.53"}, Searches: []string{"internal"}}
+	automountServiceAccountToken := true
+
+	expectedLabels := map[string]string{
+		"app":    "k6",
+		"k6_cr":  "test",
+		"runner": "true",
+		"label1": "awesome",
+	}
+
+	expectedOutcome := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-1",
+			Namespace: "test",
+			Labels:    expectedLabels,
+			Annotations: map[string]string{
+				"awesomeAnnotation": "dope",
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: new(int32),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: expectedLabels,
+					Annotations: map[string]string{
+						"awesomeAnnotation": "dope",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Hostname:                     "test-1",
+					RestartPolicy:                corev1.RestartPolicyNever,
+					Affinity:                     nil,
+					NodeSelector:                 nil,
+					Tolerations:                  nil,
+					TopologySpreadConstraints:    nil,
+					HostAliases:                  hostAliases,
+					DNSPolicy:                    corev1.DNSNone,
+					DNSConfig:                    dnsConfig,
+					ServiceAccountName:           "default",
+					AutomountServiceAccountToken: &automountServiceAccountToken,
+					SecurityContext:              restrictedPodSecurityContext(),
+					Containers: []corev1.Container{{
+						Image:           "grafana/k6:latest",
+						ImagePullPolicy: "",
+						Name:            "k6",
+						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
+						Env:             []corev1.EnvVar{instanceEnv(1)},
+						Resources:       corev1.ResourceRequirements{},
+						VolumeMounts:    script.VolumeMount(),
+						Ports:           []corev1.ContainerPort{{ContainerPort: 6565}},
+						LivenessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						SecurityContext: restrictedContainerSecurityContext(),
+					}},
+					TerminationGracePeriodSeconds: &zero,
+					Volumes:                       script.Volume(),
+				},
+			},
+		},
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
+					},
+					HostAliases: hostAliases,
+					DNSPolicy:   corev1.DNSNone,
+					DNSConfig:   dnsConfig,
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Errorf("NewRunnerJob errored, got: %v", err)
+	}
+
+	if diff := deep.Equal(job, expectedOutcome); diff != nil {
+		t.Errorf("NewRunnerJob returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobSegmentEnv(t *testing.T) {
+
+	script := &types.Script{
+		Name:     "test",
+		Filename: "thing.js",
+		Type:     "ConfigMap",
+	}
+
+	var zero int64 = 0
+	automountServiceAccountToken := true
+
+	expectedLabels := map[string]string{
+		"app":    "k6",
+		"k6_cr":  "test",
+		"runner": "true",
+		"label1": "awesome",
+	}
+
+	expectedOutcome := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-1",
+			Namespace: "test",
+			Labels:    expectedLabels,
+			Annotations: map[string]string{
+				"awesomeAnnotation": "dope",
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: new(int32),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: expectedLabels,
+					Annotations: map[string]string{
+						"awesomeAnnotation": "dope",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Hostname:                     "test-1",
+					RestartPolicy:                corev1.RestartPolicyNever,
+					Affinity:                     nil,
+					NodeSelector:                 nil,
+					Tolerations:                  nil,
+					TopologySpreadConstraints:    nil,
+					ServiceAccountName:           "default",
+					AutomountServiceAccountToken: &automountServiceAccountToken,
+					SecurityContext:              restrictedPodSecurityContext(),
+					Containers: []corev1.Container{{
+						Image:           "grafana/k6:latest",
+						ImagePullPolicy: "",
+						Name:            "k6",
+						Command:         []string{"k6", "run", "--quiet", "/test/test.js", "--address=0.0.0.0:6565", "--paused", "--tag", "instance_id=1", "--tag", "job_name=test-1"},
+						Env: []corev1.EnvVar{
+							{Name: "K6_EXECUTION_SEGMENT", Value: "0:1/2"},
+							{Name: "K6_EXECUTION_SEGMENT_SEQUENCE", Value: "0,1/2,1"},
+							instanceEnv(1),
+						},
+						Resources:    corev1.ResourceRequirements{},
+						VolumeMounts: script.VolumeMount(),
+						Ports:        []corev1.ContainerPort{{ContainerPort: 6565}},
+						LivenessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{
+									Path:   "/v1/status",
+									Port:   intstr.IntOrString{IntVal: 6565},
+									Scheme: "HTTP",
+								},
+							},
+						},
+						SecurityContext: restrictedContainerSecurityContext(),
+					}},
+					TerminationGracePeriodSeconds: &zero,
+					Volumes:                       script.Volume(),
+				},
+			},
+		},
+	}
+
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism:         2,
+			ExecutionSegmentEnv: true,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Metadata: v1alpha1.PodMetadata{
+						Labels: map[string]string{
+							"label1": "awesome",
+						},
+						Annotations: map[string]string{
+							"awesomeAnnotation": "dope",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Errorf("NewRunnerJob errored, got: %v", err)
+	}
+
+	if diff := deep.Equal(job, expectedOutcome); diff != nil {
+		t.Errorf("NewRunnerJob returned unexpected data, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobAddress(t *testing.T) {
+	testCases := []struct {
+		name        string
+		parallelism int32
+		headless    bool
+	}{
+		// runners behind a Service with a ClusterIP
+		{"service", 2, false},
+		// runners addressed by DNS names of their Pods
+		{"headless service", 2, true},
+		// runners addressed by IPs of their Pods
+		{"standalone", 1, false},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test",
+			},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism:     tc.parallelism,
+				HeadlessService: tc.headless,
+				Script: v1alpha1.K6Script{
+					ConfigMap: v1alpha1.K6Configmap{
+						Name: "test",
+						File: "test.js",
+					},
+				},
+			},
+		}
+
+		job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+		if err != nil {
+			t.Fatalf("%s: NewRunnerJob errored, got: %v", tc.name, err)
+		}
+
+		// the REST API of k6 must be reachable from outside of the Pod
+		// and on the port exposed by the container
+		container := job.Spec.Template.Spec.Containers[0]
+		var addresses []string
+		for _, arg := range container.Command {
+			if strings.HasPrefix(arg, "--address") {
+				addresses = append(addresses, arg)
+			}
+		}
+		if diff := deep.Equal(addresses, []string{"--address=0.0.0.0:6565"}); diff != nil {
+			t.Errorf("%s: unexpected bind address, diff: %s", tc.name, diff)
+		}
+		if diff := deep.Equal(container.Ports[0], corev1.ContainerPort{ContainerPort: 6565}); diff != nil {
+			t.Errorf("%s: unexpected container port, diff: %s", tc.name, diff)
+		}
+	}
+}
+
+func TestNewRunnerJobTerminationGracePeriod(t *testing.T) {
+	var (
+		zero  int64 = 0
+		grace int64 = 120
+	)
+
+	testCases := []struct {
+		name     string
+		grace    *int64
+		expected *int64
+	}{
+		{"default", nil, &zero},
+		{"custom", &grace, &grace},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test",
+			},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism: 1,
+				Script: v1alpha1.K6Script{
+					ConfigMap: v1alpha1.K6Configmap{
+						Name: "test",
+						File: "test.js",
+					},
+				},
+				Runner: v1alpha1.RunnerPod{
+					TerminationGracePeriodSeconds: tc.grace,
+				},
+			},
+		}
+
+		job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+		if err != nil {
+			t.Fatalf("%s: NewRunnerJob errored, got: %v", tc.name, err)
+		}
+
+		if diff := deep.Equal(job.Spec.Template.Spec.TerminationGracePeriodSeconds, tc.expected); diff != nil {
+			t.Errorf("%s: unexpected termination grace period, diff: %s", tc.name, diff)
+		}
+	}
+}
+
+func TestNewRunnerJobScriptURL(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				URL: &v1alpha1.K6ScriptURL{
+					URL: "https://raw.example.com/team/tests/main/script.js",
+					AuthSecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "git-token"},
+						Key:                  "authorization",
+					},
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	expectedInitContainers := []corev1.Container{{
+		Name:  types.ScriptFetchContainerName,
+		Image: types.DefaultScriptFetchImage,
+		Command: []string{
+			"curl", "--fail", "--silent", "--show-error", "--location", "--retry", "3", "--output", "/test/script.js",
+			"--header", "Authorization: $(K6_SCRIPT_AUTHORIZATION)",
+			"https://raw.example.com/team/tests/main/script.js",
+		},
+		Env: []corev1.EnvVar{{
+			Name:      "K6_SCRIPT_AUTHORIZATION",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: k6.Spec.Script.URL.AuthSecretRef},
+		}},
+		VolumeMounts:             []corev1.VolumeMount{{Name: "k6-test-volume", MountPath: "/test"}},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext:          &corev1.SecurityContext{},
+	}}
+	if diff := deep.Equal(job.Spec.Template.Spec.InitContainers, expectedInitContainers); diff != nil {
+		t.Errorf("unexpected init containers, diff: %s", diff)
+	}
+
+	expectedVolumes := []corev1.Volume{{
+		Name:         "k6-test-volume",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}}
+	if diff := deep.Equal(job.Spec.Template.Spec.Volumes, expectedVolumes); diff != nil {
+		t.Errorf("unexpected volumes, diff: %s", diff)
+	}
+
+	container := job.Spec.Template.Spec.Containers[0]
+	if diff := deep.Equal(container.VolumeMounts, []corev1.VolumeMount{{Name: "k6-test-volume", MountPath: "/test", ReadOnly: true}}); diff != nil {
+		t.Errorf("unexpected volume mounts, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobRestartPolicy(t *testing.T) {
+	var (
+		zero32 int32 = 0
+		three  int32 = 3
+	)
+
+	testCases := []struct {
+		name                  string
+		restartPolicy         corev1.RestartPolicy
+		backoffLimit          *int32
+		expectedRestartPolicy corev1.RestartPolicy
+		expectedBackoffLimit  *int32
+	}{
+		// a failed runner must not apply its load again by default
+		{"default", "", nil, corev1.RestartPolicyNever, &zero32},
+		{"retried", corev1.RestartPolicyOnFailure, &three, corev1.RestartPolicyOnFailure, &three},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test",
+			},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism: 2,
+				Script: v1alpha1.K6Script{
+					ConfigMap: v1alpha1.K6Configmap{
+						Name: "test",
+						File: "test.js",
+					},
+				},
+				Runner: v1alpha1.RunnerPod{
+					Pod: v1alpha1.Pod{
+						BackoffLimit: tc.backoffLimit,
+					},
+					RestartPolicy: tc.restartPolicy,
+				},
+			},
+		}
+
+		job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+		if err != nil {
+			t.Fatalf("%s: NewRunnerJob errored, got: %v", tc.name, err)
+		}
+
+		if job.Spec.Template.Spec.RestartPolicy != tc.expectedRestartPolicy {
+			t.Errorf("%s: expected restart policy %s, got %s", tc.name, tc.expectedRestartPolicy, job.Spec.Template.Spec.RestartPolicy)
+		}
+		if diff := deep.Equal(job.Spec.BackoffLimit, tc.expectedBackoffLimit); diff != nil {
+			t.Errorf("%s: unexpected backoff limit, diff: %s", tc.name, diff)
+		}
+		// each runner Job runs exactly one Pod
+		if job.Spec.Completions != nil || job.Spec.Parallelism != nil {
+			t.Errorf("%s: expected the default single Pod per Job, got completions %v and parallelism %v",
+				tc.name, job.Spec.Completions, job.Spec.Parallelism)
+		}
+	}
+}
+
+func TestNewRunnerJobCommandOverride(t *testing.T) {
+	segmentArgs := []string{"--execution-segment=0:1/2", "--execution-segment-sequence=0,1/2,1"}
+
+	testCases := []struct {
+		name            string
+		command         []string
+		args            []string
+		segmentEnv      bool
+		expectedCommand []string
+		expectedArgs    []string
+		expectedEnv     []corev1.EnvVar
+	}{
+		{
+			name:            "command",
+			command:         []string{"/wrapper.sh", "--paused"},
+			expectedCommand: []string{"/wrapper.sh", "--paused"},
+			expectedArgs:    segmentArgs,
+			expectedEnv:     []corev1.EnvVar{instanceEnv(1)},
+		},
+		{
+			name:         "args",
+			args:         []string{"run", "--paused", "/test/test.js"},
+			expectedArgs: append([]string{"run", "--paused", "/test/test.js"}, segmentArgs...),
+			expectedEnv:  []corev1.EnvVar{instanceEnv(1)},
+		},
+		{
+			name:            "command and args",
+			command:         []string{"sh", "-c", `prepare && k6 "$@"`, "--"},
+			args:            []string{"run", "-p", "/test/test.js"},
+			expectedCommand: []string{"sh", "-c", `prepare && k6 "$@"`, "--"},
+			expectedArgs:    append([]string{"run", "-p", "/test/test.js"}, segmentArgs...),
+			expectedEnv:     []corev1.EnvVar{instanceEnv(1)},
+		},
+		{
+			name:            "command with segment in env",
+			command:         []string{"/wrapper.sh", "--paused"},
+			segmentEnv:      true,
+			expectedCommand: []string{"/wrapper.sh", "--paused"},
+			expectedArgs:    []string{},
+			expectedEnv: []corev1.EnvVar{
+				{Name: "K6_EXECUTION_SEGMENT", Value: "0:1/2"},
+				{Name: "K6_EXECUTION_SEGMENT_SEQUENCE", Value: "0,1/2,1"},
+				instanceEnv(1),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test",
+			},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism:         2,
+				ExecutionSegmentEnv: tc.segmentEnv,
+				Script: v1alpha1.K6Script{
+					ConfigMap: v1alpha1.K6Configmap{
+						Name: "test",
+						File: "test.js",
+					},
+				},
+				Runner: v1alpha1.RunnerPod{
+					Command: tc.command,
+					Args:    tc.args,
+				},
+			},
+		}
+
+		job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+		if err != nil {
+			t.Fatalf("%s: NewRunnerJob errored, got: %v", tc.name, err)
+		}
+
+		container := job.Spec.Template.Spec.Containers[0]
+		if diff := deep.Equal(container.Command, tc.expectedCommand); diff != nil {
+			t.Errorf("%s: unexpected command, diff: %s", tc.name, diff)
+		}
+		if diff := deep.Equal(container.Args, tc.expectedArgs); diff != nil {
+			t.Errorf("%s: unexpected args, diff: %s", tc.name, diff)
+		}
+		if diff := deep.Equal(container.Env, tc.expectedEnv); diff != nil {
+			t.Errorf("%s: unexpected env, diff: %s", tc.name, diff)
+		}
+	}
+}
+
+func TestNewRunnerJobCommandOverridePaused(t *testing.T) {
+	testCases := []struct {
+		name        string
+		parallelism int32
+		paused      string
+		runner      v1alpha1.RunnerPod
+		isValid     bool
+	}{
+		{"not paused", 2, "", v1alpha1.RunnerPod{Command: []string{"/wrapper.sh"}}, false},
+		{"paused in env", 2, "", v1alpha1.RunnerPod{
+			Pod:     v1alpha1.Pod{Env: []corev1.EnvVar{{Name: "K6_PAUSED", Value: "true"}}},
+			Command: []string{"/wrapper.sh"},
+		}, true},
+		{"disabled in env", 2, "", v1alpha1.RunnerPod{
+			Pod:     v1alpha1.Pod{Env: []corev1.EnvVar{{Name: "K6_PAUSED", Value: "false"}}},
+			Command: []string{"/wrapper.sh"},
+		}, false},
+		{"pausing disabled", 2, "false", v1alpha1.RunnerPod{Command: []string{"/wrapper.sh"}}, true},
+		{"standalone", 1, "", v1alpha1.RunnerPod{Command: []string{"/wrapper.sh"}}, true},
+	}
+
+	for _, tc := range testCases {
+		k6 := &v1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "test",
+			},
+			Spec: v1alpha1.TestRunSpec{
+				Parallelism: tc.parallelism,
+				Paused:      tc.paused,
+				Script: v1alpha1.K6Script{
+					ConfigMap: v1alpha1.K6Configmap{
+						Name: "test",
+						File: "test.js",
+					},
+				},
+				Runner: tc.runner,
+			},
+		}
+
+		_, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+		if tc.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.isValid && err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestNewRunnerJobTracing(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Tracing: &v1alpha1.K6Tracing{Env: "TRACE_ID"},
+		},
+		Status: v1alpha1.TestRunStatus{
+			TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	expected := corev1.EnvVar{Name: "TRACE_ID", Value: "4bf92f3577b34da6a3ce929d0e0e4736"}
+	if diff := deep.Equal(job.Spec.Template.Spec.Containers[0].Env, []corev1.EnvVar{instanceEnv(1), expected}); diff != nil {
+		t.Errorf("unexpected env, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobSeed(t *testing.T) {
+	seed := int64(42)
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism:         2,
+			ExecutionSegmentEnv: true,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Seed: &seed,
+		},
+	}
+
+	for index := 1; index <= 2; index++ {
+		job, err := NewRunnerJob(k6, index, cloud.NewTokenInfo("", ""))
+		if err != nil {
+			t.Fatalf("NewRunnerJob errored, got: %v", err)
+		}
+
+		env := job.Spec.Template.Spec.Containers[0].Env
+		expected := corev1.EnvVar{Name: "K6_SEED", Value: "42"}
+		if diff := deep.Equal(env[len(env)-1], expected); diff != nil {
+			t.Errorf("runner %d: unexpected env, diff: %s", index, diff)
+		}
+	}
+}
+
+func TestNewRunnerJobInstanceID(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 3,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 2, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	container := job.Spec.Template.Spec.Containers[0]
+	if diff := deep.Equal(container.Env, []corev1.EnvVar{instanceEnv(2)}); diff != nil {
+		t.Errorf("unexpected env, diff: %s", diff)
+	}
+	if !strings.Contains(strings.Join(container.Command, " "), "--tag instance_id=2") {
+		t.Errorf("expected the instance_id tag to match the env var, got command %v", container.Command)
+	}
+}
+
+func TestNewRunnerJobPerRunnerScripts(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 2,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "flow-a",
+					File: "a.js",
+				},
+			},
+			PerRunnerScripts: map[string]v1alpha1.K6Script{
+				"2": {ConfigMap: v1alpha1.K6Configmap{Name: "flow-b", File: "b.js"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		index     int
+		configMap string
+		script    string
+	}{
+		{1, "flow-a", "/test/a.js"},
+		{2, "flow-b", "/test/b.js"},
+	}
+
+	for _, tc := range testCases {
+		job, err := NewRunnerJob(k6, tc.index, cloud.NewTokenInfo("", ""))
+		if err != nil {
+			t.Fatalf("NewRunnerJob errored, got: %v", err)
+		}
+
+		command := job.Spec.Template.Spec.Containers[0].Command
+		if !slices.Contains(command, tc.script) {
+			t.Errorf("runner %d: expected script %s, got command %v", tc.index, tc.script, command)
+		}
+		// each runner executes the whole of its script
+		if strings.Contains(strings.Join(command, " "), "--execution-segment") {
+			t.Errorf("runner %d: expected no execution segment, got command %v", tc.index, command)
+		}
+
+		volumes := job.Spec.Template.Spec.Volumes
+		if len(volumes) == 0 || volumes[0].ConfigMap == nil || volumes[0].ConfigMap.Name != tc.configMap {
+			t.Errorf("runner %d: expected the script volume of ConfigMap %s, got %+v", tc.index, tc.configMap, volumes)
+		}
+	}
+}
+
+func TestNewRunnerJobBrowserMode(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Env: []corev1.EnvVar{{Name: "K6_BROWSER_ARGS", Value: "no-sandbox,disable-gpu"}},
+				},
+				BrowserMode: true,
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	container := job.Spec.Template.Spec.Containers[0]
+	if container.Image != v1alpha1.DefaultBrowserRunnerImage {
+		t.Errorf("expected image %s, got %s", v1alpha1.DefaultBrowserRunnerImage, container.Image)
+	}
+
+	expectedEnv := []corev1.EnvVar{
+		instanceEnv(1),
+		{Name: "K6_BROWSER_HEADLESS", Value: "true"},
+		{Name: "K6_BROWSER_ARGS", Value: "no-sandbox,disable-gpu"},
+	}
+	if diff := deep.Equal(container.Env, expectedEnv); diff != nil {
+		t.Errorf("unexpected env, diff: %s", diff)
+	}
+
+	shmSize := v1alpha1.DefaultBrowserShmSize
+	expectedVolume := corev1.Volume{
+		Name: "k6-browser-shm",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: &shmSize},
+		},
+	}
+	volumes := job.Spec.Template.Spec.Volumes
+	if diff := deep.Equal(volumes[len(volumes)-1], expectedVolume); diff != nil {
+		t.Errorf("unexpected shm volume, diff: %s", diff)
+	}
+	expectedMount := corev1.VolumeMount{Name: "k6-browser-shm", MountPath: "/dev/shm"}
+	if diff := deep.Equal(container.VolumeMounts[len(container.VolumeMounts)-1], expectedMount); diff != nil {
+		t.Errorf("unexpected shm volume mount, diff: %s", diff)
+	}
+
+	if sc := container.SecurityContext; sc.ReadOnlyRootFilesystem == nil || *sc.ReadOnlyRootFilesystem {
+		t.Errorf("expected a writable root filesystem, got %+v", sc)
+	}
+}
+
+func TestNewRunnerJobKeepRunnersAfterFinish(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			KeepRunnersAfterFinish: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	command := job.Spec.Template.Spec.Containers[0].Command
+	if len(command) < 5 || command[0] != "sh" || command[1] != "-c" || command[3] != "k6" {
+		t.Fatalf("expected k6 to be wrapped by a shell script, got %v", command)
+	}
+	if !strings.Contains(command[2], "sleep 600 & wait $!") || !strings.Contains(command[2], "exit $code") {
+		t.Errorf("expected the script to keep the runner for 600s, got %q", command[2])
+	}
+	if diff := deep.Equal(command[4:6], []string{"k6", "run"}); diff != nil {
+		t.Errorf("expected k6 run to be passed to the script, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobThresholdsOverride(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			ThresholdsOverride: &v1alpha1.K6ThresholdsOverride{
+				ConfigMap: v1alpha1.K6Configmap{Name: "ci-thresholds", File: "strict.json"},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	container := job.Spec.Template.Spec.Containers[0]
+	if !slices.Contains(container.Command, "/thresholds/script.js") || slices.Contains(container.Command, "/test/test.js") {
+		t.Errorf("expected the wrapper module to be executed, got command %v", container.Command)
+	}
+
+	wrapper := job.Spec.Template.Annotations[v1alpha1.ThresholdsWrapperAnnotation]
+	if !strings.Contains(wrapper, "import * as script from '/test/test.js';") {
+		t.Errorf("expected the wrapper to import the script, got %q", wrapper)
+	}
+	if _, ok := job.Annotations[v1alpha1.ThresholdsWrapperAnnotation]; ok {
+		t.Error("expected the wrapper to be only in the annotations of the pod")
+	}
+
+	volumes := job.Spec.Template.Spec.Volumes
+	projected := volumes[len(volumes)-1].Projected
+	if projected == nil || len(projected.Sources) != 2 {
+		t.Fatalf("expected a projected volume of the thresholds and the wrapper, got %+v", volumes)
+	}
+	expectedItems := []corev1.KeyToPath{{Key: "strict.json", Path: "thresholds.json"}}
+	if cm := projected.Sources[0].ConfigMap; cm == nil || cm.Name != "ci-thresholds" || !reflect.DeepEqual(cm.Items, expectedItems) {
+		t.Errorf("unexpected thresholds source %+v", projected.Sources[0])
+	}
+	expectedMount := corev1.VolumeMount{Name: "k6-thresholds-volume", MountPath: "/thresholds", ReadOnly: true}
+	if diff := deep.Equal(container.VolumeMounts[len(container.VolumeMounts)-1], expectedMount); diff != nil {
+		t.Errorf("unexpected thresholds volume mount, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobCloudOutputToken(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Arguments:   "--out cloud",
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			CloudHost: "https://api.k6.example.com",
+			CloudOutputToken: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "k6-output"},
+				Key:                  "token",
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	expectedEnv := []corev1.EnvVar{
+		{
+			Name: "K6_CLOUD_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "k6-output"},
+				Key:                  "token",
+			}},
+		},
+		{Name: "K6_CLOUD_HOST", Value: "https://api.k6.example.com"},
+	}
+	env := job.Spec.Template.Spec.Containers[0].Env
+	for _, expected := range expectedEnv {
+		i := slices.IndexFunc(env, func(e corev1.EnvVar) bool { return e.Name == expected.Name })
+		if i < 0 {
+			t.Errorf("expected env var %s, got %+v", expected.Name, env)
+			continue
+		}
+		if diff := deep.Equal(env[i], expected); diff != nil {
+			t.Errorf("unexpected env var %s, diff: %s", expected.Name, diff)
+		}
+	}
+	if slices.ContainsFunc(env, func(e corev1.EnvVar) bool { return e.Name == "K6_CLOUD_PUSH_REF_ID" }) {
+		t.Error("expected no cloud test run of the operator")
+	}
+}
+
+func TestNewRunnerJobRuntimeClassName(t *testing.T) {
+	runtimeClassName := "gvisor"
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			Runner: v1alpha1.RunnerPod{
+				RuntimeClassName: &runtimeClassName,
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	if diff := deep.Equal(job.Spec.Template.Spec.RuntimeClassName, &runtimeClassName); diff != nil {
+		t.Errorf("unexpected runtime class, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobPodInfoEnv(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			PodInfoEnv: true,
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Env: []corev1.EnvVar{{Name: "K6_TAG_POD", Value: "$(K6_POD_NAME)"}},
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+
+	fieldEnv := func(name, path string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name:      name,
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: path}},
+		}
+	}
+	expected := []corev1.EnvVar{
+		instanceEnv(1),
+		fieldEnv("K6_POD_NAME", "metadata.name"),
+		fieldEnv("K6_POD_NAMESPACE", "metadata.namespace"),
+		fieldEnv("K6_POD_IP", "status.podIP"),
+		fieldEnv("K6_NODE_NAME", "spec.nodeName"),
+		// defined after the pod info, so that it can be expanded
+		{Name: "K6_TAG_POD", Value: "$(K6_POD_NAME)"},
+	}
+	if diff := deep.Equal(job.Spec.Template.Spec.Containers[0].Env, expected); diff != nil {
+		t.Errorf("unexpected env, diff: %s", diff)
+	}
+}
+
+func TestNewRunnerJobTestRunUID(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+			UID:       "test-uid",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 1,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+		},
+	}
+
+	job, err := NewRunnerJob(k6, 1, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewRunnerJob errored, got: %v", err)
+	}
+	service, err := NewRunnerService(k6, 1)
+	if err != nil {
+		t.Fatalf("NewRunnerService errored, got: %v", err)
+	}
+
+	selector := k6.ListOptions().LabelSelector
+	for name, objectLabels := range map[string]map[string]string{
+		"job":     job.Labels,
+		"pod":     job.Spec.Template.Labels,
+		"service": service.Labels,
+	} {
+		if objectLabels[v1alpha1.TestRunUIDLabel] != "test-uid" {
+			t.Errorf("expected the %s to have the UID of the test run, got labels %v", name, objectLabels)
+		}
+		if !selector.Matches(labels.Set(objectLabels)) {
+			t.Errorf("expected the %s to be listed, got labels %v", name, objectLabels)
+		}
+	}
+
+	// a pod of another test run with the same name
+	other := maps.Clone(job.Spec.Template.Labels)
+	other[v1alpha1.TestRunUIDLabel] = "other-uid"
+	if k6.IsRunnerOf(&metav1.ObjectMeta{Labels: other}) {
+		t.Error("expected the pod of another test run not to be a runner")
+	}
+
+	// runners created before the UID label
+	old := maps.Clone(job.Spec.Template.Labels)
+	delete(old, v1alpha1.TestRunUIDLabel)
+	if !selector.Matches(labels.Set(old)) {
+		t.Errorf("expected the runners without the UID label to be listed, got labels %v", old)
+	}
+	oldJob := &metav1.ObjectMeta{
+		Labels:          old,
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(k6, v1alpha1.GroupVersion.WithKind("TestRun"))},
+	}
+	controller := true
+	oldPod := &metav1.ObjectMeta{
+		Labels:          old,
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: "test-1", Controller: &controller}},
+	}
+	if !k6.IsRunnerOf(oldJob) || !k6.IsRunnerOf(oldPod) {
+		t.Error("expected the runners without the UID label to be runners")
+	}
+	if k6.IsRunnerOf(&metav1.ObjectMeta{Labels: old}) {
+		t.Error("expected an object without the UID label and controller not to be a runner")
+	}
+}
+
+func TestNewIndexedRunnerJob(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Parallelism: 2,
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{
+					Name: "test",
+					File: "test.js",
+				},
+			},
+			CompletionMode: batchv1.IndexedCompletion,
+		},
+	}
+
+	job, err := NewIndexedRunnerJob(k6, cloud.NewTokenInfo("", ""))
+	if err != nil {
+		t.Fatalf("NewIndexedRunnerJob errored, got: %v", err)
+	}
+
+	if job.Name != "test-runners" {
+		t.Errorf("unexpected name of the job %s", job.Name)
+	}
+	if job.Spec.CompletionMode == nil || *job.Spec.CompletionMode != batchv1.IndexedCompletion ||
+		job.Spec.Completions == nil || *job.Spec.Completions != 2 ||
+		job.Spec.Parallelism == nil || *job.Spec.Parallelism != 2 {
+		t.Errorf("expected an Indexed Job with 2 completions, got %+v", job.Spec)
+	}
+	if hostname := job.Spec.Template.Spec.Hostname; hostname != "" {
+		t.Errorf("expected no hostname shared by the pods, got %s", hostname)
+	}
+
+	container := job.Spec.Template.Spec.Containers[0]
+	if len(container.Command) < 5 || container.Command[0] != "sh" || container.Command[1] != "-c" {
+		t.Fatalf("expected the command to be wrapped in a shell script, got %v", container.Command)
+	}
+	script := container.Command[2]
+	for _, expected := range []string{
+		"export K6_INSTANCE_ID=$((JOB_COMPLETION_INDEX + 1))",
+		"1) export K6_EXECUTION_SEGMENT='0:1/2' K6_EXECUTION_SEGMENT_SEQUENCE='0,1/2,1' ;;",
+		"2) export K6_EXECUTION_SEGMENT='1/2:1' K6_EXECUTION_SEGMENT_SEQUENCE='0,1/2,1' ;;",
+		`exec "$@" --tag "instance_id=$K6_INSTANCE_ID" --tag "job_name=test-$K6_INSTANCE_ID"`,
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("expected the script to contain %q, got:\n%s", expected, script)
+		}
+	}
+	if diff := deep.Equal(container.Command[3:5], []string{"k6", "k6"}); diff != nil {
+		t.Errorf("unexpected command after the script, diff: %s", diff)
+	}
+	for _, arg := range container.Command[4:] {
+		if strings.HasPrefix(arg, "--execution-segment") || strings.HasPrefix(arg, "instance_id=") {
+			t.Errorf("expected the runner arguments to be set by the script, got %s", arg)
+		}
+	}
+	for _, env := range container.Env {
+		if env.Name == v1alpha1.InstanceIDEnv {
+			t.Errorf("expected the instance ID to be set by the script, got %v", env)
+		}
+	}
+
+	service, err := NewRunnerService(k6, 2)
+	if err != nil {
+		t.Fatalf("NewRunnerService errored, got: %v", err)
+	}
+	expected := map[string]string{"job-name": "test-runners", batchv1.JobCompletionIndexAnnotation: "1"}
+	if diff := deep.Equal(service.Spec.Selector, expected); diff != nil {
+		t.Errorf("unexpected selector of the service, diff: %s", diff)
+	}
+}
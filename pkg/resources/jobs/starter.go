@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/resources/containers"
+	"github.com/grafana/k6-operator/pkg/types"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
@@ -20,7 +21,7 @@ func NewStarterJob(k6 *v1alpha1.TestRun, hostname []string) *batchv1.Job {
 		starterAnnotations = k6.GetSpec().Starter.Metadata.Annotations
 	}
 
-	starterImage := "ghcr.io/grafana/k6-operator:latest-starter"
+	starterImage := types.DefaultScriptFetchImage
 	if k6.GetSpec().Starter.Image != "" {
 		starterImage = k6.GetSpec().Starter.Image
 	}
@@ -33,6 +34,7 @@ func NewStarterJob(k6 *v1alpha1.TestRun, hostname []string) *batchv1.Job {
 			}
 		}
 	}
+	starterLabels, starterAnnotations = inheritMetadata(k6, starterLabels, starterAnnotations)
 	serviceAccountName := "default"
 	if k6.GetSpec().Starter.ServiceAccountName != "" {
 		serviceAccountName = k6.GetSpec().Starter.ServiceAccountName
@@ -62,6 +64,13 @@ func NewStarterJob(k6 *v1alpha1.TestRun, hostname []string) *batchv1.Job {
 		resourceRequirements = k6.GetSpec().Starter.Resources
 	}
 
+	// Unless configured otherwise, keep the starter away from runners:
+	// it must not compete with them for resources right when the test begins.
+	affinity := k6.GetSpec().Starter.Affinity
+	if affinity == nil {
+		affinity = newStarterAntiAffinity(k6.NamespacedName().Name)
+	}
+
 	var zero32 int32
 
 	return &batchv1.Job{
@@ -81,13 +90,16 @@ func NewStarterJob(k6 *v1alpha1.TestRun, hostname []string) *batchv1.Job {
 				Spec: corev1.PodSpec{
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					ServiceAccountName:           serviceAccountName,
-					Affinity:                     k6.GetSpec().Starter.Affinity,
+					Affinity:                     affinity,
 					NodeSelector:                 k6.GetSpec().Starter.NodeSelector,
 					Tolerations:                  k6.GetSpec().Starter.Tolerations,
 					TopologySpreadConstraints:    k6.GetSpec().Starter.TopologySpreadConstraints,
 					RestartPolicy:                corev1.RestartPolicyNever,
 					SecurityContext:              &k6.GetSpec().Starter.SecurityContext,
-					ImagePullSecrets:             k6.GetSpec().Starter.ImagePullSecrets,
+					ImagePullSecrets:             newImagePullSecrets(k6.GetSpec().ImagePullSecrets, k6.GetSpec().Starter.ImagePullSecrets),
+					// sidecars, e.g. an auth proxy, are init containers with restartPolicy Always
+					InitContainers: getInitContainers(&k6.GetSpec().Starter, &types.Script{}),
+					Volumes:        k6.GetSpec().Starter.Volumes,
 					Containers: []corev1.Container{
 						containers.NewStartContainer(
 							hostname,
@@ -105,3 +117,26 @@ func NewStarterJob(k6 *v1alpha1.TestRun, hostname []string) *batchv1.Job {
 		},
 	}
 }
+
+// newStarterAntiAffinity prefers nodes without runner pods of the test run.
+func newStarterAntiAffinity(name string) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"app":    "k6",
+								"k6_cr":  name,
+								"runner": "true",
+							},
+						},
+						TopologyKey: "kubernetes.io/hostname",
+					},
+				},
+			},
+		},
+	}
+}
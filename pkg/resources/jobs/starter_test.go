@@ -6,6 +6,7 @@ import (
 	deep "github.com/go-test/deep"
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/resources/containers"
+	"github.com/grafana/k6-operator/pkg/types"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
@@ -46,7 +47,7 @@ func TestNewStarterJob(t *testing.T) {
 				Spec: corev1.PodSpec{
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					ServiceAccountName:           "default",
-					Affinity:                     nil,
+					Affinity:                     newStarterAntiAffinity("test"),
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
 					TopologySpreadConstraints:    nil,
@@ -139,7 +140,7 @@ func TestNewStarterJobIstio(t *testing.T) {
 				Spec: corev1.PodSpec{
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					ServiceAccountName:           "default",
-					Affinity:                     nil,
+					Affinity:                     newStarterAntiAffinity("test"),
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
 					TopologySpreadConstraints:    nil,
@@ -213,6 +214,51 @@ func TestNewStarterJobIstio(t *testing.T) {
 
 }
 
+func TestNewStarterAntiAffinity(t *testing.T) {
+	expectedOutcome := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"app":    "k6",
+								"k6_cr":  "test",
+								"runner": "true",
+							},
+						},
+						TopologyKey: "kubernetes.io/hostname",
+					},
+				},
+			},
+		},
+	}
+
+	if diff := deep.Equal(newStarterAntiAffinity("test"), expectedOutcome); diff != nil {
+		t.Errorf("newStarterAntiAffinity returned unexpected data, diff: %s", diff)
+	}
+
+	// affinity of the user takes precedence
+	userAffinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Starter: v1alpha1.Pod{
+				Affinity: userAffinity,
+			},
+		},
+	}
+
+	job := NewStarterJob(k6, []string{"testing"})
+	if job.Spec.Template.Spec.Affinity != userAffinity {
+		t.Errorf("NewStarterJob ignored the affinity of the starter: %v", job.Spec.Template.Spec.Affinity)
+	}
+}
+
 func TestNewStarterJobCustomResources(t *testing.T) {
 	// Test case 1: Default resources should be applied when no custom resources are specified
 	k6Default := &v1alpha1.TestRun{
@@ -282,3 +328,86 @@ func TestNewStarterJobCustomResources(t *testing.T) {
 		t.Errorf("custom resources not applied: %v", diff)
 	}
 }
+
+func TestNewStarterJobSidecar(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Starter: v1alpha1.Pod{
+				InitContainers: []v1alpha1.InitContainer{{
+					Name:          "auth-proxy",
+					Image:         "auth-proxy:latest",
+					VolumeMounts:  []corev1.VolumeMount{{Name: "credentials", MountPath: "/credentials"}},
+					RestartPolicy: &always,
+				}},
+				Volumes: []corev1.Volume{{Name: "credentials"}},
+			},
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{Name: "test", File: "test.js"},
+			},
+		},
+	}
+
+	job := NewStarterJob(k6, []string{"testing"})
+	podSpec := job.Spec.Template.Spec
+
+	expected := []corev1.Container{{
+		Name:            "auth-proxy",
+		Image:           "auth-proxy:latest",
+		VolumeMounts:    []corev1.VolumeMount{{Name: "credentials", MountPath: "/credentials"}},
+		SecurityContext: &corev1.SecurityContext{},
+		RestartPolicy:   &always,
+	}}
+	if diff := deep.Equal(podSpec.InitContainers, expected); diff != nil {
+		t.Errorf("unexpected init containers, diff: %s", diff)
+	}
+	if diff := deep.Equal(podSpec.Volumes, k6.Spec.Starter.Volumes); diff != nil {
+		t.Errorf("unexpected volumes, diff: %s", diff)
+	}
+	if len(podSpec.Containers) != 1 {
+		t.Errorf("expected the starter container only, got %d containers", len(podSpec.Containers))
+	}
+}
+
+func TestNewStarterJobImage(t *testing.T) {
+	k6 := &v1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.TestRunSpec{
+			Runner: v1alpha1.RunnerPod{
+				Pod: v1alpha1.Pod{
+					Image:           "registry.internal/k6:1.5.0",
+					ImagePullPolicy: corev1.PullAlways,
+				},
+			},
+			Script: v1alpha1.K6Script{
+				ConfigMap: v1alpha1.K6Configmap{Name: "test", File: "test.js"},
+			},
+		},
+	}
+
+	// the image of runners is not used by the starter
+	for _, job := range []*batchv1.Job{NewStarterJob(k6, []string{"testing"}), NewStopJob(k6, []string{"testing"})} {
+		container := job.Spec.Template.Spec.Containers[0]
+		if container.Image != types.DefaultScriptFetchImage || len(container.ImagePullPolicy) > 0 {
+			t.Errorf("%s: expected the default image, got %s with pull policy %q", job.Name, container.Image, container.ImagePullPolicy)
+		}
+	}
+
+	k6.Spec.Starter = v1alpha1.Pod{
+		Image:           "registry.internal/k6-starter:latest",
+		ImagePullPolicy: corev1.PullIfNotPresent,
+	}
+	for _, job := range []*batchv1.Job{NewStarterJob(k6, []string{"testing"}), NewStopJob(k6, []string{"testing"})} {
+		container := job.Spec.Template.Spec.Containers[0]
+		if container.Image != "registry.internal/k6-starter:latest" || container.ImagePullPolicy != corev1.PullIfNotPresent {
+			t.Errorf("%s: expected the starter image, got %s with pull policy %q", job.Name, container.Image, container.ImagePullPolicy)
+		}
+	}
+}
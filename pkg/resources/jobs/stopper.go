@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/k6-operator/api/v1alpha1"
 	"github.com/grafana/k6-operator/pkg/resources/containers"
+	"github.com/grafana/k6-operator/pkg/types"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -16,7 +17,7 @@ func NewStopJob(k6 *v1alpha1.TestRun, hostname []string) *batchv1.Job {
 
 	job.Name = fmt.Sprintf("%s-stopper", k6.NamespacedName().Name)
 
-	image := "ghcr.io/grafana/k6-operator:latest-starter"
+	image := types.DefaultScriptFetchImage
 	if k6.GetSpec().Starter.Image != "" {
 		image = k6.GetSpec().Starter.Image
 	}
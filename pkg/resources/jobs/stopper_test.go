@@ -44,7 +44,7 @@ func TestNewStopperJob(t *testing.T) {
 				Spec: corev1.PodSpec{
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					ServiceAccountName:           "default",
-					Affinity:                     nil,
+					Affinity:                     newStarterAntiAffinity("test"),
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
 					TopologySpreadConstraints:    nil,
@@ -126,7 +126,7 @@ func TestNewStopJobIstio(t *testing.T) {
 				Spec: corev1.PodSpec{
 					AutomountServiceAccountToken: &automountServiceAccountToken,
 					ServiceAccountName:           "default",
-					Affinity:                     nil,
+					Affinity:                     newStarterAntiAffinity("test"),
 					NodeSelector:                 nil,
 					Tolerations:                  nil,
 					TopologySpreadConstraints:    nil,
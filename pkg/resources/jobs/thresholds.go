@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"fmt"
+
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	"github.com/grafana/k6-operator/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// thresholdsMountPath is where the thresholds of ThresholdsOverride and
+	// the wrapper module applying them are mounted in runner Pods.
+	thresholdsMountPath = "/thresholds"
+
+	// thresholdsWrapperPath is the module executed by the runners instead of the script.
+	thresholdsWrapperPath = thresholdsMountPath + "/script.js"
+)
+
+// newThresholdsWrapper returns the module which re-exports the script with
+// its thresholds overridden by the ones of the mounted file.
+func newThresholdsWrapper(script *types.Script) string {
+	return fmt.Sprintf(`import * as script from '%[1]s';
+export * from '%[1]s';
+export default script.default;
+
+const thresholds = JSON.parse(open('%[2]s/thresholds.json'));
+
+export const options = Object.assign({}, script.options, {
+  thresholds: Object.assign({}, script.options && script.options.thresholds, thresholds),
+});
+`, script.FullName(), thresholdsMountPath)
+}
+
+// newThresholdsVolume returns the volume with the thresholds from the ConfigMap
+// and the wrapper module from ThresholdsWrapperAnnotation of the Pod.
+func newThresholdsVolume(override *v1alpha1.K6ThresholdsOverride) (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: "k6-thresholds-volume",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: override.ConfigMap.Name},
+							Items:                []corev1.KeyToPath{{Key: override.File(), Path: "thresholds.json"}},
+						},
+					},
+					{
+						DownwardAPI: &corev1.DownwardAPIProjection{
+							Items: []corev1.DownwardAPIVolumeFile{{
+								Path: "script.js",
+								FieldRef: &corev1.ObjectFieldSelector{
+									FieldPath: fmt.Sprintf("metadata.annotations['%s']", v1alpha1.ThresholdsWrapperAnnotation),
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	return volume, corev1.VolumeMount{Name: volume.Name, MountPath: thresholdsMountPath, ReadOnly: true}
+}
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/grafana/k6-operator/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UploadJobName returns the name of the Job uploading the output files of the test run.
+func UploadJobName(k6 *v1alpha1.TestRun) string {
+	return fmt.Sprintf("%s-upload", k6.NamespacedName().Name)
+}
+
+// NewUploadJob builds the Job uploading the output files of the test run
+// with the container configured in ArtifactUpload.
+func NewUploadJob(k6 *v1alpha1.TestRun) *batchv1.Job {
+	upload := k6.GetSpec().ArtifactUpload
+	output := k6.GetSpec().OutputVolume
+
+	mountPath := "/output"
+	if output.MountPath != "" {
+		mountPath = output.MountPath
+	}
+
+	var (
+		volumes      []corev1.Volume
+		volumeMounts []corev1.VolumeMount
+	)
+	for i, claimName := range output.ClaimNames(int(k6.GetSpec().Parallelism)) {
+		volume, volumeMount := newOutputVolume(output, i+1)
+		if output.PerRunner {
+			volume.Name = fmt.Sprintf("%s-%d", volume.Name, i+1)
+			volumeMount.Name = volume.Name
+			volumeMount.MountPath = path.Join(mountPath, claimName)
+		}
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
+
+	serviceAccountName := "default"
+	if upload.ServiceAccountName != "" {
+		serviceAccountName = upload.ServiceAccountName
+	}
+
+	labels, annotations := inheritMetadata(k6, newLabels(k6.NamespacedName().Name), nil)
+	backoffLimit := int32(0)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        UploadJobName(k6),
+			Namespace:   k6.NamespacedName().Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: serviceAccountName,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ImagePullSecrets:   k6.GetSpec().ImagePullSecrets,
+					Containers: []corev1.Container{{
+						Name:         "upload",
+						Image:        upload.Image,
+						Command:      upload.Command,
+						Args:         upload.Args,
+						Env:          upload.Env,
+						EnvFrom:      upload.EnvFrom,
+						VolumeMounts: volumeMounts,
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
@@ -3,7 +3,10 @@ package segmentation
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
+
+	"go.k6.io/k6/lib"
 )
 
 const (
@@ -13,9 +16,22 @@ const (
 
 // NewCommandFragments builds command fragments for starting k6 with execution segments.
 func NewCommandFragments(index int, total int) ([]string, error) {
+	segment, sequence, err := NewSegment(index, total)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		fmt.Sprintf("--execution-segment=%s", segment),
+		fmt.Sprintf("--execution-segment-sequence=%s", sequence),
+	}, nil
+}
 
+// NewSegment returns the execution segment of the runner with the index
+// and the execution segment sequence of all runners.
+func NewSegment(index int, total int) (segment, sequence string, err error) {
 	if index > total {
-		return nil, errors.New("node index exceeds configured parallelism")
+		return "", "", errors.New("node index exceeds configured parallelism")
 	}
 
 	parts := []string{beginning}
@@ -36,11 +52,82 @@ func NewCommandFragments(index int, total int) ([]string, error) {
 		return fmt.Sprintf("%d/%d", index, total)
 	}
 
-	segment := fmt.Sprintf("%s:%s", getSegmentPart(index-1, total), getSegmentPart(index, total))
-	sequence := strings.Join(parts[:], ",")
+	segment = fmt.Sprintf("%s:%s", getSegmentPart(index-1, total), getSegmentPart(index, total))
+	sequence = strings.Join(parts[:], ",")
 
-	return []string{
-		fmt.Sprintf("--execution-segment=%s", segment),
-		fmt.Sprintf("--execution-segment-sequence=%s", sequence),
-	}, nil
+	if err = ValidateSequence(sequence); err != nil {
+		return "", "", err
+	}
+
+	return segment, sequence, nil
+}
+
+// RunnerVUs returns the number of VUs of each of total runners when the
+// given number of VUs is segmented between them, as done by k6.
+func RunnerVUs(vus int64, total int) ([]int64, error) {
+	_, sequence, err := NewSegment(1, total)
+	if err != nil {
+		return nil, err
+	}
+	ess, err := lib.NewExecutionSegmentSequenceFromString(sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := lib.NewExecutionSegmentSequenceWrapper(ess)
+	runnerVUs := make([]int64, total)
+	for i := range runnerVUs {
+		runnerVUs[i] = wrapper.ScaleInt64(i, vus)
+	}
+	return runnerVUs, nil
+}
+
+// IdleRunners returns the indexes of the runners which get no VUs when
+// the given number of VUs is segmented between total runners, as done by k6.
+func IdleRunners(vus int64, total int) ([]int, error) {
+	if total < 2 {
+		return nil, nil
+	}
+
+	runnerVUs, err := RunnerVUs(vus, total)
+	if err != nil {
+		return nil, err
+	}
+
+	var idle []int
+	for i, n := range runnerVUs {
+		if n == 0 {
+			idle = append(idle, i+1)
+		}
+	}
+	return idle, nil
+}
+
+// ValidateSequence checks that the execution segment sequence is strictly
+// increasing from 0 to 1, as expected by k6.
+func ValidateSequence(sequence string) error {
+	parts := strings.Split(sequence, ",")
+	if len(parts) < 2 {
+		return fmt.Errorf("execution segment sequence `%s` must have at least 2 values", sequence)
+	}
+
+	var previous *big.Rat
+	for i, part := range parts {
+		value, ok := new(big.Rat).SetString(part)
+		if !ok {
+			return fmt.Errorf("execution segment sequence `%s`: `%s` is not a number", sequence, part)
+		}
+
+		switch {
+		case i == 0 && value.Sign() != 0:
+			return fmt.Errorf("execution segment sequence `%s` must start with %s", sequence, beginning)
+		case i == len(parts)-1 && value.Cmp(big.NewRat(1, 1)) != 0:
+			return fmt.Errorf("execution segment sequence `%s` must end with %s", sequence, end)
+		case previous != nil && value.Cmp(previous) <= 0:
+			return fmt.Errorf("execution segment sequence `%s` must be increasing, but `%s` follows `%s`", sequence, part, parts[i-1])
+		}
+		previous = value
+	}
+
+	return nil
 }
@@ -27,4 +27,71 @@ var _ = Describe("the execution segmentation string generator", func() {
 			}))
 		})
 	})
+	When("given the index 4 and total 4", func() {
+		It("should return the last segment and the sequence", func() {
+			segment, sequence, err := segmentation.NewSegment(4, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(segment).To(Equal("3/4:1"))
+			Expect(sequence).To(Equal("0,1/4,2/4,3/4,1"))
+		})
+	})
+	When("given the boundary parallelism", func() {
+		It("should return valid sequences", func() {
+			for _, total := range []int{2, 3, 1000} {
+				for _, index := range []int{1, total} {
+					_, sequence, err := segmentation.NewSegment(index, total)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(segmentation.ValidateSequence(sequence)).To(Succeed())
+				}
+			}
+		})
+	})
+	When("given fewer VUs than runners", func() {
+		It("should return the idle runners", func() {
+			idle, err := segmentation.IdleRunners(2, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(idle).To(HaveLen(1))
+
+			idle, err = segmentation.IdleRunners(999, 1000)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(idle).To(HaveLen(1))
+		})
+	})
+	When("given at least as many VUs as runners", func() {
+		It("should return no idle runners", func() {
+			for _, total := range []int{1, 2, 3, 1000} {
+				idle, err := segmentation.IdleRunners(int64(total), total)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(idle).To(BeEmpty())
+			}
+		})
+	})
+	When("given the VUs of the test", func() {
+		It("should return the VUs of each runner", func() {
+			vus, err := segmentation.RunnerVUs(10, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vus).To(Equal([]int64{4, 3, 3}))
+		})
+	})
+	When("given a malformed sequence", func() {
+		It("should return an error", func() {
+			for _, sequence := range []string{
+				"",
+				"1",
+				"0,1/2",
+				"1/4,1/2,1",
+				"0,1/2,1/3,1",
+				"0,1/2,1/2,1",
+				"0,a/b,1",
+			} {
+				Expect(segmentation.ValidateSequence(sequence)).NotTo(Succeed(), sequence)
+			}
+		})
+	})
+	When("given the index over the total", func() {
+		It("should return an error", func() {
+			_, _, err := segmentation.NewSegment(5, 4)
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
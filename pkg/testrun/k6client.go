@@ -7,17 +7,59 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/grafana/k6-operator/pkg/types"
 	k6Client "go.k6.io/k6/api/v1/client"
 )
 
+// runnerTransport is shared by all clients of runners, so that connections are reused.
+var runnerTransport = newRunnerTransport()
+
+func newRunnerTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	// Runners are always reached from inside the cluster, so the requests
+	// must not go through the proxy which might be configured for the operator.
+	t.Proxy = nil
+	return t
+}
+
+// NewRunnerClient returns an HTTP client for the REST API of runners.
+func NewRunnerClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: runnerTransport,
+		Timeout:   timeout,
+	}
+}
+
+// DefaultRunnerTimeout is the default timeout of requests to runners,
+// e.g. to check their status, start or stop them.
+const DefaultRunnerTimeout = 30 * time.Second
+
+// runnerClient is shared by all requests to the REST API of runners.
+var runnerClient = NewRunnerClient(DefaultRunnerTimeout)
+
+// scriptClient is used to execute setup() and teardown() on runners. It has
+// no timeout of its own: they may run as long as setupTimeout and
+// teardownTimeout of the script allow, so the context of the caller must
+// have a deadline.
+var scriptClient = NewRunnerClient(0)
+
+// SetRunnerTimeout changes the timeout of requests to runners.
+// Zero means no timeout. It must be called before any request is sent.
+func SetRunnerTimeout(timeout time.Duration) {
+	runnerClient = NewRunnerClient(timeout)
+}
+
+// RunnerClient returns the HTTP client for the REST API of runners.
+func RunnerClient() *http.Client {
+	return runnerClient
+}
+
 // This will probably be removed once distributed mode in k6 is implemented.
 
 func RunSetup(ctx context.Context, hostname string) (_ json.RawMessage, err error) {
-	c, err := k6Client.New(fmt.Sprintf("%v:6565", hostname), k6Client.WithHTTPClient(&http.Client{
-		Timeout: 0,
-	}))
+	c, err := k6Client.New(fmt.Sprintf("%v:6565", hostname), k6Client.WithHTTPClient(scriptClient))
 	if err != nil {
 		return
 	}
@@ -37,11 +79,24 @@ func RunSetup(ctx context.Context, hostname string) (_ json.RawMessage, err erro
 	return response.Data.Attributes.Data, nil
 }
 
+// GetSetupData returns the setup data which the runner at hostname has received.
+func GetSetupData(ctx context.Context, hostname string) (json.RawMessage, error) {
+	c, err := k6Client.New(fmt.Sprintf("%v:6565", hostname), k6Client.WithHTTPClient(runnerClient))
+	if err != nil {
+		return nil, err
+	}
+
+	var response types.SetupData
+	if err = c.CallAPI(ctx, "GET", &url.URL{Path: "/v1/setup"}, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Data.Attributes.Data, nil
+}
+
 func SetSetupData(ctx context.Context, hostnames []string, data json.RawMessage) (err error) {
 	for _, hostname := range hostnames {
-		c, err := k6Client.New(fmt.Sprintf("%v:6565", hostname), k6Client.WithHTTPClient(&http.Client{
-			Timeout: 0,
-		}))
+		c, err := k6Client.New(fmt.Sprintf("%v:6565", hostname), k6Client.WithHTTPClient(runnerClient))
 		if err != nil {
 			return err
 		}
@@ -59,9 +114,7 @@ func RunTeardown(ctx context.Context, hostnames []string) (err error) {
 		return errors.New("no k6 Service is available to run teardown")
 	}
 
-	c, err := k6Client.New(fmt.Sprintf("%v:6565", hostnames[0]), k6Client.WithHTTPClient(&http.Client{
-		Timeout: 0,
-	}))
+	c, err := k6Client.New(fmt.Sprintf("%v:6565", hostnames[0]), k6Client.WithHTTPClient(scriptClient))
 	if err != nil {
 		return
 	}
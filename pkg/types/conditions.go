@@ -97,4 +97,28 @@ var reasons = map[string]string{
 	"CloudTestRunAbortedUnknown": "CloudTestRunAbortedUnknown",
 	"CloudTestRunAbortedTrue":    "CloudTestRunAbortedTrue",
 	"CloudTestRunAbortedFalse":   "CloudTestRunAbortedFalse",
+
+	"RunnerJobsCreatedUnknown": "RunnerJobsCreatedUnknown",
+	"RunnerJobsCreatedTrue":    "RunnerJobsCreatedTrue",
+	"RunnerJobsCreatedFalse":   "RunnerJobsCreatedFalse",
+
+	"RunnersReadyUnknown": "RunnersReadyUnknown",
+	"RunnersReadyTrue":    "RunnersReadyTrue",
+	"RunnersReadyFalse":   "RunnersReadyFalse",
+
+	"TestStartedUnknown": "TestStartedUnknown",
+	"TestStartedTrue":    "TestStartedTrue",
+	"TestStartedFalse":   "TestStartedFalse",
+
+	"ThresholdsMetUnknown": "ThresholdsMetUnknown",
+	"ThresholdsMetTrue":    "ThresholdsMetTrue",
+	"ThresholdsMetFalse":   "ThresholdsMetFalse",
+
+	"RunnerAPIUnavailableUnknown": "RunnerAPIUnavailableUnknown",
+	"RunnerAPIUnavailableTrue":    "RunnerAPIUnavailableTrue",
+	"RunnerAPIUnavailableFalse":   "RunnerAPIUnavailableFalse",
+
+	"RunnersListFailedUnknown": "RunnersListFailedUnknown",
+	"RunnersListFailedTrue":    "RunnersListFailedTrue",
+	"RunnersListFailedFalse":   "RunnersListFailedFalse",
 }
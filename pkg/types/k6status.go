@@ -1,6 +1,9 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+)
 
 // k6 REST API types.
 // TODO: refactor with existing definitions in k6 api/v1?
@@ -20,6 +23,47 @@ type StatusAPIRequestDataAttributes struct {
 	Stopped bool `json:"stopped"`
 }
 
+const (
+	// DefaultStatusID is the ID of the status resource of k6 REST API.
+	DefaultStatusID = "default"
+	// DefaultStatusType is the type of the status resource of k6 REST API.
+	DefaultStatusType = "status"
+)
+
+// NewStatusAPIRequest returns the payload of a PATCH request to `/v1/status` of k6 REST API.
+// Empty id or type are replaced with the default ones.
+func NewStatusAPIRequest(id, typ string, attributes StatusAPIRequestDataAttributes) StatusAPIRequest {
+	if len(id) == 0 {
+		id = DefaultStatusID
+	}
+	if len(typ) == 0 {
+		typ = DefaultStatusType
+	}
+
+	return StatusAPIRequest{
+		Data: StatusAPIRequestData{
+			Attributes: attributes,
+			ID:         id,
+			Type:       typ,
+		},
+	}
+}
+
+// Validate checks that the request follows the schema of k6 REST API:
+// as a JSON:API resource, it must have both ID and type.
+func (r StatusAPIRequest) Validate() error {
+	if len(r.Data.ID) == 0 {
+		return errors.New("status request must have an id")
+	}
+	if len(r.Data.Type) == 0 {
+		return errors.New("status request must have a type")
+	}
+	if r.Data.Attributes.Paused && r.Data.Attributes.Stopped {
+		return errors.New("status request cannot both pause and stop the test")
+	}
+	return nil
+}
+
 type SetupData struct {
 	Data setUpData `json:"data"`
 }
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	k6api "go.k6.io/k6/api/v1"
+)
+
+func Test_NewStatusAPIRequest(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		id, typ    string
+		attributes StatusAPIRequestDataAttributes
+		expected   string
+	}{
+		{
+			"start with defaults",
+			"", "",
+			StatusAPIRequestDataAttributes{Paused: false},
+			`{"data":{"attributes":{"paused":false,"stopped":false},"id":"default","type":"status"}}`,
+		},
+		{
+			"stop with custom id",
+			"scenario-1", "status",
+			StatusAPIRequestDataAttributes{Stopped: true},
+			`{"data":{"attributes":{"paused":false,"stopped":true},"id":"scenario-1","type":"status"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := NewStatusAPIRequest(tc.id, tc.typ, tc.attributes)
+			if err := req.Validate(); err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
+
+			payload, err := json.Marshal(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(payload) != tc.expected {
+				t.Errorf("unexpected payload, got: %s, expected: %s", payload, tc.expected)
+			}
+
+			// the payload must be readable by k6 REST API itself
+			var status k6api.StatusJSONAPI
+			if err := json.Unmarshal(payload, &status); err != nil {
+				t.Fatalf("k6 REST API cannot parse the payload: %v", err)
+			}
+			if status.Status().Stopped != tc.attributes.Stopped {
+				t.Errorf("k6 REST API parsed stopped=%v, expected %v", status.Status().Stopped, tc.attributes.Stopped)
+			}
+			if !status.Status().Paused.Valid || status.Status().Paused.Bool != tc.attributes.Paused {
+				t.Errorf("k6 REST API parsed paused=%v, expected %v", status.Status().Paused, tc.attributes.Paused)
+			}
+		})
+	}
+}
+
+func Test_StatusAPIRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	invalid := []StatusAPIRequest{
+		{Data: StatusAPIRequestData{Type: DefaultStatusType}},
+		{Data: StatusAPIRequestData{ID: DefaultStatusID}},
+		NewStatusAPIRequest("", "", StatusAPIRequestDataAttributes{Paused: true, Stopped: true}),
+	}
+
+	for _, req := range invalid {
+		if err := req.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", req)
+		}
+	}
+}
@@ -7,13 +7,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+// ScriptFetchContainerName is the name of the init container downloading the script.
+const ScriptFetchContainerName = "k6-fetch-script"
+
+// DefaultScriptFetchImage is the image with curl used to download the script.
+const DefaultScriptFetchImage = "ghcr.io/grafana/k6-operator:latest-starter"
+
 // Internal type created to support Spec.script options
 type Script struct {
-	Name     string // Name of ConfigMap or VolumeClaim or "LocalFile"
+	Name     string // Name of ConfigMap or VolumeClaim or "LocalFile" or "URL"
 	ReadOnly bool   // VolumeClaim only
 	Filename string
 	Path     string
-	Type     string // ConfigMap | VolumeClaim | LocalFile
+	Type     string // ConfigMap | VolumeClaim | LocalFile | URL
+
+	URL           string                    // URL only
+	AuthSecretRef *corev1.SecretKeySelector // URL only
+	FetchImage    string                    // URL only
 }
 
 func (s *Script) FullName() string {
@@ -49,6 +59,16 @@ func (s *Script) Volume() []corev1.Volume {
 			},
 		}
 
+	case "URL":
+		return []corev1.Volume{
+			corev1.Volume{
+				Name: "k6-test-volume",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
+		}
+
 	default:
 		return []corev1.Volume{}
 	}
@@ -70,7 +90,8 @@ func (s *Script) VolumeMount() []corev1.VolumeMount {
 		}
 
 	// ConfigMap: always mounted at "/test" since keys cannot represent nested directories.
-	case "ConfigMap":
+	// URL: the script is downloaded to "/test" before k6 starts.
+	case "ConfigMap", "URL":
 		return []corev1.VolumeMount{
 			corev1.VolumeMount{
 				Name:      "k6-test-volume",
@@ -86,6 +107,41 @@ func (s *Script) VolumeMount() []corev1.VolumeMount {
 
 }
 
+// FetchContainers creates an init container downloading the script in case of URL;
+// otherwise, there are no containers.
+func (s *Script) FetchContainers() []corev1.Container {
+	if s.Type != "URL" {
+		return nil
+	}
+
+	image := DefaultScriptFetchImage
+	if len(s.FetchImage) > 0 {
+		image = s.FetchImage
+	}
+
+	// curl fails on HTTP errors and its message is kept as the termination message
+	command := []string{"curl", "--fail", "--silent", "--show-error", "--location", "--retry", "3", "--output", s.FullName()}
+	var env []corev1.EnvVar
+	if s.AuthSecretRef != nil {
+		env = append(env, corev1.EnvVar{
+			Name:      "K6_SCRIPT_AUTHORIZATION",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: s.AuthSecretRef},
+		})
+		// expanded by Kubernetes, so the secret doesn't appear in the Pod spec
+		command = append(command, "--header", "Authorization: $(K6_SCRIPT_AUTHORIZATION)")
+	}
+	command = append(command, s.URL)
+
+	return []corev1.Container{{
+		Name:                     ScriptFetchContainerName,
+		Image:                    image,
+		Command:                  command,
+		Env:                      env,
+		VolumeMounts:             []corev1.VolumeMount{{Name: "k6-test-volume", MountPath: "/test"}},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}}
+}
+
 // UpdateCommand modifies command to check for script existence in case of LocalFile;
 // otherwise, command remains unmodified
 func (s *Script) UpdateCommand(cmd []string) []string {